@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"lawbook/internal/achievements"
+	"lawbook/internal/models"
+	"lawbook/internal/notify"
+)
+
+// awardAchievementsJobName is also used to find where the previous run left
+// off
+const awardAchievementsJobName = "award-achievements"
+
+// awardAchievementsJob checks the achievements of every candidate evaluated
+// since the last successful run. Moots are scored outside the web
+// application, so this is how completed sessions are picked up.
+func (app *application) awardAchievementsJob(ctx context.Context) error {
+	since, err := app.models.JobRuns.LastSuccess(awardAchievementsJobName)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		return err
+	}
+
+	userIDs, err := app.models.Evaluations.CandidatesSince(since)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, id := range userIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := app.awardAchievements(id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// awardAchievements records any achievements a candidate's moot history has
+// earned that they don't already have, and notifies them of the new ones
+func (app *application) awardAchievements(userID int) error {
+	evaluations, err := app.models.Evaluations.ListForUser(userID)
+	if err != nil {
+		return err
+	}
+
+	var earned []*achievements.Rule
+	for _, rule := range achievements.Earned(evaluations) {
+		awarded, err := app.models.Achievements.Award(userID, rule.Code)
+		if err != nil {
+			return err
+		}
+		if awarded {
+			earned = append(earned, rule)
+		}
+	}
+
+	if len(earned) == 0 {
+		return nil
+	}
+
+	title := fmt.Sprintf("You've earned the %s badge", earned[0].Name)
+	if len(earned) > 1 {
+		title = fmt.Sprintf("You've earned %d new badges", len(earned))
+	}
+
+	var names []string
+	for _, rule := range earned {
+		names = append(names, rule.Name)
+	}
+
+	user, err := app.models.Users.Get(userID)
+	if err != nil {
+		return err
+	}
+
+	app.publish(userID, notify.Event{
+		Type:     models.NotifyAchievement,
+		Title:    title,
+		Body:     strings.Join(names, ", "),
+		URL:      fmt.Sprintf("/%s/dashboard", user.Role),
+		Template: "achievement.tmpl",
+		Data:     map[string]any{"Achievements": earned},
+	})
+
+	return nil
+}
+
+// dashboardBadges returns a candidate's badges for their dashboard. Newly
+// earned badges are flagged this once and then marked as seen.
+func (app *application) dashboardBadges(user *models.User) ([]*achievements.Badge, error) {
+	earned, err := app.models.Achievements.ForUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	badges := achievements.Badges(earned)
+
+	for _, a := range earned {
+		if !a.SeenAt.Valid {
+			err = app.models.Achievements.MarkSeen(user.ID)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	return badges, nil
+}
+
+// earnedBadges returns only the badges a user has earned
+func earnedBadges(earned []*models.Achievement) []*achievements.Badge {
+	var badges []*achievements.Badge
+	for _, b := range achievements.Badges(earned) {
+		if b.Earned {
+			badges = append(badges, b)
+		}
+	}
+	return badges
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	"lawbook/internal/models"
+	"lawbook/internal/stats"
+)
+
+// Charts are drawn as inline SVG in these user units and scale to the width
+// of their container. Scores run from 0 to 100.
+const (
+	chartWidth   = 640
+	chartHeight  = 200
+	chartLeft    = 36
+	chartRight   = 12
+	chartTop     = 12
+	chartBottom  = 28
+	barHeight    = 28
+	barGap       = 10
+	barLabelSize = 140
+)
+
+// chartTick is a labelled position on a chart axis
+type chartTick struct {
+	X, Y   float64
+	Label  string
+	Anchor string
+}
+
+// lineChart plots one skill's scores across a candidate's sessions, with its
+// moving average
+type lineChart struct {
+	Title         string
+	Width, Height int
+	Left, Right   float64
+	Points        []chartTick
+	ScoreLine     string
+	AverageLine   string
+	GridLines     []chartTick
+	Labels        []chartTick
+}
+
+func scoreY(score float64) float64 {
+	plot := float64(chartHeight - chartTop - chartBottom)
+	y := float64(chartHeight-chartBottom) - max(0, min(100, score))/100*plot
+	return math.Round(y*10) / 10
+}
+
+// polyline joins the valid scores into an SVG points list, skipping gaps
+func polyline(xs []float64, scores []sql.NullFloat64) string {
+	var points []string
+	for i, s := range scores {
+		if s.Valid {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", xs[i], scoreY(s.Float64)))
+		}
+	}
+	return strings.Join(points, " ")
+}
+
+func newLineChart(series *stats.Series, evaluations []*models.Evaluation) *lineChart {
+	c := &lineChart{
+		Title:  series.Skill.Name(),
+		Width:  chartWidth,
+		Height: chartHeight,
+		Left:   chartLeft,
+		Right:  chartWidth - chartRight,
+	}
+
+	// Sessions are spaced evenly; a single session sits in the middle
+	n := len(series.Scores)
+	xs := make([]float64, n)
+	plot := float64(chartWidth - chartLeft - chartRight)
+	for i := range xs {
+		if n == 1 {
+			xs[i] = chartLeft + plot/2
+		} else {
+			xs[i] = math.Round((chartLeft+float64(i)*plot/float64(n-1))*10) / 10
+		}
+	}
+
+	for _, score := range []float64{0, 25, 50, 75, 100} {
+		c.GridLines = append(c.GridLines, chartTick{X: chartLeft - 6, Y: scoreY(score), Label: fmt.Sprintf("%.0f", score)})
+	}
+
+	for i, s := range series.Scores {
+		if s.Valid {
+			c.Points = append(c.Points, chartTick{
+				X:     xs[i],
+				Y:     scoreY(s.Float64),
+				Label: fmt.Sprintf("%s: %.1f", shortDate(evaluations[i].CreatedAt), s.Float64),
+			})
+		}
+	}
+	c.ScoreLine = polyline(xs, series.Scores)
+	c.AverageLine = polyline(xs, series.MovingAverage)
+
+	if n > 0 {
+		c.Labels = append(c.Labels, chartTick{X: xs[0], Y: chartHeight - 8, Label: shortDate(evaluations[0].CreatedAt), Anchor: "start"})
+	}
+	if n > 1 {
+		c.Labels = append(c.Labels, chartTick{X: xs[n-1], Y: chartHeight - 8, Label: shortDate(evaluations[n-1].CreatedAt), Anchor: "end"})
+	}
+
+	return c
+}
+
+// chartBar is one horizontal bar of a bar chart
+type chartBar struct {
+	Label    string
+	Y        float64
+	Width    float64
+	ValueX   float64
+	Score    sql.NullFloat64
+	Sessions int
+}
+
+// barChart compares the average overall score of groups of sessions
+type barChart struct {
+	Width, Height int
+	BarHeight     int
+	LabelWidth    float64
+	Bars          []chartBar
+}
+
+func newBarChart(groups []*stats.Group) *barChart {
+	c := &barChart{
+		Width:      chartWidth,
+		Height:     max(1, len(groups)*(barHeight+barGap)-barGap),
+		BarHeight:  barHeight,
+		LabelWidth: barLabelSize,
+	}
+
+	plot := float64(chartWidth - barLabelSize - chartRight - 40)
+	for i, g := range groups {
+		overall := g.Averages[0]
+		bar := chartBar{
+			Label:    g.Name,
+			Y:        float64(i * (barHeight + barGap)),
+			Score:    overall,
+			Sessions: g.Sessions,
+		}
+		if overall.Valid {
+			bar.Width = math.Round(max(0, min(100, overall.Float64))/100*plot*10) / 10
+		}
+		bar.ValueX = barLabelSize + bar.Width + 6
+		c.Bars = append(c.Bars, bar)
+	}
+
+	return c
+}
@@ -1,10 +1,18 @@
 package main
 
-import "lawbook/internal/models"
+import (
+	"lawbook/internal/achievements"
+	"lawbook/internal/models"
+	"lawbook/internal/stats"
+)
 
 type contextKey string
 
-const isAuthenticatedContextKey = contextKey("isAuthenticated")
+const (
+	isAuthenticatedContextKey     = contextKey("isAuthenticated")
+	authenticatedUserIDContextKey = contextKey("authenticatedUserID")
+	apiTokenContextKey            = contextKey("apiToken")
+)
 
 // templateData holds data passed to HTML templates
 type templateData struct {
@@ -14,4 +22,117 @@ type templateData struct {
 	IsAuthenticated bool
 	CSRFToken       string
 	User            *models.User
+
+	ProfileCompleteness int
+	APITokens           []*models.APIToken
+	APIScopes           []models.APIScopeInfo
+	NewAPIToken         string
+
+	Sessions            []*models.Session
+	CurrentSessionToken string
+
+	Pagination *pagination
+	Users      []*models.User
+	TargetUser *models.User
+	Roles      []models.UserRole
+	AuditLog   []*models.AuditEntry
+
+	LawyerVerification  *models.LawyerVerification
+	LawyerVerifications []*models.LawyerVerification
+	LawyerVerified      bool
+	StateBarCouncils    []string
+
+	Portfolio       *models.Portfolio
+	PortfolioOwner  *models.User
+	PortfolioURL    string
+	MetaDescription string
+	StudentProfile  *models.StudentProfile
+	LawyerProfile   *models.LawyerProfile
+	Evaluations     []*models.Evaluation
+	Highlights      *models.PerformanceHighlights
+
+	Candidate      *models.Candidate
+	Candidates     []*models.Candidate
+	AreasOfLaw     []string
+	CandidateSorts []models.CandidateSort
+
+	Shortlist           *models.Shortlist
+	Shortlists          []*models.Shortlist
+	CandidateShortlists []*models.Shortlist
+	CandidateTags       map[int][]string
+	SearchQuery         string
+	BlindReview         bool
+	SavedSearches       []*models.SavedSearch
+	SearchFrequencies   []models.SearchFrequency
+
+	ProfileViews     []*models.ProfileView
+	ProfileViewCount int
+	WeeklyViews      []models.WeeklyViews
+	PeakWeeklyViews  int
+	DashboardStats   *stats.Dashboard
+	Badges           []*achievements.Badge
+
+	Progress       *stats.Progress
+	ProgressCharts *progressCharts
+	Skills         []stats.Skill
+
+	CandidateReports []*models.CandidateReport
+	Difficulties     []string
+
+	Consent               *models.Consent
+	ConsentHistory        []*models.ConsentChange
+	RecruiterVisibilities []models.RecruiterVisibility
+
+	ContactForm         interface{}
+	Conversation        *models.Conversation
+	Conversations       []*models.Conversation
+	Messages            []*models.Message
+	BlockedUser         bool
+	UnreadMessages      int
+	ConversationReports []*models.ConversationReport
+
+	JobPosting        *models.JobPosting
+	JobPostings       []*models.JobPosting
+	JobApplication    *models.JobApplication
+	JobApplications   []*models.JobApplication
+	ApplicationStages []models.ApplicationStage
+
+	Notifications           []*models.Notification
+	UnreadNotifications     int
+	NotificationTypes       []models.NotificationTypeInfo
+	NotificationPreferences []models.NotificationPreference
+
+	DigestSettings       *models.DigestSettings
+	DigestSearchURL      string
+	DigestUnsubscribeURL string
+	DigestUnsubscribed   bool
+
+	Membership          *models.Membership
+	Organisation        *models.Organisation
+	Organisations       []*models.Organisation
+	OrganisationMembers []*models.OrganisationMember
+	OrganisationInvites []*models.OrganisationInvite
+	InviteRoles         []models.OrgRole
+	TeamNotes           []*models.CandidateNote
+
+	Interview          *models.Interview
+	Interviews         []*models.Interview
+	InterviewDurations []int
+	TimeZones          []timeZone
+	CalendarFeedURL    string
+
+	MootSession   *models.MootSession
+	MootSessions  []*models.MootSession
+	MootCaseTypes []mootCaseType
+	CheckInOpen   bool
+
+	Mentor            *models.Mentor
+	Mentors           []*models.Mentor
+	MentorSlots       []*models.MentorSlot
+	SlotsForm         interface{}
+	MentoringRequest  *models.MentoringRequest
+	MentoringRequests []*models.MentoringRequest
+	MentoringReviews  []*models.MentoringReview
+	MentoringRatings  []int
+	Reviewed          bool
 }
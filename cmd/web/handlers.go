@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"lawbook/internal/models"
 	"lawbook/internal/validator"
@@ -80,7 +81,7 @@ func (app *application) userSignupPost(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	app.sessionManager.Put(req.Context(), "flash", "Your signup was successful. Please log in.")
+	app.sessionManager.Put(req.Context(), "flash", "Your signup was successful. Please log in to set up your profile.")
 	http.Redirect(w, req, "/user/login", http.StatusSeeOther)
 }
 
@@ -124,6 +125,11 @@ func (app *application) userLoginPost(w http.ResponseWriter, req *http.Request)
 			data := app.newTemplateData(req)
 			data.Form = form
 			app.renderer(w, req, "login.tmpl.html", http.StatusUnprocessableEntity, data)
+		} else if errors.Is(err, models.ErrInactiveAccount) {
+			form.AddNonFieldError("This account has been deactivated. Please contact support.")
+			data := app.newTemplateData(req)
+			data.Form = form
+			app.renderer(w, req, "login.tmpl.html", http.StatusForbidden, data)
 		} else {
 			app.serverError(w, err)
 		}
@@ -140,6 +146,13 @@ func (app *application) userLoginPost(w http.ResponseWriter, req *http.Request)
 	// Store user ID in session
 	app.sessionManager.Put(req.Context(), "authenticatedUserId", id)
 
+	// Record the login in the user's device registry
+	err = app.registerDevice(req, id)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
 	// Get user to determine role-based redirect
 	user, err := app.models.Users.Get(id)
 	if err != nil {
@@ -147,8 +160,23 @@ func (app *application) userLoginPost(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	// Redirect based on role
-	// Replace the switch block at the bottom of userLoginPost with this:
+	// Administrators use the server-rendered console rather than the React app
+	if user.Role == models.RoleAdmin {
+		http.Redirect(w, req, "/admin/users", http.StatusSeeOther)
+		return
+	}
+
+	// Onboarding: new users are asked to fill in their profile before going on
+	completeness, err := app.profileCompleteness(user)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	if completeness == 0 {
+		app.sessionManager.Put(req.Context(), "flash", "Welcome to Lawbook! Take a minute to complete your profile.")
+		http.Redirect(w, req, "/user/account/profile", http.StatusSeeOther)
+		return
+	}
 
 	// Build redirect URL to Vercel app with user info
 	redirectURL := fmt.Sprintf(
@@ -163,13 +191,20 @@ func (app *application) userLoginPost(w http.ResponseWriter, req *http.Request)
 // ==================== USER LOGOUT ====================
 
 func (app *application) userLogout(w http.ResponseWriter, req *http.Request) {
-	err := app.sessionManager.RenewToken(req.Context())
+	err := app.models.Sessions.Delete(app.sessionManager.GetString(req.Context(), "deviceToken"))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	err = app.sessionManager.RenewToken(req.Context())
 	if err != nil {
 		app.serverError(w, err)
 		return
 	}
 
 	app.sessionManager.Remove(req.Context(), "authenticatedUserId")
+	app.sessionManager.Remove(req.Context(), "deviceToken")
 	app.sessionManager.Put(req.Context(), "flash", "You've been logged out successfully!")
 	http.Redirect(w, req, "/", http.StatusSeeOther)
 }
@@ -177,7 +212,12 @@ func (app *application) userLogout(w http.ResponseWriter, req *http.Request) {
 // ==================== ACCOUNT MANAGEMENT ====================
 
 func (app *application) accountView(w http.ResponseWriter, req *http.Request) {
-	userID := app.sessionManager.GetInt(req.Context(), "authenticatedUserId")
+	app.renderAccount(w, req, apiTokenForm{ExpiresInDays: 90}, http.StatusOK)
+}
+
+// renderAccount renders the account page, including the user's API tokens
+func (app *application) renderAccount(w http.ResponseWriter, req *http.Request, form apiTokenForm, status int) {
+	userID := app.authenticatedUserID(req)
 
 	user, err := app.models.Users.Get(userID)
 	if err != nil {
@@ -189,9 +229,146 @@ func (app *application) accountView(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	tokens, err := app.models.APITokens.ListForUser(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	completeness, err := app.profileCompleteness(user)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	verification, err := app.currentVerification(user)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
 	data := app.newTemplateData(req)
 	data.User = user
-	app.renderer(w, req, "account.tmpl.html", http.StatusOK, data)
+	data.ProfileCompleteness = completeness
+	data.LawyerVerification = verification
+	data.Form = form
+	data.APITokens = tokens
+	data.APIScopes = models.APIScopes
+	data.NewAPIToken = app.sessionManager.PopString(req.Context(), "newAPIToken")
+	app.renderer(w, req, "account.tmpl.html", status, data)
+}
+
+// ==================== ACTIVE SESSIONS ====================
+
+func (app *application) accountSessions(w http.ResponseWriter, req *http.Request) {
+	sessions, err := app.models.Sessions.ListForUser(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Sessions = sessions
+	data.CurrentSessionToken = app.sessionManager.GetString(req.Context(), "deviceToken")
+	app.renderer(w, req, "sessions.tmpl.html", http.StatusOK, data)
+}
+
+func (app *application) accountSessionRevokePost(w http.ResponseWriter, req *http.Request) {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	err = app.models.Sessions.DeleteForUser(id, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "That device has been signed out.")
+	http.Redirect(w, req, "/user/account/sessions", http.StatusSeeOther)
+}
+
+func (app *application) accountSessionRevokeOthersPost(w http.ResponseWriter, req *http.Request) {
+	token := app.sessionManager.GetString(req.Context(), "deviceToken")
+
+	err := app.models.Sessions.DeleteOthersForUser(app.authenticatedUserID(req), token)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "All other devices have been signed out.")
+	http.Redirect(w, req, "/user/account/sessions", http.StatusSeeOther)
+}
+
+// ==================== API TOKENS ====================
+
+type apiTokenForm struct {
+	Name                string            `form:"name"`
+	Scopes              []models.APIScope `form:"scopes"`
+	ExpiresInDays       int               `form:"expires_in_days"`
+	validator.Validator `form:"-"`
+}
+
+func (app *application) apiTokenCreatePost(w http.ResponseWriter, req *http.Request) {
+	var form apiTokenForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Name, 100), "name", "This field cannot be more than 100 characters long")
+	form.CheckField(len(form.Scopes) > 0, "scopes", "Select at least one scope")
+	for _, scope := range form.Scopes {
+		form.CheckField(models.ValidScope(scope), "scopes", "Please select valid scopes")
+	}
+	form.CheckField(validator.PermittedInt(form.ExpiresInDays, 0, 30, 90, 365), "expires_in_days", "Please select a valid expiry")
+
+	if !form.Valid() {
+		app.renderAccount(w, req, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	ttl := time.Duration(form.ExpiresInDays) * 24 * time.Hour
+
+	plaintext, err := app.models.APITokens.New(app.authenticatedUserID(req), form.Name, form.Scopes, ttl)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "newAPIToken", plaintext)
+	app.sessionManager.Put(req.Context(), "flash", "API token created. Copy it now - it won't be shown again.")
+	http.Redirect(w, req, "/user/account#api-tokens", http.StatusSeeOther)
+}
+
+func (app *application) apiTokenRevokePost(w http.ResponseWriter, req *http.Request) {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	err = app.models.APITokens.Delete(id, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "API token revoked.")
+	http.Redirect(w, req, "/user/account#api-tokens", http.StatusSeeOther)
 }
 
 // ==================== ROLE-SPECIFIC DASHBOARDS ====================
@@ -199,18 +376,84 @@ func (app *application) accountView(w http.ResponseWriter, req *http.Request) {
 // Student Dashboard
 func (app *application) studentDashboard(w http.ResponseWriter, req *http.Request) {
 	data := app.newTemplateData(req)
+
+	completeness, err := app.profileCompleteness(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	data.ProfileCompleteness = completeness
+
+	data.DashboardStats, err = app.stats.ForUser(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data.Badges, err = app.dashboardBadges(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
 	app.renderer(w, req, "student-dashboard.tmpl.html", http.StatusOK, data)
 }
 
 // Lawyer Dashboard
 func (app *application) lawyerDashboard(w http.ResponseWriter, req *http.Request) {
 	data := app.newTemplateData(req)
+
+	completeness, err := app.profileCompleteness(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	data.ProfileCompleteness = completeness
+
+	data.LawyerVerification, err = app.currentVerification(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data.DashboardStats, err = app.stats.ForUser(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data.Badges, err = app.dashboardBadges(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
 	app.renderer(w, req, "lawyer-dashboard.tmpl.html", http.StatusOK, data)
 }
 
 // Recruiter Dashboard
 func (app *application) recruiterDashboard(w http.ResponseWriter, req *http.Request) {
 	data := app.newTemplateData(req)
+
+	completeness, err := app.profileCompleteness(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	data.ProfileCompleteness = completeness
+
+	data.Membership, err = app.organisationMembership(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data.DashboardStats, err = app.stats.ForUser(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
 	app.renderer(w, req, "recruiter-dashboard.tmpl.html", http.StatusOK, data)
 }
 
@@ -226,19 +469,17 @@ func (app *application) mootCourtSession(w http.ResponseWriter, req *http.Reques
 	app.renderer(w, req, "moot-session.tmpl.html", http.StatusOK, data)
 }
 
-// API endpoint returning JSON user info (for React app to call)
+// API endpoint returning JSON user info (for React app and API token clients)
 func (app *application) apiUserMe(w http.ResponseWriter, req *http.Request) {
-	userID := app.sessionManager.GetInt(req.Context(), "authenticatedUserId")
-	if userID == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		http.Error(w, `{"error":"not authenticated"}`, http.StatusUnauthorized)
-		return
-	}
-	user, err := app.models.Users.Get(userID)
+	user, err := app.models.Users.Get(app.authenticatedUserID(req))
 	if err != nil {
 		app.serverError(w, err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	fmt.Fprintf(w, `{"name":"%s","email":"%s","role":"%s"}`, user.Name, user.Email, user.Role)
+
+	app.writeJSON(w, http.StatusOK, map[string]string{
+		"name":  user.Name,
+		"email": user.Email,
+		"role":  string(user.Role),
+	})
 }
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"lawbook/internal/mailer"
+	"lawbook/internal/models"
+	"lawbook/internal/validator"
+)
+
+// adminPageSize is the number of rows shown per page in the admin console
+const adminPageSize = 25
+
+// passwordResetTTL is how long an emailed password reset link stays valid
+const passwordResetTTL = 24 * time.Hour
+
+// ==================== ADMIN: USERS ====================
+
+type adminUserFilter struct {
+	Query string          `form:"q"`
+	Role  models.UserRole `form:"role"`
+	Page  int             `form:"page"`
+}
+
+func (app *application) adminUsers(w http.ResponseWriter, req *http.Request) {
+	var filter adminUserFilter
+	err := app.decodeQuery(req, &filter)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	page := newPagination(filter.Page, adminPageSize, req.URL.Query())
+
+	users, total, err := app.models.Users.Search(filter.Query, filter.Role, page.PageSize, page.Offset())
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	page.Total = total
+
+	data := app.newTemplateData(req)
+	data.Form = filter
+	data.Users = users
+	data.Roles = models.Roles
+	data.Pagination = page
+	app.renderer(w, req, "admin-users.tmpl.html", http.StatusOK, data)
+}
+
+func (app *application) adminUserView(w http.ResponseWriter, req *http.Request) {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	target, err := app.models.Users.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.TargetUser = target
+	data.Roles = models.Roles
+	app.renderer(w, req, "admin-user.tmpl.html", http.StatusOK, data)
+}
+
+// adminTargetUser loads the user named by the ":id" parameter for an admin
+// action. Admins may not act on their own account, so they can't lock
+// themselves out. It writes the error response and returns nil on failure.
+func (app *application) adminTargetUser(w http.ResponseWriter, req *http.Request) *models.User {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	if id == app.authenticatedUserID(req) {
+		app.sessionManager.Put(req.Context(), "flash", "You can't perform that action on your own account.")
+		http.Redirect(w, req, fmt.Sprintf("/admin/users/%d", id), http.StatusSeeOther)
+		return nil
+	}
+
+	target, err := app.models.Users.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	return target
+}
+
+// audit records an admin action and redirects back to the target user with a flash message
+func (app *application) audit(w http.ResponseWriter, req *http.Request, action string, target *models.User, details, flash string) {
+	err := app.models.AuditLog.Insert(app.authenticatedUserID(req), action, target.ID, details)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", flash)
+	http.Redirect(w, req, fmt.Sprintf("/admin/users/%d", target.ID), http.StatusSeeOther)
+}
+
+func (app *application) adminDeactivateUserPost(w http.ResponseWriter, req *http.Request) {
+	target := app.adminTargetUser(w, req)
+	if target == nil {
+		return
+	}
+
+	err := app.models.Users.DeactivateUser(target.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	// Sign the user out everywhere
+	err = app.models.Sessions.DeleteAllForUser(target.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.audit(w, req, models.AuditDeactivateUser, target, "", fmt.Sprintf("%s has been deactivated.", target.Name))
+}
+
+func (app *application) adminReactivateUserPost(w http.ResponseWriter, req *http.Request) {
+	target := app.adminTargetUser(w, req)
+	if target == nil {
+		return
+	}
+
+	err := app.models.Users.ReactivateUser(target.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.audit(w, req, models.AuditReactivateUser, target, "", fmt.Sprintf("%s has been reactivated.", target.Name))
+}
+
+func (app *application) adminVerifyEmailPost(w http.ResponseWriter, req *http.Request) {
+	target := app.adminTargetUser(w, req)
+	if target == nil {
+		return
+	}
+
+	err := app.models.Users.VerifyEmail(target.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.audit(w, req, models.AuditVerifyEmail, target, target.Email, fmt.Sprintf("%s's email address is now verified.", target.Name))
+}
+
+type adminRoleForm struct {
+	Role models.UserRole `form:"role"`
+}
+
+func (app *application) adminChangeRolePost(w http.ResponseWriter, req *http.Request) {
+	target := app.adminTargetUser(w, req)
+	if target == nil {
+		return
+	}
+
+	var form adminRoleForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	valid := false
+	for _, role := range models.Roles {
+		if form.Role == role {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = app.models.Users.UpdateRole(target.ID, form.Role)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	details := fmt.Sprintf("%s -> %s", target.Role, form.Role)
+	app.audit(w, req, models.AuditChangeRole, target, details, fmt.Sprintf("%s is now a %s.", target.Name, roleDisplay(form.Role)))
+}
+
+func (app *application) adminPasswordResetPost(w http.ResponseWriter, req *http.Request) {
+	target := app.adminTargetUser(w, req)
+	if target == nil {
+		return
+	}
+
+	token, err := app.models.PasswordResets.New(target.ID, passwordResetTTL)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := map[string]any{
+		"Name":     target.Name,
+		"ResetURL": app.config.baseURL + "/user/password/reset?token=" + token,
+		"Hours":    int(passwordResetTTL.Hours()),
+	}
+
+	msg, err := mailer.Render(emailTemplateDir, "password-reset.tmpl", target.Email, data)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	err = app.mailer.Send(msg)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.audit(w, req, models.AuditPasswordReset, target, target.Email, fmt.Sprintf("A password reset link has been emailed to %s.", target.Email))
+}
+
+// ==================== ADMIN: AUDIT LOG ====================
+
+func (app *application) adminAuditLog(w http.ResponseWriter, req *http.Request) {
+	pageNumber, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	page := newPagination(pageNumber, adminPageSize, req.URL.Query())
+
+	entries, total, err := app.models.AuditLog.Latest(page.PageSize, page.Offset())
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	page.Total = total
+
+	data := app.newTemplateData(req)
+	data.AuditLog = entries
+	data.Pagination = page
+	app.renderer(w, req, "admin-audit.tmpl.html", http.StatusOK, data)
+}
+
+// ==================== PASSWORD RESET ====================
+
+type passwordResetForm struct {
+	Token               string `form:"token"`
+	Password            string `form:"password"`
+	ConfirmPassword     string `form:"confirm_password"`
+	validator.Validator `form:"-"`
+}
+
+func (app *application) userPasswordReset(w http.ResponseWriter, req *http.Request) {
+	token := req.URL.Query().Get("token")
+
+	_, err := app.models.PasswordResets.GetUserID(token)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "That password reset link is invalid or has expired.")
+			http.Redirect(w, req, "/user/login", http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = passwordResetForm{Token: token}
+	app.renderer(w, req, "password-reset.tmpl.html", http.StatusOK, data)
+}
+
+func (app *application) userPasswordResetPost(w http.ResponseWriter, req *http.Request) {
+	var form passwordResetForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.CheckField(validator.NotBlank(form.Password), "password", "This field cannot be blank")
+	form.CheckField(validator.MinChars(form.Password, 8), "password", "This field must be at least 8 characters long")
+	form.CheckField(form.Password == form.ConfirmPassword, "confirm_password", "Passwords do not match")
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		form.Password, form.ConfirmPassword = "", ""
+		data.Form = form
+		app.renderer(w, req, "password-reset.tmpl.html", http.StatusUnprocessableEntity, data)
+		return
+	}
+
+	userID, err := app.models.PasswordResets.GetUserID(form.Token)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "That password reset link is invalid or has expired.")
+			http.Redirect(w, req, "/user/login", http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	err = app.models.Users.UpdatePassword(userID, form.Password)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	// The link is single use, and any existing logins should end
+	err = app.models.PasswordResets.DeleteAllForUser(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	err = app.models.Sessions.DeleteAllForUser(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Your password has been reset. Please log in.")
+	http.Redirect(w, req, "/user/login", http.StatusSeeOther)
+}
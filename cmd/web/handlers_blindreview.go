@@ -0,0 +1,108 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"lawbook/internal/models"
+)
+
+// ==================== RECRUITER: BLIND REVIEW ====================
+
+// pseudonym is the name a candidate is shown under in blind review. It
+// depends only on the candidate, so colleagues sharing a shortlist see the
+// same pseudonym.
+func pseudonym(candidateID int) string {
+	sum := sha256.Sum256([]byte("lawbook-blind-review:" + strconv.Itoa(candidateID)))
+	return "Candidate " + base32.StdEncoding.EncodeToString(sum[:])[:6]
+}
+
+// anonymise replaces the identity of each candidate with a pseudonym if the
+// recruiter has blind review switched on, leaving candidates the recruiter
+// has chosen to reveal untouched. It reports whether blind review is on.
+func (app *application) anonymise(recruiterID int, candidates ...*models.Candidate) (bool, error) {
+	on, err := app.models.RecruiterProfiles.BlindReview(recruiterID)
+	if err != nil || !on {
+		return false, err
+	}
+
+	ids := make([]int, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.ID
+	}
+
+	revealed, err := app.models.IdentityReveals.Revealed(recruiterID, ids)
+	if err != nil {
+		return false, err
+	}
+
+	for _, c := range candidates {
+		if _, ok := revealed[c.ID]; ok {
+			continue
+		}
+		c.Name = pseudonym(c.ID)
+		c.University = ""
+		c.FirmName = ""
+		c.PortfolioSlug = sql.NullString{}
+		c.Anonymised = true
+	}
+
+	return true, nil
+}
+
+type blindReviewForm struct {
+	On bool `form:"on"`
+}
+
+func (app *application) recruiterBlindReviewPost(w http.ResponseWriter, req *http.Request) {
+	var form blindReviewForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = app.models.RecruiterProfiles.SetBlindReview(app.authenticatedUserID(req), form.On)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	flash := "Blind review is off and this has been recorded. Candidates' names are shown again."
+	if form.On {
+		flash = "Blind review is on. Candidates are shown under pseudonyms without their names, universities or firms."
+	}
+	app.sessionManager.Put(req.Context(), "flash", flash)
+	http.Redirect(w, req, "/recruiter/candidates", http.StatusSeeOther)
+}
+
+// recruiterCandidateRevealPost shows a candidate's identity to a recruiter in
+// blind review from now on. Every reveal is logged.
+func (app *application) recruiterCandidateRevealPost(w http.ResponseWriter, req *http.Request) {
+	candidate := app.recruiterCandidate(w, req)
+	if candidate == nil {
+		return
+	}
+
+	recruiterID := app.authenticatedUserID(req)
+
+	err := app.models.IdentityReveals.Insert(recruiterID, candidate.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	// The candidate was loaded under their pseudonym
+	candidate, err = app.models.Candidates.Get(candidate.ID, recruiterID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("You revealed the identity of %s. The reveal has been recorded.", candidate.Name))
+	http.Redirect(w, req, fmt.Sprintf("/recruiter/candidates/%d", candidate.ID), http.StatusSeeOther)
+}
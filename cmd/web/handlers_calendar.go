@@ -0,0 +1,281 @@
+package main
+
+import (
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"lawbook/internal/ical"
+	"lawbook/internal/models"
+	"lawbook/internal/validator"
+)
+
+// timeZone is a time zone events can be scheduled in
+type timeZone struct {
+	Name  string
+	Label string
+}
+
+// timeZones are the time zones users schedule interviews and moots in, the
+// first being the default
+var timeZones = []timeZone{
+	{"Asia/Kolkata", "India (IST)"},
+	{"Asia/Dubai", "Dubai (GST)"},
+	{"Asia/Singapore", "Singapore (SGT)"},
+	{"Europe/London", "London (GMT/BST)"},
+	{"Europe/Paris", "Central Europe (CET/CEST)"},
+	{"America/New_York", "New York (ET)"},
+	{"America/Los_Angeles", "Los Angeles (PT)"},
+	{"Australia/Sydney", "Sydney (AET)"},
+	{"UTC", "UTC"},
+}
+
+// timeZoneLocation loads one of timeZones, reporting false for any other
+func timeZoneLocation(name string) (*time.Location, bool) {
+	if !slices.ContainsFunc(timeZones, func(z timeZone) bool { return z.Name == name }) {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(name)
+	return loc, err == nil
+}
+
+// datetimeLocalLayout is the format of a datetime-local input's value
+const datetimeLocalLayout = "2006-01-02T15:04"
+
+// parseSlots reads the times entered in datetime-local inputs in the given
+// location, adding a "slot" field error to v for any that are malformed,
+// past or repeated, or if there are none or more than limit. Blank inputs
+// are dropped from the values returned.
+func parseSlots(v *validator.Validator, inputs []string, loc *time.Location, limit int, now time.Time) ([]string, []time.Time) {
+	var values []string
+	var starts []time.Time
+	for _, s := range inputs {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		values = append(values, s)
+
+		start, err := time.ParseInLocation(datetimeLocalLayout, s, loc)
+		switch {
+		case err != nil:
+			v.AddFieldErrors("slot", "Enter each time as a date and time")
+		case !start.After(now):
+			v.AddFieldErrors("slot", "Each time must be in the future")
+		case slices.ContainsFunc(starts, start.Equal):
+			v.AddFieldErrors("slot", "Each time must be different")
+		default:
+			starts = append(starts, start)
+		}
+	}
+
+	v.CheckField(len(values) > 0, "slot", "Offer at least one time")
+	v.CheckField(len(values) <= limit, "slot", fmt.Sprintf("Offer no more than %d times", limit))
+
+	return values, starts
+}
+
+// calendarFeedHistory is how far back a calendar feed lists past events
+const calendarFeedHistory = 30 * 24 * time.Hour
+
+// calendarFeedURL is the absolute address of a user's calendar feed. The
+// user is given a feed secret the first time it is asked for.
+func (app *application) calendarFeedURL(userID int) (string, error) {
+	token, err := app.models.Users.CalendarToken(userID)
+	if err != nil {
+		return "", err
+	}
+
+	if token == "" {
+		token, err = app.models.Users.ResetCalendarToken(userID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	query := url.Values{
+		"user":  {strconv.Itoa(userID)},
+		"token": {token},
+	}
+	return app.config.baseURL + "/calendar.ics?" + query.Encode(), nil
+}
+
+// calendarHost is the domain calendar event UIDs are scoped to
+func (app *application) calendarHost() string {
+	u, err := url.Parse(app.config.baseURL)
+	if err != nil || u.Hostname() == "" {
+		return "lawbook"
+	}
+	return u.Hostname()
+}
+
+// interviewEvent describes a booked interview for calendar applications
+func (app *application) interviewEvent(i *models.Interview) ical.Event {
+	with := i.RecruiterName
+	if i.CompanyName != "" {
+		with += ", " + i.CompanyName
+	}
+
+	status := ical.StatusConfirmed
+	if i.Cancelled() {
+		status = ical.StatusCancelled
+	}
+
+	return ical.Event{
+		UID:         fmt.Sprintf("interview-%d@%s", i.ID, app.calendarHost()),
+		Sequence:    i.Sequence,
+		Start:       i.StartsAt.Time,
+		End:         i.EndsAt(),
+		Summary:     fmt.Sprintf("Interview: %s (%s and %s)", i.Title, i.CandidateName, with),
+		Description: i.Details,
+		URL:         fmt.Sprintf("%s/interviews/%d", app.config.baseURL, i.ID),
+		Status:      status,
+		Organizer:   ical.Person{Name: i.RecruiterName, Email: i.RecruiterEmail},
+		Attendees:   []ical.Person{{Name: i.CandidateName, Email: i.CandidateEmail}},
+	}
+}
+
+// mootEvent describes a scheduled moot for calendar applications
+func (app *application) mootEvent(s *models.MootSession) ical.Event {
+	status := ical.StatusConfirmed
+	if s.Cancelled() {
+		status = ical.StatusCancelled
+	}
+
+	// Invitees' addresses are only shared with the others once they have
+	// said they're coming
+	var attendees []ical.Person
+	organiser := ical.Person{Name: s.CreatorName}
+	for _, p := range s.Participants {
+		switch {
+		case p.UserID == s.CreatedBy:
+			organiser.Email = p.Email
+		case p.RSVP == models.RSVPYes:
+			attendees = append(attendees, ical.Person{Name: p.Name, Email: p.Email})
+		}
+	}
+
+	var roles []string
+	for _, p := range s.Participants {
+		roles = append(roles, fmt.Sprintf("%s: %s", mootRoleDisplay(p.Role), p.Name))
+	}
+	for _, role := range s.AIRoles() {
+		roles = append(roles, mootRoleDisplay(role)+": AI")
+	}
+
+	return ical.Event{
+		UID:         fmt.Sprintf("moot-%d@%s", s.ID, app.calendarHost()),
+		Sequence:    s.Sequence,
+		Start:       s.ScheduledAt.Time,
+		End:         s.EndsAt(),
+		Summary:     mootTitle(s),
+		Description: strings.Join(roles, "\n"),
+		URL:         fmt.Sprintf("%s/moot/sessions/%d", app.config.baseURL, s.ID),
+		Status:      status,
+		Organizer:   organiser,
+		Attendees:   attendees,
+	}
+}
+
+// ==================== CALENDAR FEED ====================
+
+// calendarFeed serves a user's interviews and scheduled moots as an
+// iCalendar feed that calendar applications subscribe to. It is
+// authenticated by the secret in the link alone, since calendar applications
+// don't log in.
+func (app *application) calendarFeed(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	userID, err := strconv.Atoi(query.Get("user"))
+	if err != nil || userID < 1 {
+		app.notFound(w)
+		return
+	}
+
+	expected, err := app.models.Users.CalendarToken(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+	if expected == "" || !hmac.Equal([]byte(query.Get("token")), []byte(expected)) {
+		app.notFound(w)
+		return
+	}
+
+	active, err := app.models.Users.IsActive(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	if !active {
+		app.notFound(w)
+		return
+	}
+
+	interviews, err := app.models.Interviews.Feed(userID, time.Now().Add(-calendarFeedHistory))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	moots, err := app.models.MootSessions.Feed(userID, time.Now().Add(-calendarFeedHistory))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	calendar := &ical.Calendar{Name: "Lawbook"}
+	for _, i := range interviews {
+		calendar.Events = append(calendar.Events, app.interviewEvent(i))
+	}
+	for _, s := range moots {
+		calendar.Events = append(calendar.Events, app.mootEvent(s))
+	}
+	slices.SortStableFunc(calendar.Events, func(a, b ical.Event) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	w.Write(calendar.Bytes(time.Now()))
+}
+
+type calendarResetForm struct {
+	From string `form:"from"`
+}
+
+// calendarResetPost replaces the secret in the user's calendar feed address,
+// in case the address has leaked
+func (app *application) calendarResetPost(w http.ResponseWriter, req *http.Request) {
+	var form calendarResetForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	_, err = app.models.Users.ResetCalendarToken(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	// Back to whichever page the feed address was shown on
+	next := "/interviews"
+	if form.From == "/moot/sessions" {
+		next = form.From
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Your calendar feed has a new address and the old one no longer works. Subscribe to the new address in your calendar applications.")
+	http.Redirect(w, req, next, http.StatusSeeOther)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"lawbook/internal/models"
+	"lawbook/internal/validator"
+)
+
+// compareTrendMonths is the number of months covered by the score trend
+const compareTrendMonths = 6
+
+// ==================== RECRUITER: CANDIDATE COMPARISON ====================
+
+type compareForm struct {
+	IDs                 []int `form:"id"`
+	validator.Validator `form:"-"`
+}
+
+// compareCandidates checks the candidates chosen in the form and builds their
+// reports. Validation problems are recorded on the form; any candidates who
+// can still be compared are reported alongside them.
+func (app *application) compareCandidates(req *http.Request, form *compareForm) ([]*models.CandidateReport, error) {
+	seen := make(map[int]bool)
+	ids := form.IDs[:0]
+	for _, id := range form.IDs {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	form.IDs = ids
+
+	form.CheckField(len(ids) >= 2, "id", "Choose at least two candidates to compare")
+	form.CheckField(len(ids) <= models.MaxCompareCandidates, "id", fmt.Sprintf("You can compare up to %d candidates at a time", models.MaxCompareCandidates))
+	if !form.Valid() {
+		return nil, nil
+	}
+
+	reports, err := app.models.Candidates.Compare(ids, app.authenticatedUserID(req), compareTrendMonths)
+	if err != nil {
+		return nil, err
+	}
+
+	form.CheckField(len(reports) == len(ids), "id", "Some of these candidates are not available to you")
+
+	return reports, nil
+}
+
+func (app *application) recruiterCompare(w http.ResponseWriter, req *http.Request) {
+	var form compareForm
+	err := app.decodeQuery(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Difficulties = models.Difficulties
+
+	// Without a selection, the page explains how to choose candidates
+	if len(form.IDs) > 0 {
+		data.CandidateReports, err = app.compareCandidates(req, &form)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	candidates := make([]*models.Candidate, len(data.CandidateReports))
+	for i, r := range data.CandidateReports {
+		candidates[i] = r.Candidate
+	}
+
+	data.BlindReview, err = app.anonymise(app.authenticatedUserID(req), candidates...)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data.Form = form
+	app.renderer(w, req, "compare.tmpl.html", http.StatusOK, data)
+}
+
+// ==================== API: CANDIDATE COMPARISON ====================
+
+type candidateReportJSON struct {
+	Candidate      candidateJSON    `json:"candidate"`
+	StandardScores standardJSON     `json:"standard_scores"`
+	DifficultyMix  map[string]int   `json:"difficulty_mix"`
+	Trend          []trendPointJSON `json:"trend"`
+	TrendChange    *float64         `json:"trend_change"`
+}
+
+// standardJSON holds standard scores: 50 is the average for the case type
+// and every 10 points is one standard deviation
+type standardJSON struct {
+	Overall         *float64 `json:"overall"`
+	LegalKnowledge  *float64 `json:"legal_knowledge"`
+	Argumentation   *float64 `json:"argumentation"`
+	Presentation    *float64 `json:"presentation"`
+	ResponseQuality *float64 `json:"response_quality"`
+}
+
+type trendPointJSON struct {
+	Month string   `json:"month"`
+	Score *float64 `json:"score"`
+}
+
+func (app *application) apiCompareCandidates(w http.ResponseWriter, req *http.Request) {
+	var form compareForm
+	err := app.decodeQuery(req, &form)
+	if err != nil {
+		app.errorJSON(w, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	reports, err := app.compareCandidates(req, &form)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	if !form.Valid() {
+		app.errorJSON(w, http.StatusUnprocessableEntity, form.FieldErrors["id"])
+		return
+	}
+
+	candidates := make([]*models.Candidate, len(reports))
+	for i, r := range reports {
+		candidates[i] = r.Candidate
+	}
+
+	_, err = app.anonymise(app.authenticatedUserID(req), candidates...)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	results := make([]candidateReportJSON, 0, len(reports))
+	for _, r := range reports {
+		js := candidateReportJSON{
+			Candidate: app.newCandidateJSON(r.Candidate),
+			StandardScores: standardJSON{
+				Overall:         nullFloat(r.OverallStandard),
+				LegalKnowledge:  nullFloat(r.LegalKnowledgeStandard),
+				Argumentation:   nullFloat(r.ArgumentationStandard),
+				Presentation:    nullFloat(r.PresentationStandard),
+				ResponseQuality: nullFloat(r.ResponseQualityStandard),
+			},
+			DifficultyMix: make(map[string]int, len(models.Difficulties)),
+			TrendChange:   nullFloat(r.TrendChange()),
+		}
+		for _, d := range models.Difficulties {
+			js.DifficultyMix[d] = r.Difficulty[d]
+		}
+		for _, p := range r.Trend {
+			js.Trend = append(js.Trend, trendPointJSON{Month: p.Month.Format("2006-01"), Score: nullFloat(p.Score)})
+		}
+		results = append(results, js)
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]any{"candidates": results})
+}
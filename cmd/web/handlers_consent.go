@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"lawbook/internal/models"
+	"lawbook/internal/validator"
+)
+
+// consentHistoryLimit is the number of past consent changes shown
+const consentHistoryLimit = 20
+
+// ==================== RECRUITER CONSENT ====================
+
+type consentForm struct {
+	Visibility  models.RecruiterVisibility `form:"visibility"`
+	ApprovedIDs []int                      `form:"approved_organisations"`
+	SharedIDs   []int                      `form:"shared_evaluations"`
+
+	validator.Validator `form:"-"`
+}
+
+func newConsentForm(c *models.Consent, evaluations []*models.Evaluation) consentForm {
+	form := consentForm{
+		Visibility:  c.Visibility,
+		ApprovedIDs: c.ApprovedOrganisations,
+	}
+	for _, e := range evaluations {
+		if e.SharedWithRecruiters {
+			form.SharedIDs = append(form.SharedIDs, e.ID)
+		}
+	}
+	return form
+}
+
+// Shares reports whether an evaluation is ticked to be shared on the form
+func (f consentForm) Shares(evaluationID int) bool {
+	for _, id := range f.SharedIDs {
+		if id == evaluationID {
+			return true
+		}
+	}
+	return false
+}
+
+// Approves reports whether an organisation is ticked as approved on the form
+func (f consentForm) Approves(organisationID int) bool {
+	return slices.Contains(f.ApprovedIDs, organisationID)
+}
+
+func (app *application) consentEdit(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	consent, err := app.models.Consents.Get(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	evaluations, err := app.models.Evaluations.ListForUser(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.renderConsent(w, req, newConsentForm(consent, evaluations), evaluations, http.StatusOK)
+}
+
+func (app *application) renderConsent(w http.ResponseWriter, req *http.Request, form consentForm, evaluations []*models.Evaluation, status int) {
+	history, err := app.models.Consents.History(app.authenticatedUserID(req), consentHistoryLimit)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	organisations, err := app.models.Organisations.Verified()
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.Evaluations = evaluations
+	data.Organisations = organisations
+	data.ConsentHistory = history
+	data.RecruiterVisibilities = models.RecruiterVisibilities
+	app.renderer(w, req, "privacy.tmpl.html", status, data)
+}
+
+func (app *application) consentEditPost(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	var form consentForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	evaluations, err := app.models.Evaluations.ListForUser(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	organisations, err := app.models.Organisations.Verified()
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	slices.Sort(form.ApprovedIDs)
+	form.ApprovedIDs = slices.Compact(form.ApprovedIDs)
+
+	form.CheckField(models.ValidRecruiterVisibility(form.Visibility), "visibility", "Please choose who can find you")
+	if form.Visibility == models.VisibleToApproved {
+		form.CheckField(len(form.ApprovedIDs) > 0, "approved_organisations", "Approve at least one company, or choose another setting")
+	}
+	form.CheckField(len(form.ApprovedIDs) <= models.MaxApprovedCompanies, "approved_organisations", fmt.Sprintf("You can approve up to %d companies", models.MaxApprovedCompanies))
+	for _, id := range form.ApprovedIDs {
+		verified := slices.ContainsFunc(organisations, func(o *models.Organisation) bool { return o.ID == id })
+		form.CheckField(verified, "approved_organisations", "Please choose companies from the list")
+	}
+
+	if !form.Valid() {
+		app.renderConsent(w, req, form, evaluations, http.StatusUnprocessableEntity)
+		return
+	}
+
+	consent := &models.Consent{
+		UserID:                userID,
+		Visibility:            form.Visibility,
+		ApprovedOrganisations: form.ApprovedIDs,
+	}
+	for _, e := range evaluations {
+		if !form.Shares(e.ID) {
+			consent.WithheldEvaluations = append(consent.WithheldEvaluations, e.ID)
+		}
+	}
+
+	err = app.models.Consents.Update(consent, clientIP(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Your privacy settings have been saved.")
+	http.Redirect(w, req, "/user/account/privacy", http.StatusSeeOther)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// digestToken signs a user ID so the unsubscribe link in their digest works
+// without logging in
+func (app *application) digestToken(userID int) string {
+	mac := hmac.New(sha256.New, []byte(app.config.secretKey))
+	mac.Write([]byte("digest-unsubscribe:" + strconv.Itoa(userID)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// digestUnsubscribeURL is the absolute one-click unsubscribe link for a user
+func (app *application) digestUnsubscribeURL(userID int) string {
+	query := url.Values{
+		"user":  {strconv.Itoa(userID)},
+		"token": {app.digestToken(userID)},
+	}
+	return app.config.baseURL + "/digest/unsubscribe?" + query.Encode()
+}
+
+// ==================== DIGEST UNSUBSCRIBE ====================
+
+// digestUnsubscribeUser reads the user ID from an unsubscribe link, returning
+// false if the link has been tampered with
+func (app *application) digestUnsubscribeUser(req *http.Request) (int, bool) {
+	query := req.URL.Query()
+
+	userID, err := strconv.Atoi(query.Get("user"))
+	if err != nil || userID < 1 {
+		return 0, false
+	}
+
+	expected := app.digestToken(userID)
+	if !hmac.Equal([]byte(query.Get("token")), []byte(expected)) {
+		return 0, false
+	}
+
+	return userID, true
+}
+
+// digestUnsubscribe asks the user to confirm, so that link scanners which
+// follow every URL in an email don't unsubscribe them
+func (app *application) digestUnsubscribe(w http.ResponseWriter, req *http.Request) {
+	if _, ok := app.digestUnsubscribeUser(req); !ok {
+		app.notFound(w)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.DigestUnsubscribeURL = req.URL.RequestURI()
+	app.renderer(w, req, "digest-unsubscribe.tmpl.html", http.StatusOK, data)
+}
+
+// digestUnsubscribePost handles both the confirmation form and the one-click
+// POST that mail clients send for the List-Unsubscribe-Post header
+func (app *application) digestUnsubscribePost(w http.ResponseWriter, req *http.Request) {
+	userID, ok := app.digestUnsubscribeUser(req)
+	if !ok {
+		app.notFound(w)
+		return
+	}
+
+	err := app.models.Digests.SetSubscribed(userID, false)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.DigestUnsubscribed = true
+	app.renderer(w, req, "digest-unsubscribe.tmpl.html", http.StatusOK, data)
+}
+
+// ==================== RECRUITER: DIGEST SEARCH ====================
+
+type digestFilterForm struct {
+	Filter string `form:"filter"`
+}
+
+// recruiterDigestFilterPost saves a candidate search for the recruiter's
+// weekly digest and subscribes them to it
+func (app *application) recruiterDigestFilterPost(w http.ResponseWriter, req *http.Request) {
+	var form digestFilterForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	filter, err := app.candidateSearchQuery(form.Filter)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+
+	err = app.models.Digests.SetCandidateFilter(userID, filter)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	err = app.models.Digests.SetSubscribed(userID, true)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "New candidates matching this search will appear in your weekly digest.")
+	http.Redirect(w, req, candidateSearchURL(filter), http.StatusSeeOther)
+}
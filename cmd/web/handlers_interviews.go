@@ -0,0 +1,527 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"lawbook/internal/ical"
+	"lawbook/internal/mailer"
+	"lawbook/internal/models"
+	"lawbook/internal/notify"
+	"lawbook/internal/validator"
+)
+
+// maxInterviewSlots is the most times a recruiter can offer for an interview
+const maxInterviewSlots = 5
+
+// interviewReminderLead is how long before an interview its participants
+// are reminded
+const interviewReminderLead = 24 * time.Hour
+
+// interviewDurations are the lengths in minutes an interview can be
+var interviewDurations = []int{30, 45, 60, 90}
+
+// ==================== RECRUITER: PROPOSE INTERVIEWS ====================
+
+type interviewForm struct {
+	Title               string   `form:"title"`
+	Details             string   `form:"details"`
+	Duration            int      `form:"duration"`
+	TimeZone            string   `form:"time_zone"`
+	Slots               []string `form:"slot"`
+	validator.Validator `form:"-"`
+
+	// starts holds the parsed slots once validated
+	starts []time.Time
+}
+
+// SlotValues returns the slot inputs to show, padded with empty ones
+func (f *interviewForm) SlotValues() []string {
+	values := make([]string, maxInterviewSlots)
+	copy(values, f.Slots)
+	return values
+}
+
+func (f *interviewForm) validate(now time.Time) {
+	f.Title = strings.TrimSpace(f.Title)
+	f.Details = strings.TrimSpace(f.Details)
+
+	f.CheckField(validator.NotBlank(f.Title), "title", "This field cannot be blank")
+	f.CheckField(validator.MaxChars(f.Title, 150), "title", "This field cannot be more than 150 characters long")
+	f.CheckField(validator.MaxChars(f.Details, 500), "details", "This field cannot be more than 500 characters long")
+	f.CheckField(validator.PermittedInt(f.Duration, interviewDurations...), "duration", "Choose how long the interview will last")
+
+	f.validateSlots(now)
+}
+
+// validateSlots checks the offered times, which are read in the chosen time
+// zone, and keeps them in starts
+func (f *interviewForm) validateSlots(now time.Time) {
+	loc, ok := timeZoneLocation(f.TimeZone)
+	if !ok {
+		f.AddFieldErrors("time_zone", "Choose a time zone")
+		return
+	}
+
+	f.Slots, f.starts = parseSlots(&f.Validator, f.Slots, loc, maxInterviewSlots, now)
+}
+
+// interviewCandidate loads the candidate named by the ":id" parameter for a
+// recruiter proposing an interview. Candidates must have accepted the
+// recruiter's contact request first. It writes the response and returns nil
+// otherwise.
+func (app *application) interviewCandidate(w http.ResponseWriter, req *http.Request) *models.Candidate {
+	candidate := app.recruiterCandidate(w, req)
+	if candidate == nil {
+		return nil
+	}
+
+	conversation, err := app.models.Conversations.Find(app.authenticatedUserID(req), candidate.ID)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, err)
+		return nil
+	}
+
+	if conversation == nil || !conversation.Accepted() {
+		app.sessionManager.Put(req.Context(), "flash", "You can invite a candidate to an interview once they have accepted your contact request.")
+		http.Redirect(w, req, fmt.Sprintf("/recruiter/candidates/%d", candidate.ID), http.StatusSeeOther)
+		return nil
+	}
+
+	return candidate
+}
+
+func (app *application) recruiterInterviewNew(w http.ResponseWriter, req *http.Request) {
+	candidate := app.interviewCandidate(w, req)
+	if candidate == nil {
+		return
+	}
+
+	form := interviewForm{Duration: 30, TimeZone: timeZones[0].Name}
+	app.renderInterviewNew(w, req, candidate, form, http.StatusOK)
+}
+
+func (app *application) renderInterviewNew(w http.ResponseWriter, req *http.Request, candidate *models.Candidate, form interviewForm, status int) {
+	data := app.newTemplateData(req)
+	data.Form = &form
+	data.Candidate = candidate
+	data.InterviewDurations = interviewDurations
+	data.TimeZones = timeZones
+	app.renderer(w, req, "interview-new.tmpl.html", status, data)
+}
+
+func (app *application) recruiterInterviewNewPost(w http.ResponseWriter, req *http.Request) {
+	candidate := app.interviewCandidate(w, req)
+	if candidate == nil {
+		return
+	}
+
+	var form interviewForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.validate(time.Now())
+	if !form.Valid() {
+		app.renderInterviewNew(w, req, candidate, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	recruiterID := app.authenticatedUserID(req)
+
+	id, err := app.models.Interviews.Insert(recruiterID, candidate.ID, form.Title, form.Details, form.Duration, form.TimeZone, form.starts)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	interview, err := app.models.Interviews.Get(id, recruiterID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.notifyInterviewProposed(interview, false)
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("Your interview invitation has been sent to %s.", candidate.Name))
+	http.Redirect(w, req, fmt.Sprintf("/interviews/%d", id), http.StatusSeeOther)
+}
+
+// ==================== INTERVIEWS ====================
+
+func (app *application) interviews(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	interviews, err := app.models.Interviews.ListForUser(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	feedURL, err := app.calendarFeedURL(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Interviews = interviews
+	data.CalendarFeedURL = feedURL
+	app.renderer(w, req, "interviews.tmpl.html", http.StatusOK, data)
+}
+
+// userInterview loads the interview named by the ":id" parameter. It writes
+// the error response and returns nil if the user isn't taking part.
+func (app *application) userInterview(w http.ResponseWriter, req *http.Request) *models.Interview {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	interview, err := app.models.Interviews.Get(id, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	return interview
+}
+
+func (app *application) interviewView(w http.ResponseWriter, req *http.Request) {
+	interview := app.userInterview(w, req)
+	if interview == nil {
+		return
+	}
+
+	form := interviewForm{TimeZone: interview.TimeZone}
+	app.renderInterview(w, req, interview, form, http.StatusOK)
+}
+
+// renderInterview shows an interview. The form is the recruiter's form for
+// offering new times.
+func (app *application) renderInterview(w http.ResponseWriter, req *http.Request, interview *models.Interview, form interviewForm, status int) {
+	data := app.newTemplateData(req)
+	data.Form = &form
+	data.Interview = interview
+	data.TimeZones = timeZones
+	app.renderer(w, req, "interview.tmpl.html", status, data)
+}
+
+type interviewAcceptForm struct {
+	SlotID int `form:"slot_id"`
+}
+
+// interviewAcceptPost books the time the candidate chose and sends both
+// participants a calendar invite
+func (app *application) interviewAcceptPost(w http.ResponseWriter, req *http.Request) {
+	interview := app.userInterview(w, req)
+	if interview == nil {
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+	if interview.IsRecruiter(userID) {
+		app.clientError(w, http.StatusForbidden)
+		return
+	}
+
+	var form interviewAcceptForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	interviewURL := fmt.Sprintf("/interviews/%d", interview.ID)
+
+	err = app.models.Interviews.Schedule(interview.ID, userID, form.SlotID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "That time is no longer available. Please choose another.")
+			http.Redirect(w, req, interviewURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	interview, err = app.models.Interviews.Get(interview.ID, userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.notifyInterviewScheduled(interview)
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("Your interview is booked for %s. A calendar invite is on its way.", interviewTime(interview)))
+	http.Redirect(w, req, interviewURL, http.StatusSeeOther)
+}
+
+// interviewReschedulePost offers new times for an interview. A booked time
+// is cancelled in both participants' calendars.
+func (app *application) interviewReschedulePost(w http.ResponseWriter, req *http.Request) {
+	interview := app.userInterview(w, req)
+	if interview == nil {
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+	if !interview.IsRecruiter(userID) {
+		app.clientError(w, http.StatusForbidden)
+		return
+	}
+
+	var form interviewForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.validateSlots(time.Now())
+	if !form.Valid() {
+		app.renderInterview(w, req, interview, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	interviewURL := fmt.Sprintf("/interviews/%d", interview.ID)
+
+	err = app.models.Interviews.Reschedule(interview.ID, userID, form.starts)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "This interview has been cancelled.")
+			http.Redirect(w, req, interviewURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	if interview.Scheduled() {
+		// The cancellation must carry the sequence of the reschedule, or
+		// calendar applications would ignore it as out of date
+		interview.Sequence++
+		app.notifyInterviewCancelled(interview, userID, true)
+	}
+
+	interview, err = app.models.Interviews.Get(interview.ID, userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.notifyInterviewProposed(interview, true)
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("New times have been sent to %s.", interview.CandidateName))
+	http.Redirect(w, req, interviewURL, http.StatusSeeOther)
+}
+
+// interviewCancelPost lets either participant cancel an interview
+func (app *application) interviewCancelPost(w http.ResponseWriter, req *http.Request) {
+	interview := app.userInterview(w, req)
+	if interview == nil {
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+	interviewURL := fmt.Sprintf("/interviews/%d", interview.ID)
+
+	err := app.models.Interviews.Cancel(interview.ID, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "This interview has already been cancelled.")
+			http.Redirect(w, req, interviewURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	wasScheduled := interview.Scheduled()
+
+	interview, err = app.models.Interviews.Get(interview.ID, userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.notifyInterviewCancelled(interview, userID, wasScheduled)
+
+	app.sessionManager.Put(req.Context(), "flash", "The interview has been cancelled.")
+	http.Redirect(w, req, interviewURL, http.StatusSeeOther)
+}
+
+// interviewICS downloads a booked interview as an iCalendar file
+func (app *application) interviewICS(w http.ResponseWriter, req *http.Request) {
+	interview := app.userInterview(w, req)
+	if interview == nil {
+		return
+	}
+
+	if !interview.StartsAt.Valid || interview.Proposed() {
+		app.notFound(w)
+		return
+	}
+
+	method := ical.MethodRequest
+	if interview.Cancelled() {
+		method = ical.MethodCancel
+	}
+
+	calendar := &ical.Calendar{Method: method, Events: []ical.Event{app.interviewEvent(interview)}}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="interview-%d.ics"`, interview.ID))
+	w.Write(calendar.Bytes(time.Now()))
+}
+
+// ==================== INTERVIEW NOTIFICATIONS ====================
+
+// interviewTime formats when a booked interview starts in its own time zone
+func interviewTime(i *models.Interview) string {
+	return zonedDate(i.StartsAt.Time, i.TimeZone)
+}
+
+// interviewAttachment returns a booked interview as a calendar invite, or
+// its cancellation
+func (app *application) interviewAttachment(i *models.Interview, method string) []mailer.Attachment {
+	event := app.interviewEvent(i)
+	if method == ical.MethodCancel {
+		event.Status = ical.StatusCancelled
+	}
+	calendar := &ical.Calendar{Method: method, Events: []ical.Event{event}}
+
+	return []mailer.Attachment{{
+		Filename:    "invite.ics",
+		ContentType: ical.ContentType + "; method=" + method,
+		Data:        calendar.Bytes(time.Now()),
+	}}
+}
+
+// interviewWith names the other participant from userID's point of view
+func interviewWith(i *models.Interview, userID int) string {
+	if i.IsRecruiter(userID) {
+		return i.CandidateName
+	}
+	if i.CompanyName != "" {
+		return i.RecruiterName + " (" + i.CompanyName + ")"
+	}
+	return i.RecruiterName
+}
+
+// notifyInterviewProposed asks the candidate to choose a time
+func (app *application) notifyInterviewProposed(i *models.Interview, rescheduled bool) {
+	recruiter := interviewWith(i, i.CandidateID)
+
+	title := fmt.Sprintf("%s has invited you to an interview", recruiter)
+	if rescheduled {
+		title = fmt.Sprintf("%s has offered new times for your interview", recruiter)
+	}
+
+	times := make([]string, len(i.Slots))
+	for n, s := range i.Slots {
+		times[n] = zonedDate(s.StartsAt, i.TimeZone)
+	}
+
+	app.publish(i.CandidateID, notify.Event{
+		Type:     models.NotifyInterview,
+		Title:    title,
+		Body:     fmt.Sprintf("%q. Choose one of %d times.", i.Title, len(times)),
+		URL:      fmt.Sprintf("/interviews/%d", i.ID),
+		Template: "interview.tmpl",
+		Data: map[string]any{
+			"Interview": i,
+			"With":      recruiter,
+			"Times":     times,
+		},
+	})
+}
+
+// notifyInterviewScheduled sends both participants a calendar invite for the
+// time the candidate chose
+func (app *application) notifyInterviewScheduled(i *models.Interview) {
+	attachments := app.interviewAttachment(i, ical.MethodRequest)
+
+	for _, userID := range []int{i.RecruiterID, i.CandidateID} {
+		with := interviewWith(i, userID)
+
+		app.publish(userID, notify.Event{
+			Type:     models.NotifyInterview,
+			Title:    fmt.Sprintf("Your interview with %s is booked", with),
+			Body:     fmt.Sprintf("%q, %s.", i.Title, interviewTime(i)),
+			URL:      fmt.Sprintf("/interviews/%d", i.ID),
+			Template: "interview.tmpl",
+			Data: map[string]any{
+				"Interview": i,
+				"With":      with,
+				"When":      interviewTime(i),
+			},
+			Attachments: attachments,
+		})
+	}
+}
+
+// notifyInterviewCancelled tells the participants an interview has been
+// cancelled or is being rescheduled. Calendar cancellations are sent if it
+// had been booked; otherwise only the other participant needs telling.
+func (app *application) notifyInterviewCancelled(i *models.Interview, byUserID int, booked bool) {
+	recipients := []int{i.OtherID(byUserID)}
+
+	var attachments []mailer.Attachment
+	if booked {
+		attachments = app.interviewAttachment(i, ical.MethodCancel)
+		recipients = append(recipients, byUserID)
+	}
+
+	for _, userID := range recipients {
+		by := interviewWith(i, userID)
+		if userID == byUserID {
+			by = "you"
+		}
+
+		title := fmt.Sprintf("Your interview with %s has been cancelled", interviewWith(i, userID))
+		body := fmt.Sprintf("%q was cancelled by %s.", i.Title, by)
+		if !i.Cancelled() {
+			title = fmt.Sprintf("Your interview with %s is being rescheduled", interviewWith(i, userID))
+			body = fmt.Sprintf("%q no longer takes place %s. New times have been offered by %s.", i.Title, interviewTime(i), by)
+		}
+
+		app.publish(userID, notify.Event{
+			Type:        models.NotifyInterview,
+			Title:       title,
+			Body:        body,
+			URL:         fmt.Sprintf("/interviews/%d", i.ID),
+			Attachments: attachments,
+		})
+	}
+}
+
+// remindInterview reminds both participants of an interview coming up
+func (app *application) remindInterview(i *models.Interview) {
+	for _, userID := range []int{i.RecruiterID, i.CandidateID} {
+		with := interviewWith(i, userID)
+
+		app.publish(userID, notify.Event{
+			Type:     models.NotifyInterview,
+			Title:    fmt.Sprintf("Reminder: your interview with %s", with),
+			Body:     fmt.Sprintf("%q, %s.", i.Title, interviewTime(i)),
+			URL:      fmt.Sprintf("/interviews/%d", i.ID),
+			Template: "interview.tmpl",
+			Data: map[string]any{
+				"Interview": i,
+				"With":      with,
+				"When":      interviewTime(i),
+			},
+		})
+	}
+}
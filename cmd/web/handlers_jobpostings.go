@@ -0,0 +1,487 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"lawbook/internal/models"
+	"lawbook/internal/validator"
+)
+
+// jobPageSize is the number of postings shown per page of the job board
+const jobPageSize = 20
+
+// ==================== RECRUITER: JOB POSTINGS ====================
+
+type jobPostingForm struct {
+	Title         string `form:"title"`
+	PracticeArea  string `form:"practice_area"`
+	Location      string `form:"location"`
+	MinExperience int    `form:"min_experience"`
+	MaxExperience int    `form:"max_experience"`
+	Description   string `form:"description"`
+	Deadline      string `form:"deadline"`
+	Closed        bool   `form:"closed"`
+
+	validator.Validator `form:"-"`
+}
+
+func newJobPostingForm(p *models.JobPosting) jobPostingForm {
+	return jobPostingForm{
+		Title:         p.Title,
+		PracticeArea:  p.PracticeArea,
+		Location:      p.Location,
+		MinExperience: p.MinExperience,
+		MaxExperience: p.MaxExperience,
+		Description:   p.Description,
+		Deadline:      p.Deadline.Format("2006-01-02"),
+		Closed:        p.Closed,
+	}
+}
+
+// validate checks the form and returns the deadline it names. An open
+// posting's deadline can't be in the past.
+func (f *jobPostingForm) validate() time.Time {
+	f.Title = strings.TrimSpace(f.Title)
+	f.PracticeArea = strings.TrimSpace(f.PracticeArea)
+	f.Location = strings.TrimSpace(f.Location)
+	f.Description = strings.TrimSpace(f.Description)
+
+	f.CheckField(validator.NotBlank(f.Title), "title", "This field cannot be blank")
+	f.CheckField(validator.MaxChars(f.Title, 150), "title", "This field cannot be more than 150 characters long")
+	f.CheckField(validator.NotBlank(f.PracticeArea), "practice_area", "This field cannot be blank")
+	f.CheckField(validator.MaxChars(f.PracticeArea, 100), "practice_area", "This field cannot be more than 100 characters long")
+	f.CheckField(validator.NotBlank(f.Location), "location", "This field cannot be blank")
+	f.CheckField(validator.MaxChars(f.Location, 100), "location", "This field cannot be more than 100 characters long")
+	f.CheckField(validator.Between(f.MinExperience, 0, 50), "min_experience", "Please enter a number of years between 0 and 50")
+	f.CheckField(validator.Between(f.MaxExperience, 0, 50), "max_experience", "Please enter a number of years between 0 and 50")
+	f.CheckField(f.MaxExperience == 0 || f.MaxExperience >= f.MinExperience, "max_experience", "This must be at least the minimum experience")
+	f.CheckField(validator.NotBlank(f.Description), "description", "This field cannot be blank")
+	f.CheckField(validator.MaxChars(f.Description, 10000), "description", "This field cannot be more than 10000 characters long")
+
+	deadline, err := time.Parse("2006-01-02", f.Deadline)
+	if err != nil {
+		f.AddFieldErrors("deadline", "Please enter a valid date")
+		return deadline
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	f.CheckField(f.Closed || !deadline.Before(today), "deadline", "The deadline cannot be in the past")
+
+	return deadline
+}
+
+func (f jobPostingForm) posting(recruiterID int, deadline time.Time) *models.JobPosting {
+	return &models.JobPosting{
+		RecruiterID:   recruiterID,
+		Title:         f.Title,
+		PracticeArea:  f.PracticeArea,
+		Location:      f.Location,
+		MinExperience: f.MinExperience,
+		MaxExperience: f.MaxExperience,
+		Description:   f.Description,
+		Deadline:      deadline,
+		Closed:        f.Closed,
+	}
+}
+
+func (app *application) recruiterJobs(w http.ResponseWriter, req *http.Request) {
+	app.renderJobPostings(w, req, jobPostingForm{}, http.StatusOK)
+}
+
+func (app *application) renderJobPostings(w http.ResponseWriter, req *http.Request, form jobPostingForm, status int) {
+	postings, err := app.models.JobPostings.ListForRecruiter(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.JobPostings = postings
+	app.renderer(w, req, "job-postings.tmpl.html", status, data)
+}
+
+func (app *application) recruiterJobCreatePost(w http.ResponseWriter, req *http.Request) {
+	var form jobPostingForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	// New postings always start open
+	form.Closed = false
+
+	deadline := form.validate()
+	if !form.Valid() {
+		app.renderJobPostings(w, req, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	id, err := app.models.JobPostings.Insert(form.posting(app.authenticatedUserID(req), deadline))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Your job posting is live.")
+	http.Redirect(w, req, fmt.Sprintf("/recruiter/jobs/%d", id), http.StatusSeeOther)
+}
+
+// recruiterJobPosting loads the recruiter's own posting named by the ":id"
+// parameter. It writes the error response and returns nil on failure.
+func (app *application) recruiterJobPosting(w http.ResponseWriter, req *http.Request) *models.JobPosting {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	posting, err := app.models.JobPostings.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	if posting.RecruiterID != app.authenticatedUserID(req) {
+		app.notFound(w)
+		return nil
+	}
+
+	return posting
+}
+
+func (app *application) recruiterJobView(w http.ResponseWriter, req *http.Request) {
+	posting := app.recruiterJobPosting(w, req)
+	if posting == nil {
+		return
+	}
+
+	app.renderJobPosting(w, req, posting, newJobPostingForm(posting), http.StatusOK)
+}
+
+func (app *application) renderJobPosting(w http.ResponseWriter, req *http.Request, posting *models.JobPosting, form jobPostingForm, status int) {
+	applications, err := app.models.JobApplications.ListForPosting(posting.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.JobPosting = posting
+	data.JobApplications = applications
+	data.ApplicationStages = models.ApplicationStages
+	app.renderer(w, req, "job-posting.tmpl.html", status, data)
+}
+
+func (app *application) recruiterJobUpdatePost(w http.ResponseWriter, req *http.Request) {
+	posting := app.recruiterJobPosting(w, req)
+	if posting == nil {
+		return
+	}
+
+	var form jobPostingForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	deadline := form.validate()
+	if !form.Valid() {
+		app.renderJobPosting(w, req, posting, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	updated := form.posting(posting.RecruiterID, deadline)
+	updated.ID = posting.ID
+
+	err = app.models.JobPostings.Update(updated)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Job posting updated.")
+	http.Redirect(w, req, fmt.Sprintf("/recruiter/jobs/%d", posting.ID), http.StatusSeeOther)
+}
+
+// recruiterJobApplication loads an application to the recruiter's posting,
+// named by the ":application" parameter. It writes the error response and
+// returns nil on failure.
+func (app *application) recruiterJobApplication(w http.ResponseWriter, req *http.Request, posting *models.JobPosting) *models.JobApplication {
+	id, err := readIntParam(req, "application")
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	application, err := app.models.JobApplications.Get(id, posting.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	return application
+}
+
+func (app *application) recruiterApplicationView(w http.ResponseWriter, req *http.Request) {
+	posting := app.recruiterJobPosting(w, req)
+	if posting == nil {
+		return
+	}
+
+	application := app.recruiterJobApplication(w, req, posting)
+	if application == nil {
+		return
+	}
+
+	evaluations, err := app.models.Evaluations.Attached(application.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.JobPosting = posting
+	data.JobApplication = application
+	data.Evaluations = evaluations
+	data.ApplicationStages = models.ApplicationStages
+	app.renderer(w, req, "job-application.tmpl.html", http.StatusOK, data)
+}
+
+type applicationStageForm struct {
+	Stage models.ApplicationStage `form:"stage"`
+}
+
+func (app *application) recruiterApplicationStagePost(w http.ResponseWriter, req *http.Request) {
+	posting := app.recruiterJobPosting(w, req)
+	if posting == nil {
+		return
+	}
+
+	application := app.recruiterJobApplication(w, req, posting)
+	if application == nil {
+		return
+	}
+
+	var form applicationStageForm
+	err := app.decodePostForm(req, &form)
+	if err != nil || !models.ValidApplicationStage(form.Stage) {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = app.models.JobApplications.SetStage(application.ID, posting.ID, form.Stage)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("%s moved to %s.", application.ApplicantName, form.Stage))
+	http.Redirect(w, req, fmt.Sprintf("/recruiter/jobs/%d", posting.ID), http.StatusSeeOther)
+}
+
+// ==================== JOB BOARD ====================
+
+type jobSearchForm struct {
+	PracticeArea string `form:"area"`
+	Location     string `form:"location"`
+	Experience   int    `form:"experience"`
+	Page         int    `form:"page"`
+}
+
+func (app *application) jobs(w http.ResponseWriter, req *http.Request) {
+	var form jobSearchForm
+	err := app.decodeQuery(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	filter := models.JobPostingFilter{
+		PracticeArea: form.PracticeArea,
+		Location:     strings.TrimSpace(form.Location),
+		Experience:   max(form.Experience, 0),
+	}
+
+	page := newPagination(form.Page, jobPageSize, req.URL.Query())
+
+	postings, total, err := app.models.JobPostings.ListOpen(filter, page.PageSize, page.Offset())
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	page.Total = total
+
+	areas, err := app.models.JobPostings.PracticeAreas()
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	applications, err := app.models.JobApplications.ListForApplicant(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.JobPostings = postings
+	data.JobApplications = applications
+	data.AreasOfLaw = areas
+	data.Pagination = page
+	app.renderer(w, req, "jobs.tmpl.html", http.StatusOK, data)
+}
+
+type jobApplicationForm struct {
+	CoverLetter      string `form:"cover_letter"`
+	IncludePortfolio bool   `form:"include_portfolio"`
+	EvaluationIDs    []int  `form:"evaluation"`
+
+	validator.Validator `form:"-"`
+}
+
+// Attaches reports whether an evaluation is ticked to be attached on the form
+func (f jobApplicationForm) Attaches(evaluationID int) bool {
+	for _, id := range f.EvaluationIDs {
+		if id == evaluationID {
+			return true
+		}
+	}
+	return false
+}
+
+// jobPosting loads the posting named by the ":id" parameter. It writes the
+// error response and returns nil on failure.
+func (app *application) jobPosting(w http.ResponseWriter, req *http.Request) *models.JobPosting {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	posting, err := app.models.JobPostings.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	return posting
+}
+
+func (app *application) jobView(w http.ResponseWriter, req *http.Request) {
+	posting := app.jobPosting(w, req)
+	if posting == nil {
+		return
+	}
+
+	app.renderJob(w, req, posting, jobApplicationForm{}, http.StatusOK)
+}
+
+func (app *application) renderJob(w http.ResponseWriter, req *http.Request, posting *models.JobPosting, form jobApplicationForm, status int) {
+	userID := app.authenticatedUserID(req)
+
+	application, err := app.models.JobApplications.Find(posting.ID, userID)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, err)
+		return
+	}
+
+	// Closed postings are only shown to people who applied
+	if !posting.Open() && application == nil {
+		app.notFound(w)
+		return
+	}
+
+	evaluations, err := app.models.Evaluations.ListForUser(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	portfolio, err := app.models.Portfolios.Get(userID)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.JobPosting = posting
+	data.JobApplication = application
+	data.Evaluations = evaluations
+	if portfolio != nil && portfolio.IsPublic {
+		data.Portfolio = portfolio
+	}
+	app.renderer(w, req, "job.tmpl.html", status, data)
+}
+
+func (app *application) jobApplyPost(w http.ResponseWriter, req *http.Request) {
+	posting := app.jobPosting(w, req)
+	if posting == nil {
+		return
+	}
+
+	if !posting.Open() {
+		app.sessionManager.Put(req.Context(), "flash", "This posting is no longer taking applications.")
+		http.Redirect(w, req, "/jobs", http.StatusSeeOther)
+		return
+	}
+
+	var form jobApplicationForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.CoverLetter = strings.TrimSpace(form.CoverLetter)
+
+	form.CheckField(validator.NotBlank(form.CoverLetter), "cover_letter", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.CoverLetter, 5000), "cover_letter", "This field cannot be more than 5000 characters long")
+	form.CheckField(len(form.EvaluationIDs) <= models.MaxApplicationEvaluations, "evaluation", fmt.Sprintf("You can attach up to %d moot results", models.MaxApplicationEvaluations))
+
+	if !form.Valid() {
+		app.renderJob(w, req, posting, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	_, err = app.models.JobApplications.Apply(&models.JobApplication{
+		PostingID:        posting.ID,
+		ApplicantID:      app.authenticatedUserID(req),
+		CoverLetter:      form.CoverLetter,
+		IncludePortfolio: form.IncludePortfolio,
+		EvaluationIDs:    form.EvaluationIDs,
+	})
+	if err != nil && !errors.Is(err, models.ErrDuplicateApplication) {
+		app.serverError(w, err)
+		return
+	}
+
+	flash := fmt.Sprintf("Your application for %q has been sent.", posting.Title)
+	if err != nil {
+		flash = "You have already applied for this role."
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", flash)
+	http.Redirect(w, req, fmt.Sprintf("/jobs/%d", posting.ID), http.StatusSeeOther)
+}
@@ -0,0 +1,695 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"lawbook/internal/ical"
+	"lawbook/internal/mailer"
+	"lawbook/internal/models"
+	"lawbook/internal/notify"
+	"lawbook/internal/validator"
+)
+
+// mentorPageSize is the number of mentors listed per page
+const mentorPageSize = 20
+
+// maxMentorSlots is the most times a mentor can publish at once
+const maxMentorSlots = 5
+
+// maxPendingMentoringRequests is the most requests a student can have
+// waiting for an answer, so nobody holds every slot
+const maxPendingMentoringRequests = 3
+
+// mentorReviewsShown is the number of reviews shown on a mentor's profile
+const mentorReviewsShown = 20
+
+// mentoringRatings are the ratings a review can give
+var mentoringRatings = []int{5, 4, 3, 2, 1}
+
+// ==================== LAWYER: MENTORING PROFILE ====================
+
+type mentorProfileForm struct {
+	Headline            string   `form:"headline"`
+	Expertise           []string `form:"expertise"`
+	TimeZone            string   `form:"time_zone"`
+	Listed              bool     `form:"listed"`
+	validator.Validator `form:"-"`
+}
+
+// Judges reports whether a case type is ticked on the form
+func (f *mentorProfileForm) Judges(caseType string) bool {
+	return slices.Contains(f.Expertise, caseType)
+}
+
+func (f *mentorProfileForm) validate() {
+	f.Headline = strings.TrimSpace(f.Headline)
+
+	f.CheckField(validator.NotBlank(f.Headline), "headline", "This field cannot be blank")
+	f.CheckField(validator.MaxChars(f.Headline, 160), "headline", "This field cannot be more than 160 characters long")
+	f.CheckField(len(f.Expertise) > 0, "expertise", "Choose at least one case type")
+	for _, caseType := range f.Expertise {
+		f.CheckField(validCaseType(caseType), "expertise", "Choose from the case types listed")
+	}
+
+	_, ok := timeZoneLocation(f.TimeZone)
+	f.CheckField(ok, "time_zone", "Choose a time zone")
+}
+
+// mentorSlotsForm publishes times a mentor is available. They are read in
+// the time zone of the mentor's profile.
+type mentorSlotsForm struct {
+	Slots               []string `form:"slot"`
+	validator.Validator `form:"-"`
+
+	// starts holds the parsed slots once validated
+	starts []time.Time
+}
+
+// SlotValues returns the slot inputs to show, padded with empty ones
+func (f *mentorSlotsForm) SlotValues() []string {
+	values := make([]string, maxMentorSlots)
+	copy(values, f.Slots)
+	return values
+}
+
+func (app *application) mentorProfileEdit(w http.ResponseWriter, req *http.Request) {
+	mentor, err := app.models.Mentors.Get(app.authenticatedUserID(req))
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, err)
+		return
+	}
+
+	form := mentorProfileForm{TimeZone: timeZones[0].Name, Listed: true}
+	if mentor != nil {
+		form = mentorProfileForm{
+			Headline:  mentor.Headline,
+			Expertise: mentor.Expertise,
+			TimeZone:  mentor.TimeZone,
+			Listed:    mentor.IsListed,
+		}
+	}
+
+	app.renderMentorProfileEdit(w, req, mentor, form, mentorSlotsForm{}, http.StatusOK)
+}
+
+func (app *application) renderMentorProfileEdit(w http.ResponseWriter, req *http.Request, mentor *models.Mentor, form mentorProfileForm, slotsForm mentorSlotsForm, status int) {
+	data := app.newTemplateData(req)
+	data.Form = &form
+	data.SlotsForm = &slotsForm
+	data.Mentor = mentor
+	data.MootCaseTypes = mootCaseTypes
+	data.TimeZones = timeZones
+
+	if mentor != nil {
+		slots, err := app.models.Mentors.Slots(mentor.UserID, true)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		data.MentorSlots = slots
+	}
+
+	app.renderer(w, req, "mentor-edit.tmpl.html", status, data)
+}
+
+func (app *application) mentorProfileEditPost(w http.ResponseWriter, req *http.Request) {
+	var form mentorProfileForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+
+	form.validate()
+	if !form.Valid() {
+		mentor, err := app.models.Mentors.Get(userID)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, err)
+			return
+		}
+		app.renderMentorProfileEdit(w, req, mentor, form, mentorSlotsForm{}, http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = app.models.Mentors.Upsert(userID, form.Headline, form.Listed, form.TimeZone, form.Expertise)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	flash := "Your mentoring profile has been saved. Students can find you at /mentors."
+	if !form.Listed {
+		flash = "Your mentoring profile has been saved. It is hidden from students until you list it."
+	}
+	app.sessionManager.Put(req.Context(), "flash", flash)
+	http.Redirect(w, req, "/lawyer/mentoring", http.StatusSeeOther)
+}
+
+func (app *application) mentorSlotsPost(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	mentor, err := app.models.Mentors.Get(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "Save your mentoring profile before adding times.")
+			http.Redirect(w, req, "/lawyer/mentoring", http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	var form mentorSlotsForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	loc, _ := timeZoneLocation(mentor.TimeZone)
+	if loc == nil {
+		loc = time.UTC
+	}
+	form.Slots, form.starts = parseSlots(&form.Validator, form.Slots, loc, maxMentorSlots, time.Now())
+
+	if !form.Valid() {
+		profileForm := mentorProfileForm{
+			Headline:  mentor.Headline,
+			Expertise: mentor.Expertise,
+			TimeZone:  mentor.TimeZone,
+			Listed:    mentor.IsListed,
+		}
+		app.renderMentorProfileEdit(w, req, mentor, profileForm, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = app.models.Mentors.AddSlots(userID, form.starts)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Your availability has been updated.")
+	http.Redirect(w, req, "/lawyer/mentoring", http.StatusSeeOther)
+}
+
+func (app *application) mentorSlotDeletePost(w http.ResponseWriter, req *http.Request) {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	err = app.models.Mentors.DeleteSlot(id, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "That time has been requested by a student, so it can't be removed. Decline or cancel the request instead.")
+			http.Redirect(w, req, "/lawyer/mentoring", http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "The time has been removed.")
+	http.Redirect(w, req, "/lawyer/mentoring", http.StatusSeeOther)
+}
+
+// ==================== MENTORS ====================
+
+type mentorSearchForm struct {
+	CaseType string `form:"case_type"`
+	Page     int    `form:"page"`
+}
+
+func (app *application) mentors(w http.ResponseWriter, req *http.Request) {
+	var form mentorSearchForm
+	err := app.decodeQuery(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	page := newPagination(form.Page, mentorPageSize, req.URL.Query())
+
+	mentors, total, err := app.models.Mentors.List(form.CaseType, app.config.requireVerifiedLawyers, page.PageSize, page.Offset())
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	page.Total = total
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.Mentors = mentors
+	data.MootCaseTypes = mootCaseTypes
+	data.Pagination = page
+	app.renderer(w, req, "mentors.tmpl.html", http.StatusOK, data)
+}
+
+type mentoringRequestForm struct {
+	SlotID              int             `form:"slot_id"`
+	CaseType            string          `form:"case_type"`
+	Difficulty          string          `form:"difficulty"`
+	Role                models.MootRole `form:"role"`
+	Message             string          `form:"message"`
+	validator.Validator `form:"-"`
+}
+
+func (f *mentoringRequestForm) validate(mentor *models.Mentor) {
+	f.Message = strings.TrimSpace(f.Message)
+
+	f.CheckField(f.SlotID > 0, "slot_id", "Choose a time")
+	f.CheckField(slices.Contains(mentor.Expertise, f.CaseType), "case_type", "Choose one of the case types this mentor judges")
+	f.CheckField(slices.Contains(models.Difficulties, f.Difficulty), "difficulty", "Choose a difficulty")
+	f.CheckField(f.Role == models.MootAppellant || f.Role == models.MootRespondent, "role", "Choose which side you will argue")
+	f.CheckField(validator.MaxChars(f.Message, 1000), "message", "This field cannot be more than 1000 characters long")
+}
+
+// viewableMentor loads the mentor named by the ":id" parameter. Mentors who
+// aren't listed, or aren't verified when verification is required, are only
+// shown to themselves. It writes the error response and returns nil
+// otherwise.
+func (app *application) viewableMentor(w http.ResponseWriter, req *http.Request) *models.Mentor {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	mentor, err := app.models.Mentors.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	hidden := !mentor.IsListed || (app.config.requireVerifiedLawyers && !mentor.Verified)
+	if hidden && mentor.UserID != app.authenticatedUserID(req) {
+		app.notFound(w)
+		return nil
+	}
+
+	return mentor
+}
+
+// mentorRecord returns a lawyer's mentoring rating and latest reviews for
+// their profile pages. The mentor is nil if no student has reviewed them.
+func (app *application) mentorRecord(userID int) (*models.Mentor, []*models.MentoringReview, error) {
+	mentor, err := app.models.Mentors.Get(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	if mentor.Reviews == 0 {
+		return nil, nil, nil
+	}
+
+	reviews, err := app.models.MentoringRequests.ReviewsOf(userID, mentorReviewsShown)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mentor, reviews, nil
+}
+
+func (app *application) mentorView(w http.ResponseWriter, req *http.Request) {
+	mentor := app.viewableMentor(w, req)
+	if mentor == nil {
+		return
+	}
+
+	form := mentoringRequestForm{Difficulty: "medium", Role: models.MootAppellant}
+	if len(mentor.Expertise) == 1 {
+		form.CaseType = mentor.Expertise[0]
+	}
+
+	app.renderMentor(w, req, mentor, form, http.StatusOK)
+}
+
+func (app *application) renderMentor(w http.ResponseWriter, req *http.Request, mentor *models.Mentor, form mentoringRequestForm, status int) {
+	slots, err := app.models.Mentors.Slots(mentor.UserID, false)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	reviews, err := app.models.MentoringRequests.ReviewsOf(mentor.UserID, mentorReviewsShown)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = &form
+	data.Mentor = mentor
+	data.MentorSlots = slots
+	data.MentoringReviews = reviews
+	data.MootCaseTypes = mootCaseTypes
+	data.Difficulties = models.Difficulties
+	app.renderer(w, req, "mentor.tmpl.html", status, data)
+}
+
+func (app *application) mentorRequestPost(w http.ResponseWriter, req *http.Request) {
+	mentor := app.viewableMentor(w, req)
+	if mentor == nil {
+		return
+	}
+
+	var form mentoringRequestForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	studentID := app.authenticatedUserID(req)
+
+	pending, err := app.models.MentoringRequests.CountPending(studentID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	form.validate(mentor)
+	if pending >= maxPendingMentoringRequests {
+		form.AddNonFieldError(fmt.Sprintf("You already have %d requests waiting for an answer. Wait for a mentor to reply, or withdraw one, before asking again.", pending))
+	}
+
+	if !form.Valid() {
+		app.renderMentor(w, req, mentor, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	id, err := app.models.MentoringRequests.Request(form.SlotID, mentor.UserID, studentID, form.CaseType, form.Difficulty, form.Role, form.Message)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			form.AddFieldErrors("slot_id", "That time has just been taken. Choose another.")
+			app.renderMentor(w, req, mentor, form, http.StatusUnprocessableEntity)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	r, err := app.models.MentoringRequests.Get(id, studentID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.notifyMentoring(r, r.MentorID,
+		fmt.Sprintf("%s has asked you to judge their moot", r.StudentName),
+		"Accept to book the moot in both your calendars, or decline to free the time.", nil)
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("Your request has been sent to %s.", mentor.Name))
+	http.Redirect(w, req, fmt.Sprintf("/mentoring/%d", id), http.StatusSeeOther)
+}
+
+// ==================== MENTORING REQUESTS ====================
+
+func (app *application) mentoring(w http.ResponseWriter, req *http.Request) {
+	requests, err := app.models.MentoringRequests.ListForUser(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.MentoringRequests = requests
+	app.renderer(w, req, "mentoring.tmpl.html", http.StatusOK, data)
+}
+
+// userMentoringRequest loads the request named by the ":id" parameter. It
+// writes the error response and returns nil if the user isn't a party to it.
+func (app *application) userMentoringRequest(w http.ResponseWriter, req *http.Request) *models.MentoringRequest {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	r, err := app.models.MentoringRequests.Get(id, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	return r
+}
+
+type mentoringReviewForm struct {
+	Rating              int    `form:"rating"`
+	Comment             string `form:"comment"`
+	validator.Validator `form:"-"`
+}
+
+func (app *application) mentoringView(w http.ResponseWriter, req *http.Request) {
+	r := app.userMentoringRequest(w, req)
+	if r == nil {
+		return
+	}
+
+	app.renderMentoringRequest(w, req, r, mentoringReviewForm{Rating: 5}, http.StatusOK)
+}
+
+func (app *application) renderMentoringRequest(w http.ResponseWriter, req *http.Request, r *models.MentoringRequest, form mentoringReviewForm, status int) {
+	reviews, err := app.models.MentoringRequests.Reviews(r.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+
+	data := app.newTemplateData(req)
+	data.Form = &form
+	data.MentoringRequest = r
+	data.MentoringReviews = reviews
+	data.MentoringRatings = mentoringRatings
+	data.Reviewed = slices.ContainsFunc(reviews, func(rv *models.MentoringReview) bool { return rv.ReviewerID == userID })
+	app.renderer(w, req, "mentoring-request.tmpl.html", status, data)
+}
+
+func (app *application) mentoringAcceptPost(w http.ResponseWriter, req *http.Request) {
+	r := app.userMentoringRequest(w, req)
+	if r == nil {
+		return
+	}
+
+	mentorID := app.authenticatedUserID(req)
+	if !r.IsMentor(mentorID) {
+		app.clientError(w, http.StatusForbidden)
+		return
+	}
+
+	requestURL := fmt.Sprintf("/mentoring/%d", r.ID)
+
+	mootID, err := app.models.MentoringRequests.Accept(r.ID, mentorID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "This request has already been answered, withdrawn or has passed.")
+			http.Redirect(w, req, requestURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	moot, err := app.models.MootSessions.Get(mootID, mentorID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	invite := app.mootAttachment(moot, ical.MethodRequest)
+	checkIn := fmt.Sprintf("Check in from the moot page %d minutes before the start; if either of you doesn't, it is cancelled.",
+		int(mootCheckInOpens.Minutes()))
+
+	app.notifyMentoring(r, r.StudentID,
+		fmt.Sprintf("%s will judge your moot", r.MentorName),
+		"Your moot is booked. "+checkIn, invite)
+	app.notifyMentoring(r, r.MentorID,
+		fmt.Sprintf("Your moot with %s is booked", r.StudentName),
+		checkIn, invite)
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("The moot is booked. %s has been sent a calendar invite.", r.StudentName))
+	http.Redirect(w, req, requestURL, http.StatusSeeOther)
+}
+
+func (app *application) mentoringDeclinePost(w http.ResponseWriter, req *http.Request) {
+	r := app.userMentoringRequest(w, req)
+	if r == nil {
+		return
+	}
+
+	mentorID := app.authenticatedUserID(req)
+	if !r.IsMentor(mentorID) {
+		app.clientError(w, http.StatusForbidden)
+		return
+	}
+
+	requestURL := fmt.Sprintf("/mentoring/%d", r.ID)
+
+	err := app.models.MentoringRequests.Decline(r.ID, mentorID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "This request has already been answered or withdrawn.")
+			http.Redirect(w, req, requestURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	app.notifyMentoring(r, r.StudentID,
+		fmt.Sprintf("%s can't judge your moot", r.MentorName),
+		"Try another of their times, or another mentor.", nil)
+
+	app.sessionManager.Put(req.Context(), "flash", "The request has been declined and the time freed.")
+	http.Redirect(w, req, requestURL, http.StatusSeeOther)
+}
+
+func (app *application) mentoringCancelPost(w http.ResponseWriter, req *http.Request) {
+	r := app.userMentoringRequest(w, req)
+	if r == nil {
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+	requestURL := fmt.Sprintf("/mentoring/%d", r.ID)
+
+	err := app.models.MentoringRequests.Cancel(r.ID, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "This request can no longer be cancelled.")
+			http.Redirect(w, req, requestURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	var cancellation []mailer.Attachment
+	if r.Booked() {
+		moot, err := app.models.MootSessions.Get(int(r.MootSessionID.Int64), userID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		cancellation = app.mootAttachment(moot, ical.MethodCancel)
+	}
+
+	name := r.StudentName
+	if r.IsMentor(userID) {
+		name = r.MentorName
+	}
+
+	title := fmt.Sprintf("%s has withdrawn their request", name)
+	if r.Booked() {
+		title = fmt.Sprintf("%s has cancelled your mentored moot", name)
+	}
+	app.notifyMentoring(r, r.OtherID(userID), title, "", cancellation)
+
+	app.sessionManager.Put(req.Context(), "flash", "Cancelled. The other party has been told.")
+	http.Redirect(w, req, requestURL, http.StatusSeeOther)
+}
+
+func (app *application) mentoringReviewPost(w http.ResponseWriter, req *http.Request) {
+	r := app.userMentoringRequest(w, req)
+	if r == nil {
+		return
+	}
+
+	requestURL := fmt.Sprintf("/mentoring/%d", r.ID)
+
+	if !r.Happened() {
+		app.sessionManager.Put(req.Context(), "flash", "You can leave a review once the moot has taken place.")
+		http.Redirect(w, req, requestURL, http.StatusSeeOther)
+		return
+	}
+
+	var form mentoringReviewForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Comment = strings.TrimSpace(form.Comment)
+
+	form.CheckField(validator.PermittedInt(form.Rating, mentoringRatings...), "rating", "Choose a rating")
+	form.CheckField(validator.MaxChars(form.Comment, 1000), "comment", "This field cannot be more than 1000 characters long")
+
+	if !form.Valid() {
+		app.renderMentoringRequest(w, req, r, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+
+	err = app.models.MentoringRequests.Review(r.ID, userID, r.OtherID(userID), form.Rating, form.Comment)
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicateReview) {
+			app.sessionManager.Put(req.Context(), "flash", "You have already reviewed this moot.")
+			http.Redirect(w, req, requestURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	if r.IsMentor(userID) {
+		app.notifyMentoring(r, r.StudentID,
+			fmt.Sprintf("%s has left you feedback", r.MentorName),
+			fmt.Sprintf("They rated your advocacy %d out of 5.", form.Rating), nil)
+	} else {
+		app.notifyMentoring(r, r.MentorID,
+			fmt.Sprintf("%s has reviewed your mentoring", r.StudentName),
+			fmt.Sprintf("They rated the moot %d out of 5. The review is shown on your mentor profile.", form.Rating), nil)
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Thanks for your review.")
+	http.Redirect(w, req, requestURL, http.StatusSeeOther)
+}
+
+// ==================== MENTORING NOTIFICATIONS ====================
+
+// mentoringSummary describes a mentored moot, such as "Criminal Law, medium
+// difficulty"
+func mentoringSummary(r *models.MentoringRequest) string {
+	return fmt.Sprintf("%s, %s difficulty", caseTypeLabel(r.CaseType), r.Difficulty)
+}
+
+// notifyMentoring tells one party to a mentoring request about a change to
+// it
+func (app *application) notifyMentoring(r *models.MentoringRequest, userID int, title, body string, attachments []mailer.Attachment) {
+	app.publish(userID, notify.Event{
+		Type:     models.NotifyMentoring,
+		Title:    title,
+		Body:     body,
+		URL:      fmt.Sprintf("/mentoring/%d", r.ID),
+		Template: "mentoring.tmpl",
+		Data: map[string]any{
+			"Request": r,
+			"Summary": mentoringSummary(r),
+			"When":    zonedDate(r.StartsAt, r.TimeZone),
+		},
+		Attachments: attachments,
+	})
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"lawbook/internal/models"
+	"lawbook/internal/notify"
+	"lawbook/internal/validator"
+)
+
+// Recruiters can start at most maxNewConversations conversations in any
+// conversationWindow, so candidates aren't flooded with contact requests
+const (
+	maxNewConversations = 20
+	conversationWindow  = 24 * time.Hour
+)
+
+// ==================== RECRUITER: CONTACT REQUESTS ====================
+
+type contactForm struct {
+	Subject             string `form:"subject"`
+	Message             string `form:"message"`
+	validator.Validator `form:"-"`
+}
+
+func (app *application) recruiterCandidateContactPost(w http.ResponseWriter, req *http.Request) {
+	candidate := app.recruiterCandidate(w, req)
+	if candidate == nil {
+		return
+	}
+
+	var form contactForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	recruiterID := app.authenticatedUserID(req)
+	candidateURL := fmt.Sprintf("/recruiter/candidates/%d", candidate.ID)
+
+	form.Subject = strings.TrimSpace(form.Subject)
+	form.Message = strings.TrimSpace(form.Message)
+
+	form.CheckField(validator.NotBlank(form.Subject), "subject", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Subject, 150), "subject", "This field cannot be more than 150 characters long")
+	form.CheckField(validator.NotBlank(form.Message), "message", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Message, 5000), "message", "This field cannot be more than 5000 characters long")
+
+	blocked, err := app.models.Conversations.Blocked(recruiterID, candidate.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	if blocked {
+		form.AddNonFieldError("You can't contact this candidate.")
+	}
+
+	started, err := app.models.Conversations.CountStartedSince(recruiterID, time.Now().Add(-conversationWindow))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	if started >= maxNewConversations {
+		form.AddNonFieldError(fmt.Sprintf("You can contact up to %d new candidates a day. Please try again later.", maxNewConversations))
+	}
+
+	if !form.Valid() {
+		noteForm, err := app.candidateNoteForm(recruiterID, candidate.ID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		app.renderCandidate(w, req, candidate, noteForm, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	id, err := app.models.Conversations.Start(recruiterID, candidate.ID, form.Subject, form.Message)
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicateConversation) {
+			app.sessionManager.Put(req.Context(), "flash", "You have already contacted this candidate.")
+			http.Redirect(w, req, candidateURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	conversation, err := app.models.Conversations.Get(id, recruiterID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.notifyMessage(conversation, recruiterID, true)
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("Your contact request has been sent to %s.", candidate.Name))
+	http.Redirect(w, req, fmt.Sprintf("/messages/%d", id), http.StatusSeeOther)
+}
+
+// notifyMessage tells the participant in a conversation who didn't send the
+// latest message about it. request is set for the recruiter's first message.
+func (app *application) notifyMessage(c *models.Conversation, senderID int, request bool) {
+	sender := c.CandidateName
+	if c.IsRecruiter(senderID) {
+		sender = c.RecruiterName
+		if c.CompanyName != "" {
+			sender += " (" + c.CompanyName + ")"
+		}
+	}
+
+	title, template := fmt.Sprintf("New message from %s", sender), "new-message.tmpl"
+	if request {
+		title, template = fmt.Sprintf("%s would like to contact you", sender), "contact-request.tmpl"
+	}
+
+	app.publish(c.OtherID(senderID), notify.Event{
+		Type:     models.NotifyMessage,
+		Title:    title,
+		Body:     c.Subject,
+		URL:      fmt.Sprintf("/messages/%d", c.ID),
+		Template: template,
+		Data: map[string]any{
+			"Sender":  sender,
+			"Subject": c.Subject,
+		},
+	})
+}
+
+// ==================== MESSAGES ====================
+
+// messageForm is used by both the reply and report forms on a conversation
+type messageForm struct {
+	Body                string `form:"body"`
+	Reason              string `form:"reason"`
+	validator.Validator `form:"-"`
+}
+
+func (app *application) messages(w http.ResponseWriter, req *http.Request) {
+	conversations, err := app.models.Conversations.ListForUser(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Conversations = conversations
+	app.renderer(w, req, "messages.tmpl.html", http.StatusOK, data)
+}
+
+// userConversation loads the conversation named by the ":id" parameter. It
+// writes the error response and returns nil if the user isn't taking part.
+func (app *application) userConversation(w http.ResponseWriter, req *http.Request) *models.Conversation {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	conversation, err := app.models.Conversations.Get(id, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	return conversation
+}
+
+func (app *application) conversationView(w http.ResponseWriter, req *http.Request) {
+	conversation := app.userConversation(w, req)
+	if conversation == nil {
+		return
+	}
+
+	err := app.models.Conversations.MarkRead(conversation, app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.renderConversation(w, req, conversation, messageForm{}, http.StatusOK)
+}
+
+func (app *application) renderConversation(w http.ResponseWriter, req *http.Request, c *models.Conversation, form messageForm, status int) {
+	userID := app.authenticatedUserID(req)
+
+	messages, err := app.models.Conversations.Messages(c.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	blocked, err := app.models.Conversations.HasBlocked(userID, c.OtherID(userID))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.Conversation = c
+	data.Messages = messages
+	data.BlockedUser = blocked
+	app.renderer(w, req, "conversation.tmpl.html", status, data)
+}
+
+func (app *application) conversationReplyPost(w http.ResponseWriter, req *http.Request) {
+	conversation := app.userConversation(w, req)
+	if conversation == nil {
+		return
+	}
+
+	var form messageForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+	form.Body = strings.TrimSpace(form.Body)
+
+	form.CheckField(validator.NotBlank(form.Body), "body", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Body, 5000), "body", "This field cannot be more than 5000 characters long")
+
+	if !conversation.Accepted() {
+		form.AddNonFieldError("You can reply once the contact request has been accepted.")
+	}
+
+	blocked, err := app.models.Conversations.Blocked(userID, conversation.OtherID(userID))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	if blocked {
+		form.AddNonFieldError("Messages can't be sent in this conversation.")
+	}
+
+	if !form.Valid() {
+		app.renderConversation(w, req, conversation, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = app.models.Conversations.Send(conversation, userID, form.Body)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.notifyMessage(conversation, userID, false)
+
+	http.Redirect(w, req, fmt.Sprintf("/messages/%d", conversation.ID), http.StatusSeeOther)
+}
+
+func (app *application) conversationAcceptPost(w http.ResponseWriter, req *http.Request) {
+	app.answerContactRequest(w, req, true)
+}
+
+func (app *application) conversationDeclinePost(w http.ResponseWriter, req *http.Request) {
+	app.answerContactRequest(w, req, false)
+}
+
+// answerContactRequest accepts or declines a pending contact request. Only
+// the candidate can answer.
+func (app *application) answerContactRequest(w http.ResponseWriter, req *http.Request, accept bool) {
+	conversation := app.userConversation(w, req)
+	if conversation == nil {
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+	if conversation.IsRecruiter(userID) {
+		app.clientError(w, http.StatusForbidden)
+		return
+	}
+
+	err := app.models.Conversations.Answer(conversation.ID, userID, accept)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "This contact request has already been answered.")
+			http.Redirect(w, req, fmt.Sprintf("/messages/%d", conversation.ID), http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	flash := fmt.Sprintf("You have declined %s's contact request.", conversation.RecruiterName)
+	if accept {
+		flash = fmt.Sprintf("You can now message %s.", conversation.RecruiterName)
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", flash)
+	http.Redirect(w, req, fmt.Sprintf("/messages/%d", conversation.ID), http.StatusSeeOther)
+}
+
+func (app *application) conversationBlockPost(w http.ResponseWriter, req *http.Request) {
+	conversation := app.userConversation(w, req)
+	if conversation == nil {
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+
+	err := app.models.Conversations.Block(userID, conversation.OtherID(userID))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "You won't receive any more messages from this user.")
+	http.Redirect(w, req, fmt.Sprintf("/messages/%d", conversation.ID), http.StatusSeeOther)
+}
+
+func (app *application) conversationUnblockPost(w http.ResponseWriter, req *http.Request) {
+	conversation := app.userConversation(w, req)
+	if conversation == nil {
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+
+	err := app.models.Conversations.Unblock(userID, conversation.OtherID(userID))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "This user has been unblocked.")
+	http.Redirect(w, req, fmt.Sprintf("/messages/%d", conversation.ID), http.StatusSeeOther)
+}
+
+func (app *application) conversationReportPost(w http.ResponseWriter, req *http.Request) {
+	conversation := app.userConversation(w, req)
+	if conversation == nil {
+		return
+	}
+
+	var form messageForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Reason = strings.TrimSpace(form.Reason)
+
+	form.CheckField(validator.NotBlank(form.Reason), "reason", "Please tell us what's wrong")
+	form.CheckField(validator.MaxChars(form.Reason, 500), "reason", "This field cannot be more than 500 characters long")
+
+	if !form.Valid() {
+		app.renderConversation(w, req, conversation, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = app.models.Conversations.Report(conversation.ID, app.authenticatedUserID(req), form.Reason)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Thank you. An administrator will review this conversation.")
+	http.Redirect(w, req, fmt.Sprintf("/messages/%d", conversation.ID), http.StatusSeeOther)
+}
+
+// ==================== ADMIN: REPORTED CONVERSATIONS ====================
+
+func (app *application) adminReports(w http.ResponseWriter, req *http.Request) {
+	pageNumber, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	page := newPagination(pageNumber, adminPageSize, req.URL.Query())
+
+	reports, total, err := app.models.Conversations.Reports(page.PageSize, page.Offset())
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	page.Total = total
+
+	data := app.newTemplateData(req)
+	data.ConversationReports = reports
+	data.Pagination = page
+	app.renderer(w, req, "admin-reports.tmpl.html", http.StatusOK, data)
+}
+
+func (app *application) adminConversationView(w http.ResponseWriter, req *http.Request) {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	conversation, err := app.models.Conversations.Reported(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	messages, err := app.models.Conversations.Messages(conversation.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Conversation = conversation
+	data.Messages = messages
+	app.renderer(w, req, "admin-conversation.tmpl.html", http.StatusOK, data)
+}
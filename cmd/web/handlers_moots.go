@@ -0,0 +1,614 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"lawbook/internal/ical"
+	"lawbook/internal/mailer"
+	"lawbook/internal/models"
+	"lawbook/internal/notify"
+	"lawbook/internal/validator"
+)
+
+// Participants can check in to a scheduled moot from mootCheckInOpens before
+// the start. Anyone who hasn't by mootCheckInGrace after it is a no-show.
+const (
+	mootCheckInOpens = 15 * time.Minute
+	mootCheckInGrace = 10 * time.Minute
+)
+
+// mootReminderLead is how long before a scheduled moot its participants are
+// reminded
+const mootReminderLead = time.Hour
+
+// mootCaseType is a kind of case a moot can be argued on
+type mootCaseType struct {
+	Value string
+	Label string
+}
+
+// mootCaseTypes are the case types offered when setting up a moot
+var mootCaseTypes = []mootCaseType{
+	{"constitutional", "Constitutional Law"},
+	{"criminal", "Criminal Law"},
+	{"civil", "Civil Law"},
+	{"corporate", "Corporate Law"},
+	{"family", "Family Law"},
+}
+
+// caseTypeLabel names one of mootCaseTypes, such as "Criminal Law"
+func caseTypeLabel(value string) string {
+	for _, c := range mootCaseTypes {
+		if c.Value == value {
+			return c.Label
+		}
+	}
+	return value
+}
+
+// validCaseType reports whether value is one of mootCaseTypes
+func validCaseType(value string) bool {
+	return slices.ContainsFunc(mootCaseTypes, func(c mootCaseType) bool { return c.Value == value })
+}
+
+// ==================== MOOT COURT: SCHEDULING ====================
+
+// mootScheduleForm books a dual or trio moot. Participants are invited by
+// email address, one field per role; the organiser's own role is left blank.
+type mootScheduleForm struct {
+	SessionType         string              `form:"session_type"`
+	Role                models.MootRole     `form:"role"`
+	CaseType            string              `form:"case_type"`
+	Difficulty          string              `form:"difficulty"`
+	Appellant           string              `form:"appellant"`
+	Respondent          string              `form:"respondent"`
+	Judge               string              `form:"judge"`
+	StartsAt            string              `form:"starts_at"`
+	TimeZone            string              `form:"time_zone"`
+	NoShow              models.NoShowPolicy `form:"no_show"`
+	validator.Validator `form:"-"`
+
+	// start holds the parsed start time once validated
+	start time.Time
+}
+
+// invitee returns the email address entered for a role, with the field
+// holding it
+func (f *mootScheduleForm) invitee(role models.MootRole) (email, field string) {
+	switch role {
+	case models.MootAppellant:
+		return f.Appellant, "appellant"
+	case models.MootRespondent:
+		return f.Respondent, "respondent"
+	default:
+		return f.Judge, "judge"
+	}
+}
+
+// roles returns the roles people play in the moot. Dual moots have an AI
+// judge.
+func (f *mootScheduleForm) roles() []models.MootRole {
+	if f.SessionType == "trio" {
+		return models.MootRoles
+	}
+	return []models.MootRole{models.MootAppellant, models.MootRespondent}
+}
+
+func (f *mootScheduleForm) validate(now time.Time) {
+	f.Appellant = strings.TrimSpace(f.Appellant)
+	f.Respondent = strings.TrimSpace(f.Respondent)
+	f.Judge = strings.TrimSpace(f.Judge)
+
+	f.CheckField(f.SessionType == "dual_player" || f.SessionType == "trio", "session_type", "Choose who is taking part")
+	f.CheckField(slices.Contains(f.roles(), f.Role), "role", "Choose a role you can play in this moot")
+	f.CheckField(validCaseType(f.CaseType), "case_type", "Choose a case type")
+	f.CheckField(slices.Contains(models.Difficulties, f.Difficulty), "difficulty", "Choose a difficulty")
+	f.CheckField(f.NoShow == models.NoShowAI || f.NoShow == models.NoShowCancel, "no_show", "Choose what happens if someone doesn't turn up")
+
+	for _, role := range f.roles() {
+		if role == f.Role {
+			continue
+		}
+		email, field := f.invitee(role)
+		f.CheckField(validator.NotBlank(email), field, "Enter the email address of the person taking this role")
+	}
+
+	loc, ok := timeZoneLocation(f.TimeZone)
+	if !ok {
+		f.AddFieldErrors("time_zone", "Choose a time zone")
+		return
+	}
+
+	start, err := time.ParseInLocation(datetimeLocalLayout, strings.TrimSpace(f.StartsAt), loc)
+	switch {
+	case err != nil:
+		f.AddFieldErrors("starts_at", "Enter a date and time")
+	case !start.After(now.Add(mootCheckInOpens)):
+		f.AddFieldErrors("starts_at", fmt.Sprintf("The moot must start at least %d minutes from now", int(mootCheckInOpens.Minutes())))
+	default:
+		f.start = start
+	}
+}
+
+// mootCantInvite is the error for anyone who can't be invited to a moot.
+// It is the same whatever the reason, so the form can't be used to find out
+// who has an account.
+const mootCantInvite = "This person can't be invited. Check the address, or ask them to join Lawbook as a student or lawyer."
+
+// mootSeats looks up the people invited to a moot and assigns each their
+// role, adding a field error for anyone who can't take part
+func (app *application) mootSeats(f *mootScheduleForm, organiser *models.User) ([]models.MootSeat, error) {
+	seats := []models.MootSeat{{UserID: organiser.ID, Role: f.Role}}
+
+	for _, role := range f.roles() {
+		if role == f.Role {
+			continue
+		}
+		email, field := f.invitee(role)
+
+		user, err := app.models.Users.GetByEmail(email)
+		if err != nil {
+			if !errors.Is(err, models.ErrNoRecord) {
+				return nil, err
+			}
+			f.AddFieldErrors(field, mootCantInvite)
+			continue
+		}
+
+		switch {
+		case !user.IsActive || (user.Role != models.RoleStudent && user.Role != models.RoleLawyer):
+			f.AddFieldErrors(field, mootCantInvite)
+			continue
+		case slices.ContainsFunc(seats, func(s models.MootSeat) bool { return s.UserID == user.ID }):
+			f.AddFieldErrors(field, "Each role must be taken by a different person")
+			continue
+		}
+
+		if app.config.requireVerifiedLawyers && user.Role == models.RoleLawyer {
+			verified, err := app.models.LawyerVerifications.IsVerified(user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if !verified {
+				f.AddFieldErrors(field, mootCantInvite)
+				continue
+			}
+		}
+
+		seats = append(seats, models.MootSeat{UserID: user.ID, Role: role})
+	}
+
+	return seats, nil
+}
+
+func (app *application) mootSchedule(w http.ResponseWriter, req *http.Request) {
+	form := mootScheduleForm{
+		SessionType: "dual_player",
+		Role:        models.MootAppellant,
+		Difficulty:  "medium",
+		TimeZone:    timeZones[0].Name,
+		NoShow:      models.NoShowAI,
+	}
+	app.renderMootSchedule(w, req, form, http.StatusOK)
+}
+
+func (app *application) renderMootSchedule(w http.ResponseWriter, req *http.Request, form mootScheduleForm, status int) {
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.MootCaseTypes = mootCaseTypes
+	data.Difficulties = models.Difficulties
+	data.TimeZones = timeZones
+	app.renderer(w, req, "moot-schedule.tmpl.html", status, data)
+}
+
+func (app *application) mootSchedulePost(w http.ResponseWriter, req *http.Request) {
+	var form mootScheduleForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	organiser, err := app.models.Users.Get(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	form.validate(time.Now())
+
+	var seats []models.MootSeat
+	if form.Valid() {
+		seats, err = app.mootSeats(&form, organiser)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	if !form.Valid() {
+		app.renderMootSchedule(w, req, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	id, err := app.models.MootSessions.Schedule(organiser.ID, form.SessionType, form.CaseType, form.Difficulty, form.start, form.TimeZone, form.NoShow, seats)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	moot, err := app.models.MootSessions.Get(id, organiser.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.notifyMootInvited(moot)
+
+	app.sessionManager.Put(req.Context(), "flash", "Your moot is scheduled. The other participants have been sent an invitation.")
+	http.Redirect(w, req, fmt.Sprintf("/moot/sessions/%d", id), http.StatusSeeOther)
+}
+
+// ==================== MOOT COURT: SCHEDULED MOOTS ====================
+
+func (app *application) moots(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	moots, err := app.models.MootSessions.ListScheduled(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	feedURL, err := app.calendarFeedURL(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.MootSessions = moots
+	data.CalendarFeedURL = feedURL
+	app.renderer(w, req, "moots.tmpl.html", http.StatusOK, data)
+}
+
+// userMoot loads the moot named by the ":id" parameter. It writes the error
+// response and returns nil if the user isn't taking part.
+func (app *application) userMoot(w http.ResponseWriter, req *http.Request) *models.MootSession {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	moot, err := app.models.MootSessions.Get(id, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	return moot
+}
+
+func (app *application) mootView(w http.ResponseWriter, req *http.Request) {
+	moot := app.userMoot(w, req)
+	if moot == nil {
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.MootSession = moot
+	data.CheckInOpen = moot.Upcoming() && time.Now().After(moot.ScheduledAt.Time.Add(-mootCheckInOpens))
+	app.renderer(w, req, "moot.tmpl.html", http.StatusOK, data)
+}
+
+type mootRSVPForm struct {
+	Answer models.RSVP `form:"answer"`
+}
+
+func (app *application) mootRSVPPost(w http.ResponseWriter, req *http.Request) {
+	moot := app.userMoot(w, req)
+	if moot == nil {
+		return
+	}
+
+	var form mootRSVPForm
+	err := app.decodePostForm(req, &form)
+	if err != nil || (form.Answer != models.RSVPYes && form.Answer != models.RSVPNo) {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+	mootURL := fmt.Sprintf("/moot/sessions/%d", moot.ID)
+
+	participant := moot.Participant(userID)
+	if participant.RSVP == form.Answer {
+		http.Redirect(w, req, mootURL, http.StatusSeeOther)
+		return
+	}
+
+	err = app.models.MootSessions.SetRSVP(moot.ID, userID, form.Answer)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "This moot has already started or been cancelled.")
+			http.Redirect(w, req, mootURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	if !moot.IsOrganiser(userID) {
+		app.notifyMootRSVP(moot, participant, form.Answer)
+	}
+
+	flash := "Thanks, you're down as coming. Check in from 15 minutes before the start."
+	if form.Answer == models.RSVPNo {
+		flash = "Thanks, the organiser has been told you can't make it."
+	}
+	app.sessionManager.Put(req.Context(), "flash", flash)
+	http.Redirect(w, req, mootURL, http.StatusSeeOther)
+}
+
+// mootCheckInPost records that a participant is ready. A moot starts once
+// everyone has checked in, or at the deadline without those who haven't.
+func (app *application) mootCheckInPost(w http.ResponseWriter, req *http.Request) {
+	moot := app.userMoot(w, req)
+	if moot == nil {
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+	mootURL := fmt.Sprintf("/moot/sessions/%d", moot.ID)
+
+	if moot.Participant(userID).CheckedInAt.Valid {
+		http.Redirect(w, req, mootURL, http.StatusSeeOther)
+		return
+	}
+
+	err := app.models.MootSessions.CheckIn(moot.ID, userID, mootCheckInOpens)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "Check-in isn't open for this moot.")
+			http.Redirect(w, req, mootURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "You're checked in. The moot starts when everyone is here.")
+	http.Redirect(w, req, mootURL, http.StatusSeeOther)
+}
+
+func (app *application) mootCancelPost(w http.ResponseWriter, req *http.Request) {
+	moot := app.userMoot(w, req)
+	if moot == nil {
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+	if !moot.IsOrganiser(userID) {
+		app.clientError(w, http.StatusForbidden)
+		return
+	}
+
+	mootURL := fmt.Sprintf("/moot/sessions/%d", moot.ID)
+
+	err := app.models.MootSessions.Cancel(moot.ID, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "This moot has already started or been cancelled.")
+			http.Redirect(w, req, mootURL, http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	moot, err = app.models.MootSessions.Get(moot.ID, userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.notifyMootCancelled(moot, fmt.Sprintf("%s cancelled it.", moot.CreatorName))
+
+	app.sessionManager.Put(req.Context(), "flash", "The moot has been cancelled and the other participants told.")
+	http.Redirect(w, req, mootURL, http.StatusSeeOther)
+}
+
+// mootICS downloads a scheduled moot as an iCalendar file
+func (app *application) mootICS(w http.ResponseWriter, req *http.Request) {
+	moot := app.userMoot(w, req)
+	if moot == nil {
+		return
+	}
+
+	if !moot.ScheduledAt.Valid {
+		app.notFound(w)
+		return
+	}
+
+	method := ical.MethodRequest
+	if moot.Cancelled() {
+		method = ical.MethodCancel
+	}
+
+	calendar := &ical.Calendar{Method: method, Events: []ical.Event{app.mootEvent(moot)}}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="moot-%d.ics"`, moot.ID))
+	w.Write(calendar.Bytes(time.Now()))
+}
+
+// ==================== MOOT NOTIFICATIONS ====================
+
+// mootTime formats when a scheduled moot starts in its own time zone
+func mootTime(s *models.MootSession) string {
+	return zonedDate(s.ScheduledAt.Time, s.TimeZone)
+}
+
+// mootTitle describes a moot, such as "Criminal Law moot (trio, hard)"
+func mootTitle(s *models.MootSession) string {
+	caseType := "General"
+	if s.CaseType != "" {
+		caseType = caseTypeLabel(s.CaseType)
+	}
+	return fmt.Sprintf("%s moot (%s, %s)", caseType, sessionTypeDisplay(s.SessionType), s.Difficulty)
+}
+
+// mootAttachment returns a scheduled moot as a calendar invite, or its
+// cancellation
+func (app *application) mootAttachment(s *models.MootSession, method string) []mailer.Attachment {
+	calendar := &ical.Calendar{Method: method, Events: []ical.Event{app.mootEvent(s)}}
+
+	return []mailer.Attachment{{
+		Filename:    "invite.ics",
+		ContentType: ical.ContentType + "; method=" + method,
+		Data:        calendar.Bytes(time.Now()),
+	}}
+}
+
+// notifyMoot tells each of the given participants about a scheduled moot
+func (app *application) notifyMoot(s *models.MootSession, participants []*models.MootParticipant, title, body string, attachments []mailer.Attachment) {
+	for _, p := range participants {
+		app.publish(p.UserID, notify.Event{
+			Type:     models.NotifyMoot,
+			Title:    title,
+			Body:     body,
+			URL:      fmt.Sprintf("/moot/sessions/%d", s.ID),
+			Template: "moot.tmpl",
+			Data: map[string]any{
+				"Moot":      s,
+				"MootTitle": mootTitle(s),
+				"When":      mootTime(s),
+				"Role":      mootRoleDisplay(p.Role),
+			},
+			Attachments: attachments,
+		})
+	}
+}
+
+// mootOthers returns the participants other than userID
+func mootOthers(s *models.MootSession, userID int) []*models.MootParticipant {
+	var ps []*models.MootParticipant
+	for _, p := range s.Participants {
+		if p.UserID != userID {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
+// notifyMootInvited sends the invited participants a calendar invite
+func (app *application) notifyMootInvited(s *models.MootSession) {
+	app.notifyMoot(s, mootOthers(s, s.CreatedBy),
+		fmt.Sprintf("%s has invited you to a moot", s.CreatorName),
+		fmt.Sprintf("%s, %s. Let them know whether you can make it.", mootTitle(s), mootTime(s)),
+		app.mootAttachment(s, ical.MethodRequest))
+}
+
+// notifyMootRSVP tells the organiser how a participant answered
+func (app *application) notifyMootRSVP(s *models.MootSession, p *models.MootParticipant, answer models.RSVP) {
+	title := fmt.Sprintf("%s is coming to your moot", p.Name)
+	if answer == models.RSVPNo {
+		title = fmt.Sprintf("%s can't make your moot", p.Name)
+	}
+
+	body := fmt.Sprintf("%s, %s.", mootTitle(s), mootTime(s))
+	if answer == models.RSVPNo {
+		if s.NoShowPolicy == models.NoShowAI {
+			body += fmt.Sprintf(" The AI will take the role of %s unless they check in.", mootRoleDisplay(p.Role))
+		} else {
+			body += " The moot will be cancelled unless they check in."
+		}
+	}
+
+	organiser := s.Participant(s.CreatedBy)
+	app.notifyMoot(s, []*models.MootParticipant{organiser}, title, body, nil)
+}
+
+// notifyMootCancelled tells the participants other than the organiser that
+// a moot is off, cancelling it in their calendars
+func (app *application) notifyMootCancelled(s *models.MootSession, reason string) {
+	app.notifyMoot(s, mootOthers(s, s.CreatedBy),
+		fmt.Sprintf("Your moot on %s has been cancelled", mootTime(s)),
+		mootTitle(s)+". "+reason,
+		app.mootAttachment(s, ical.MethodCancel))
+}
+
+// remindMoot reminds the participants who haven't declined that a moot is
+// about to start
+func (app *application) remindMoot(s *models.MootSession) {
+	var coming []*models.MootParticipant
+	for _, p := range s.Participants {
+		if p.RSVP != models.RSVPNo {
+			coming = append(coming, p)
+		}
+	}
+
+	app.notifyMoot(s, coming,
+		fmt.Sprintf("Your moot starts at %s", mootTime(s)),
+		fmt.Sprintf("%s. Check in from %d minutes before the start; anyone who hasn't checked in %d minutes after it misses the moot.",
+			mootTitle(s), int(mootCheckInOpens.Minutes()), int(mootCheckInGrace.Minutes())),
+		nil)
+}
+
+// startMoot starts a scheduled moot and tells the participants what happened
+// to anyone who didn't check in
+func (app *application) startMoot(s *models.MootSession) error {
+	status, absent, err := app.models.MootSessions.Start(s.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(absent) == 0 {
+		return nil
+	}
+
+	var missing, present []*models.MootParticipant
+	var names []string
+	for _, p := range s.Participants {
+		if slices.Contains(absent, p.UserID) {
+			missing = append(missing, p)
+			names = append(names, p.Name)
+		} else {
+			present = append(present, p)
+		}
+	}
+	noShows := strings.Join(names, " and ")
+
+	if status == models.MootCancelled {
+		s.Status = models.MootCancelled
+		s.Sequence++
+
+		app.notifyMoot(s, s.Participants,
+			fmt.Sprintf("Your moot on %s has been cancelled", mootTime(s)),
+			fmt.Sprintf("%s. %s didn't check in in time.", mootTitle(s), noShows),
+			app.mootAttachment(s, ical.MethodCancel))
+		return nil
+	}
+
+	app.notifyMoot(s, present,
+		"Your moot has started with the AI standing in",
+		fmt.Sprintf("%s didn't check in in time, so the AI has taken their place.", noShows),
+		nil)
+
+	app.notifyMoot(s, missing,
+		"You missed your moot",
+		fmt.Sprintf("%s started without you, with the AI taking your place.", mootTitle(s)),
+		nil)
+
+	return nil
+}
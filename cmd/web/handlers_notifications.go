@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"lawbook/internal/models"
+	"lawbook/internal/notify"
+)
+
+// notificationPageSize is the number of notifications listed per page
+const notificationPageSize = 25
+
+// publish delivers an event to a user. Notifications are never the point of
+// the request that raises them, so failures are only logged.
+func (app *application) publish(userID int, e notify.Event) {
+	err := app.notifier.Publish(userID, e)
+	if err != nil {
+		app.errorLog.Printf("publishing %s notification to user %d: %s", e.Type, userID, err)
+	}
+}
+
+// ==================== NOTIFICATION CENTRE ====================
+
+func (app *application) notifications(w http.ResponseWriter, req *http.Request) {
+	pageNumber, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	page := newPagination(pageNumber, notificationPageSize, req.URL.Query())
+
+	notifications, total, err := app.models.Notifications.List(app.authenticatedUserID(req), page.PageSize, page.Offset())
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	page.Total = total
+
+	data := app.newTemplateData(req)
+	data.Notifications = notifications
+	data.Pagination = page
+	app.renderer(w, req, "notifications.tmpl.html", http.StatusOK, data)
+}
+
+// notificationOpen marks a notification as read and follows its link
+func (app *application) notificationOpen(w http.ResponseWriter, req *http.Request) {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+
+	notification, err := app.models.Notifications.Get(id, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	err = app.models.Notifications.MarkRead(notification.ID, userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	target := notification.URL
+	if target == "" {
+		target = "/notifications"
+	}
+	http.Redirect(w, req, target, http.StatusSeeOther)
+}
+
+func (app *application) notificationReadPost(w http.ResponseWriter, req *http.Request) {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	err = app.models.Notifications.MarkRead(id, app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	http.Redirect(w, req, "/notifications", http.StatusSeeOther)
+}
+
+func (app *application) notificationsReadAllPost(w http.ResponseWriter, req *http.Request) {
+	err := app.models.Notifications.MarkAllRead(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "All notifications marked as read.")
+	http.Redirect(w, req, "/notifications", http.StatusSeeOther)
+}
+
+// ==================== NOTIFICATION PREFERENCES ====================
+
+type notificationPreferencesForm struct {
+	InApp  []models.NotificationType `form:"in_app"`
+	Email  []models.NotificationType `form:"email"`
+	Digest bool                      `form:"digest"`
+}
+
+func (app *application) notificationPreferences(w http.ResponseWriter, req *http.Request) {
+	data := app.newTemplateData(req)
+
+	types := models.NotificationTypesFor(data.User.Role)
+	prefs, err := app.models.Notifications.Preferences(data.User.ID, types)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	digest, err := app.models.Digests.Get(data.User.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data.NotificationTypes = types
+	data.NotificationPreferences = prefs
+	data.DigestSettings = digest
+	data.DigestSearchURL = candidateSearchURL(digest.CandidateFilter)
+	app.renderer(w, req, "notification-preferences.tmpl.html", http.StatusOK, data)
+}
+
+func (app *application) notificationPreferencesPost(w http.ResponseWriter, req *http.Request) {
+	var form notificationPreferencesForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	user, err := app.models.Users.Get(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	// Unticked boxes aren't submitted, so every type the user can receive is
+	// saved, not just the ones in the form
+	var prefs []models.NotificationPreference
+	for _, info := range models.NotificationTypesFor(user.Role) {
+		prefs = append(prefs, models.NotificationPreference{
+			Type:  info.Type,
+			InApp: slices.Contains(form.InApp, info.Type),
+			Email: slices.Contains(form.Email, info.Type),
+		})
+	}
+
+	err = app.models.Notifications.SetPreferences(user.ID, prefs)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	err = app.models.Digests.SetSubscribed(user.ID, form.Digest)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Your notification preferences have been saved.")
+	http.Redirect(w, req, "/user/account/notifications", http.StatusSeeOther)
+}
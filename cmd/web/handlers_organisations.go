@@ -0,0 +1,624 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"lawbook/internal/mailer"
+	"lawbook/internal/models"
+	"lawbook/internal/notify"
+	"lawbook/internal/validator"
+)
+
+// domainRX matches a bare email domain such as "example.co.in"
+var domainRX = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)*\.[a-z]{2,}$`)
+
+// freeMailDomains can't be claimed by an organisation, since anyone can have
+// an address there
+var freeMailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"yahoo.com":      true,
+	"yahoo.co.in":    true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"icloud.com":     true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"rediffmail.com": true,
+	"zoho.com":       true,
+}
+
+// ==================== RECRUITER: ORGANISATIONS ====================
+
+type organisationForm struct {
+	Name                string         `form:"name"`
+	Email               string         `form:"email"`
+	Role                models.OrgRole `form:"role"`
+	Domain              string         `form:"domain"`
+	validator.Validator `form:"-"`
+}
+
+// organisationMembership returns the recruiter's membership, or nil if they
+// don't belong to an organisation. A recruiter with a verified email address
+// at an organisation's verified domain is added to it first, unless they have
+// left or been removed from it before.
+func (app *application) organisationMembership(user *models.User) (*models.Membership, error) {
+	membership, err := app.models.Organisations.ForUser(user.ID)
+	if err == nil {
+		return membership, nil
+	}
+	if !errors.Is(err, models.ErrNoRecord) {
+		return nil, err
+	}
+
+	if !user.EmailVerified {
+		return nil, nil
+	}
+
+	_, err = app.models.Organisations.AutoJoin(user.ID, user.Email)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) || errors.Is(err, models.ErrAlreadyMember) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	membership, err = app.models.Organisations.ForUser(user.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return membership, nil
+}
+
+// recruiterOrganisationManager loads the recruiter's membership. It writes
+// the response and returns nil if they don't belong to an organisation or
+// aren't allowed to manage it.
+func (app *application) recruiterOrganisationManager(w http.ResponseWriter, req *http.Request) *models.Membership {
+	membership, err := app.models.Organisations.ForUser(app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	if !membership.CanManage() {
+		app.sessionManager.Put(req.Context(), "flash", "Only the owner and admins can manage your organisation.")
+		http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+		return nil
+	}
+
+	return membership
+}
+
+func (app *application) recruiterOrganisation(w http.ResponseWriter, req *http.Request) {
+	app.renderOrganisation(w, req, organisationForm{}, http.StatusOK)
+}
+
+func (app *application) renderOrganisation(w http.ResponseWriter, req *http.Request, form organisationForm, status int) {
+	data := app.newTemplateData(req)
+
+	membership, err := app.organisationMembership(data.User)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	if membership == nil {
+		// Invites are only shown once the recruiter has proved they own the
+		// address they were sent to
+		if data.User.EmailVerified {
+			data.OrganisationInvites, err = app.models.Organisations.InvitesFor(data.User.Email)
+			if err != nil {
+				app.serverError(w, err)
+				return
+			}
+		}
+	} else {
+		data.OrganisationMembers, err = app.models.Organisations.Members(membership.Organisation.ID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+
+		if membership.CanManage() {
+			data.OrganisationInvites, err = app.models.Organisations.Invites(membership.Organisation.ID)
+			if err != nil {
+				app.serverError(w, err)
+				return
+			}
+		}
+
+		if form.Domain == "" {
+			form.Domain = membership.Organisation.EmailDomain
+		}
+	}
+
+	data.Form = form
+	data.Membership = membership
+	data.InviteRoles = models.InviteRoles
+	app.renderer(w, req, "organisation.tmpl.html", status, data)
+}
+
+func (app *application) recruiterOrganisationCreatePost(w http.ResponseWriter, req *http.Request) {
+	var form organisationForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+	form.CheckField(validator.NotBlank(form.Name), "name", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.Name, 255), "name", "This field cannot be more than 255 characters long")
+
+	if !form.Valid() {
+		app.renderOrganisation(w, req, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	_, err = app.models.Organisations.Create(form.Name, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrAlreadyMember) {
+			app.sessionManager.Put(req.Context(), "flash", "You already belong to an organisation.")
+			http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("%s has been created. Invite your colleagues to join.", form.Name))
+	http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+}
+
+func (app *application) recruiterOrganisationDomainPost(w http.ResponseWriter, req *http.Request) {
+	membership := app.recruiterOrganisationManager(w, req)
+	if membership == nil {
+		return
+	}
+
+	var form organisationForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(form.Domain)), "@")
+	if form.Domain != "" {
+		form.CheckField(validator.MaxChars(form.Domain, 255), "domain", "This field cannot be more than 255 characters long")
+		form.CheckField(validator.Matches(form.Domain, domainRX), "domain", "This field must be a domain such as example.com")
+		form.CheckField(!freeMailDomains[form.Domain], "domain", "Free email providers can't be claimed by an organisation")
+	}
+
+	if form.Domain == membership.Organisation.EmailDomain {
+		http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+		return
+	}
+
+	if form.Valid() {
+		err = app.models.Organisations.SetDomain(membership.Organisation.ID, form.Domain)
+		if err != nil {
+			if !errors.Is(err, models.ErrDuplicateDomain) {
+				app.serverError(w, err)
+				return
+			}
+			form.AddFieldErrors("domain", "Another organisation has already claimed this domain")
+		}
+	}
+
+	if !form.Valid() {
+		app.renderOrganisation(w, req, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	flash := "Your organisation's email domain has been removed."
+	if form.Domain != "" {
+		flash = fmt.Sprintf("%s will be checked by an administrator. Once it is verified, recruiters with a verified address there join automatically.", form.Domain)
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", flash)
+	http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+}
+
+func (app *application) recruiterOrganisationInvitePost(w http.ResponseWriter, req *http.Request) {
+	membership := app.recruiterOrganisationManager(w, req)
+	if membership == nil {
+		return
+	}
+
+	var form organisationForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Email = strings.ToLower(strings.TrimSpace(form.Email))
+	form.CheckField(validator.NotBlank(form.Email), "email", "This field cannot be blank")
+	form.CheckField(validator.Matches(form.Email, validator.EmailRX), "email", "This field must be a valid email address")
+	form.CheckField(models.ValidInviteRole(form.Role), "role", "Please choose a valid role")
+
+	// Only the owner can hand out admin rights
+	if form.Role == models.OrgAdmin && !membership.IsOwner() {
+		form.AddFieldErrors("role", "Only the owner can invite admins")
+	}
+
+	if form.Valid() {
+		members, err := app.models.Organisations.Members(membership.Organisation.ID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		for _, m := range members {
+			if strings.EqualFold(m.Email, form.Email) {
+				form.AddFieldErrors("email", fmt.Sprintf("%s is already a member", m.Name))
+			}
+		}
+	}
+
+	if !form.Valid() {
+		app.renderOrganisation(w, req, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	userID := app.authenticatedUserID(req)
+
+	err = app.models.Organisations.Invite(membership.Organisation.ID, form.Email, form.Role, userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	inviter, err := app.models.Users.Get(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := map[string]any{
+		"Inviter":      inviter.Name,
+		"Organisation": membership.Organisation.Name,
+		"URL":          app.config.baseURL + "/recruiter/organisation",
+	}
+
+	// Recruiters who already have an account with the address verified hear
+	// about the invite as they have chosen to; anyone else is emailed
+	invitee, err := app.models.Users.GetByEmail(form.Email)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, err)
+		return
+	}
+
+	if invitee != nil && invitee.Role == models.RoleRecruiter && invitee.EmailVerified {
+		app.publish(invitee.ID, notify.Event{
+			Type:     models.NotifyInvite,
+			Title:    fmt.Sprintf("%s has invited you to join %s", inviter.Name, membership.Organisation.Name),
+			URL:      "/recruiter/organisation",
+			Template: "organisation-invite.tmpl",
+			Data:     data,
+		})
+	} else {
+		msg, err := mailer.Render(emailTemplateDir, "organisation-invite.tmpl", form.Email, data)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+
+		// The invite is saved and listed on the organisation page, so a mail
+		// failure shouldn't undo it
+		err = app.mailer.Send(msg)
+		if err != nil {
+			app.errorLog.Printf("sending organisation invite to %s: %s", form.Email, err)
+		}
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("An invite has been sent to %s.", form.Email))
+	http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+}
+
+func (app *application) recruiterOrganisationInviteRevokePost(w http.ResponseWriter, req *http.Request) {
+	membership := app.recruiterOrganisationManager(w, req)
+	if membership == nil {
+		return
+	}
+
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	err = app.models.Organisations.RevokeInvite(id, membership.Organisation.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "The invite has been withdrawn.")
+	http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+}
+
+func (app *application) recruiterOrganisationInviteAcceptPost(w http.ResponseWriter, req *http.Request) {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	user, err := app.models.Users.Get(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	if !user.EmailVerified {
+		app.notFound(w)
+		return
+	}
+
+	organisation, err := app.models.Organisations.AcceptInvite(id, user.ID, user.Email)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNoRecord):
+			app.notFound(w)
+		case errors.Is(err, models.ErrAlreadyMember):
+			app.sessionManager.Put(req.Context(), "flash", "Leave your current organisation before joining another.")
+			http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+		default:
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("You have joined %s.", organisation.Name))
+	http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+}
+
+func (app *application) recruiterOrganisationInviteDeclinePost(w http.ResponseWriter, req *http.Request) {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	user, err := app.models.Users.Get(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	if !user.EmailVerified {
+		app.notFound(w)
+		return
+	}
+
+	err = app.models.Organisations.DeclineInvite(id, user.Email)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "The invite has been declined.")
+	http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+}
+
+// recruiterOrganisationMember loads the member named by the ":id" parameter
+// from the manager's organisation. It writes the error response and returns
+// nil if there is no such member.
+func (app *application) recruiterOrganisationMember(w http.ResponseWriter, req *http.Request, membership *models.Membership) *models.OrganisationMember {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	member, err := app.models.Organisations.Member(membership.Organisation.ID, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	return member
+}
+
+func (app *application) recruiterOrganisationRolePost(w http.ResponseWriter, req *http.Request) {
+	membership := app.recruiterOrganisationManager(w, req)
+	if membership == nil {
+		return
+	}
+
+	if !membership.IsOwner() {
+		app.sessionManager.Put(req.Context(), "flash", "Only the owner can change roles.")
+		http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+		return
+	}
+
+	member := app.recruiterOrganisationMember(w, req, membership)
+	if member == nil {
+		return
+	}
+
+	var form organisationForm
+	err := app.decodePostForm(req, &form)
+	if err != nil || !models.ValidInviteRole(form.Role) || member.Role == models.OrgOwner {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = app.models.Organisations.SetRole(membership.Organisation.ID, member.UserID, form.Role)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("%s's role has been changed to %s.", member.Name, form.Role))
+	http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+}
+
+func (app *application) recruiterOrganisationRemovePost(w http.ResponseWriter, req *http.Request) {
+	membership := app.recruiterOrganisationManager(w, req)
+	if membership == nil {
+		return
+	}
+
+	member := app.recruiterOrganisationMember(w, req, membership)
+	if member == nil {
+		return
+	}
+
+	// The owner can remove anyone else; admins can only remove members
+	if member.Role == models.OrgOwner || (member.Role == models.OrgAdmin && !membership.IsOwner()) {
+		app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("You can't remove %s.", member.Name))
+		http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+		return
+	}
+
+	err := app.models.Organisations.RemoveMember(membership.Organisation.ID, member.UserID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("%s has been removed from %s.", member.Name, membership.Organisation.Name))
+	http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+}
+
+func (app *application) recruiterOrganisationLeavePost(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	membership, err := app.models.Organisations.ForUser(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	if membership.IsOwner() {
+		app.sessionManager.Put(req.Context(), "flash", "As the owner, you can delete the organisation but not leave it.")
+		http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+		return
+	}
+
+	err = app.models.Organisations.RemoveMember(membership.Organisation.ID, userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("You have left %s.", membership.Organisation.Name))
+	http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+}
+
+func (app *application) recruiterOrganisationDeletePost(w http.ResponseWriter, req *http.Request) {
+	membership := app.recruiterOrganisationManager(w, req)
+	if membership == nil {
+		return
+	}
+
+	if !membership.IsOwner() {
+		app.sessionManager.Put(req.Context(), "flash", "Only the owner can delete the organisation.")
+		http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+		return
+	}
+
+	err := app.models.Organisations.Delete(membership.Organisation.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("%s has been deleted.", membership.Organisation.Name))
+	http.Redirect(w, req, "/recruiter/organisation", http.StatusSeeOther)
+}
+
+// ==================== ADMIN: ORGANISATIONS ====================
+
+func (app *application) adminOrganisations(w http.ResponseWriter, req *http.Request) {
+	pageNumber, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	page := newPagination(pageNumber, adminPageSize, req.URL.Query())
+
+	organisations, total, err := app.models.Organisations.List(page.PageSize, page.Offset())
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	page.Total = total
+
+	data := app.newTemplateData(req)
+	data.Organisations = organisations
+	data.Pagination = page
+	app.renderer(w, req, "admin-organisations.tmpl.html", http.StatusOK, data)
+}
+
+func (app *application) adminOrganisationDomainPost(w http.ResponseWriter, req *http.Request) {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	err = req.ParseForm()
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+	verified := req.PostForm.Get("verified") == "true"
+
+	organisation, err := app.models.Organisations.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	if organisation.EmailDomain == "" {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = app.models.Organisations.VerifyDomain(organisation.ID, verified)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	action := models.AuditUnverifyDomain
+	flash := fmt.Sprintf("%s is no longer verified for %s.", organisation.EmailDomain, organisation.Name)
+	if verified {
+		action = models.AuditVerifyDomain
+		flash = fmt.Sprintf("%s is now verified for %s.", organisation.EmailDomain, organisation.Name)
+	}
+
+	err = app.models.AuditLog.Insert(app.authenticatedUserID(req), action, 0,
+		fmt.Sprintf("%s (%s)", organisation.EmailDomain, organisation.Name))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", flash)
+	http.Redirect(w, req, "/admin/organisations", http.StatusSeeOther)
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"lawbook/internal/models"
+	"lawbook/internal/validator"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// slugRX matches portfolio slugs: lowercase words separated by single hyphens
+var slugRX = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// slugify turns a name into a portfolio slug suggestion
+func slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+
+	for _, r := range strings.ToLower(name) {
+		if b.Len() >= 50 {
+			break
+		}
+
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			hyphen = false
+		case !hyphen && b.Len() > 0:
+			b.WriteByte('-')
+			hyphen = true
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) < 3 {
+		slug = "member"
+	}
+	return slug
+}
+
+// ==================== PORTFOLIO: OWNER ====================
+
+type portfolioForm struct {
+	Slug     string `form:"slug"`
+	Headline string `form:"headline"`
+	IsPublic bool   `form:"is_public"`
+	NoIndex  bool   `form:"noindex"`
+
+	ShowUniversity      bool `form:"show_university"`
+	ShowYearOfStudy     bool `form:"show_year_of_study"`
+	ShowSpecialization  bool `form:"show_specialization"`
+	ShowExperience      bool `form:"show_experience"`
+	ShowFirm            bool `form:"show_firm"`
+	ShowBio             bool `form:"show_bio"`
+	ShowBarRegistration bool `form:"show_bar_registration"`
+	ShowVerified        bool `form:"show_verified"`
+	ShowHighlights      bool `form:"show_highlights"`
+	ShowAchievements    bool `form:"show_achievements"`
+
+	EvaluationIDs []int `form:"evaluations"`
+
+	validator.Validator `form:"-"`
+}
+
+func newPortfolioForm(p *models.Portfolio) portfolioForm {
+	return portfolioForm{
+		Slug:                p.Slug,
+		Headline:            p.Headline,
+		IsPublic:            p.IsPublic,
+		NoIndex:             p.NoIndex,
+		ShowUniversity:      p.ShowUniversity,
+		ShowYearOfStudy:     p.ShowYearOfStudy,
+		ShowSpecialization:  p.ShowSpecialization,
+		ShowExperience:      p.ShowExperience,
+		ShowFirm:            p.ShowFirm,
+		ShowBio:             p.ShowBio,
+		ShowBarRegistration: p.ShowBarRegistration,
+		ShowVerified:        p.ShowVerified,
+		ShowHighlights:      p.ShowHighlights,
+		ShowAchievements:    p.ShowAchievements,
+		EvaluationIDs:       p.EvaluationIDs,
+	}
+}
+
+// Features reports whether an evaluation is ticked on the form
+func (f portfolioForm) Features(evaluationID int) bool {
+	for _, id := range f.EvaluationIDs {
+		if id == evaluationID {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *application) portfolioEdit(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	portfolio, err := app.models.Portfolios.Get(userID)
+	if err != nil {
+		if !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, err)
+			return
+		}
+
+		user, err := app.models.Users.Get(userID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+
+		// Suggest a slug from the user's name, made unique with their ID if needed
+		slug := slugify(user.Name)
+		taken, err := app.models.Portfolios.SlugTaken(slug, userID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		if taken {
+			slug = fmt.Sprintf("%s-%d", slug, userID)
+		}
+
+		portfolio = models.NewPortfolio(userID, slug)
+	}
+
+	app.renderPortfolioEdit(w, req, newPortfolioForm(portfolio), http.StatusOK)
+}
+
+func (app *application) renderPortfolioEdit(w http.ResponseWriter, req *http.Request, form portfolioForm, status int) {
+	evaluations, err := app.models.Evaluations.ListForUser(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.Evaluations = evaluations
+	data.PortfolioURL = app.config.baseURL + "/p/" + form.Slug
+	app.renderer(w, req, "portfolio-edit.tmpl.html", status, data)
+}
+
+func (app *application) portfolioEditPost(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	var form portfolioForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Slug = strings.ToLower(strings.TrimSpace(form.Slug))
+	form.Headline = strings.TrimSpace(form.Headline)
+
+	form.CheckField(validator.MinChars(form.Slug, 3), "slug", "This field must be at least 3 characters long")
+	form.CheckField(validator.MaxChars(form.Slug, 60), "slug", "This field cannot be more than 60 characters long")
+	form.CheckField(validator.Matches(form.Slug, slugRX), "slug", "Use lowercase letters, numbers and single hyphens only")
+	form.CheckField(validator.MaxChars(form.Headline, 160), "headline", "This field cannot be more than 160 characters long")
+
+	if form.FieldErrors["slug"] == "" {
+		taken, err := app.models.Portfolios.SlugTaken(form.Slug, userID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		form.CheckField(!taken, "slug", "That address is already taken")
+	}
+
+	if !form.Valid() {
+		app.renderPortfolioEdit(w, req, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = app.models.Portfolios.Upsert(&models.Portfolio{
+		UserID:              userID,
+		Slug:                form.Slug,
+		Headline:            form.Headline,
+		IsPublic:            form.IsPublic,
+		NoIndex:             form.NoIndex,
+		ShowUniversity:      form.ShowUniversity,
+		ShowYearOfStudy:     form.ShowYearOfStudy,
+		ShowSpecialization:  form.ShowSpecialization,
+		ShowExperience:      form.ShowExperience,
+		ShowFirm:            form.ShowFirm,
+		ShowBio:             form.ShowBio,
+		ShowBarRegistration: form.ShowBarRegistration,
+		ShowVerified:        form.ShowVerified,
+		ShowHighlights:      form.ShowHighlights,
+		ShowAchievements:    form.ShowAchievements,
+		EvaluationIDs:       form.EvaluationIDs,
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicateSlug) {
+			form.AddFieldErrors("slug", "That address is already taken")
+			app.renderPortfolioEdit(w, req, form, http.StatusUnprocessableEntity)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	flash := "Your portfolio has been saved. It is private until you publish it."
+	if form.IsPublic {
+		flash = "Your portfolio has been saved and is live."
+	}
+	app.sessionManager.Put(req.Context(), "flash", flash)
+	http.Redirect(w, req, "/user/account/portfolio", http.StatusSeeOther)
+}
+
+// ==================== PORTFOLIO: PUBLIC PAGE ====================
+
+func (app *application) portfolioView(w http.ResponseWriter, req *http.Request) {
+	slug := httprouter.ParamsFromContext(req.Context()).ByName("slug")
+
+	portfolio, err := app.models.Portfolios.GetBySlug(slug)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	owner, err := app.models.Users.Get(portfolio.UserID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	// Owners can preview their page before publishing it
+	isOwner := app.authenticatedUserID(req) == owner.ID
+	if !isOwner && (!portfolio.IsPublic || !owner.IsActive) {
+		app.notFound(w)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Portfolio = portfolio
+	data.PortfolioOwner = owner
+	data.PortfolioURL = app.config.baseURL + "/p/" + portfolio.Slug
+
+	var description []string
+	if portfolio.Headline != "" {
+		description = append(description, portfolio.Headline)
+	}
+
+	switch owner.Role {
+	case models.RoleStudent:
+		p, err := app.models.StudentProfiles.Get(owner.ID)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, err)
+			return
+		}
+		data.StudentProfile = p
+		if p != nil && portfolio.ShowUniversity && p.University != "" {
+			description = append(description, "Law student at "+p.University)
+		}
+	case models.RoleLawyer:
+		p, err := app.models.LawyerProfiles.Get(owner.ID)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, err)
+			return
+		}
+		data.LawyerProfile = p
+		if p != nil && portfolio.ShowFirm && p.FirmName != "" {
+			description = append(description, "Lawyer at "+p.FirmName)
+		}
+
+		if portfolio.ShowVerified {
+			data.LawyerVerification, err = app.currentVerification(owner)
+			if err != nil {
+				app.serverError(w, err)
+				return
+			}
+		}
+
+		data.Mentor, data.MentoringReviews, err = app.mentorRecord(owner.ID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	default:
+		app.notFound(w)
+		return
+	}
+
+	data.Evaluations, err = app.models.Evaluations.Featured(owner.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	if portfolio.ShowHighlights {
+		data.Highlights, err = app.models.Evaluations.Highlights(owner.ID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+	}
+
+	if portfolio.ShowAchievements {
+		earned, err := app.models.Achievements.ForUser(owner.ID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		data.Badges = earnedBadges(earned)
+	}
+
+	if len(description) == 0 {
+		description = append(description, fmt.Sprintf("%s's moot court portfolio on Lawbook", owner.Name))
+	}
+	data.MetaDescription = strings.Join(description, " · ")
+
+	if portfolio.NoIndex || !portfolio.IsPublic {
+		w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	}
+
+	app.renderer(w, req, "portfolio.tmpl.html", http.StatusOK, data)
+}
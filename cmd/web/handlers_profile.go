@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"lawbook/internal/models"
+	"lawbook/internal/stats"
+	"lawbook/internal/validator"
+)
+
+// ==================== PROFILES ====================
+
+// profileForm holds the fields of all three role-specific profiles. Only the
+// fields for the signed-in user's role are shown and validated.
+type profileForm struct {
+	// Students and lawyers
+	Specialization string `form:"specialization"`
+	Bio            string `form:"bio"`
+
+	// Students
+	University          string `form:"university"`
+	YearOfStudy         int    `form:"year_of_study"`
+	MootCourtExperience string `form:"moot_court_experience"`
+
+	// Lawyers
+	BarRegistrationNumber string `form:"bar_registration_number"`
+	YearsOfExperience     int    `form:"years_of_experience"`
+	FirmName              string `form:"firm_name"`
+
+	// Recruiters
+	CompanyName    string `form:"company_name"`
+	Position       string `form:"position"`
+	CompanyWebsite string `form:"company_website"`
+	AnonymousViews bool   `form:"anonymous_views"`
+
+	validator.Validator `form:"-"`
+}
+
+// profileCompleteness returns how complete a user's role-specific profile
+// is, as a percentage. Users without a profile are 0% complete; roles that
+// have no profile are always 100%.
+func (app *application) profileCompleteness(user *models.User) (int, error) {
+	var completeness int
+	var err error
+
+	switch user.Role {
+	case models.RoleStudent:
+		var p *models.StudentProfile
+		p, err = app.models.StudentProfiles.Get(user.ID)
+		if err == nil {
+			completeness = p.Completeness()
+		}
+	case models.RoleLawyer:
+		var p *models.LawyerProfile
+		p, err = app.models.LawyerProfiles.Get(user.ID)
+		if err == nil {
+			completeness = p.Completeness()
+		}
+	case models.RoleRecruiter:
+		var p *models.RecruiterProfile
+		p, err = app.models.RecruiterProfiles.Get(user.ID)
+		if err == nil {
+			completeness = p.Completeness()
+		}
+	default:
+		return 100, nil
+	}
+
+	if errors.Is(err, models.ErrNoRecord) {
+		return 0, nil
+	}
+	return completeness, err
+}
+
+func (app *application) profileEdit(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	user, err := app.models.Users.Get(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	var form profileForm
+
+	switch user.Role {
+	case models.RoleStudent:
+		p, err := app.models.StudentProfiles.Get(userID)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, err)
+			return
+		}
+		if p != nil {
+			form.University = p.University
+			form.YearOfStudy = p.YearOfStudy
+			form.Specialization = p.Specialization
+			form.MootCourtExperience = p.MootCourtExperience
+		}
+	case models.RoleLawyer:
+		p, err := app.models.LawyerProfiles.Get(userID)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, err)
+			return
+		}
+		if p != nil {
+			form.BarRegistrationNumber = p.BarRegistrationNumber
+			form.YearsOfExperience = p.YearsOfExperience
+			form.Specialization = p.Specialization
+			form.FirmName = p.FirmName
+			form.Bio = p.Bio
+		}
+	case models.RoleRecruiter:
+		p, err := app.models.RecruiterProfiles.Get(userID)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			app.serverError(w, err)
+			return
+		}
+		if p != nil {
+			form.CompanyName = p.CompanyName
+			form.Position = p.Position
+			form.CompanyWebsite = p.CompanyWebsite
+			form.Bio = p.Bio
+			form.AnonymousViews = p.AnonymousViews
+		}
+	default:
+		app.notFound(w)
+		return
+	}
+
+	verification, err := app.currentVerification(user)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.LawyerVerification = verification
+	app.renderer(w, req, "profile.tmpl.html", http.StatusOK, data)
+}
+
+func (app *application) profileEditPost(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	user, err := app.models.Users.Get(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	var form profileForm
+	err = app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	verification, err := app.currentVerification(user)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	form.Specialization = strings.TrimSpace(form.Specialization)
+	form.CheckField(validator.MaxChars(form.Specialization, 255), "specialization", "This field cannot be more than 255 characters long")
+	form.CheckField(validator.MaxChars(form.Bio, 2000), "bio", "This field cannot be more than 2000 characters long")
+
+	switch user.Role {
+	case models.RoleStudent:
+		form.University = strings.TrimSpace(form.University)
+		form.CheckField(validator.NotBlank(form.University), "university", "This field cannot be blank")
+		form.CheckField(validator.MaxChars(form.University, 255), "university", "This field cannot be more than 255 characters long")
+		form.CheckField(validator.Between(form.YearOfStudy, 1, 5), "year_of_study", "Please select your year of study")
+		form.CheckField(validator.MaxChars(form.MootCourtExperience, 2000), "moot_court_experience", "This field cannot be more than 2000 characters long")
+	case models.RoleLawyer:
+		form.BarRegistrationNumber = strings.TrimSpace(form.BarRegistrationNumber)
+		if verification != nil && verification.Approved() {
+			// Only a new verification can change a verified enrolment number
+			form.BarRegistrationNumber = verification.EnrolmentNumber
+		}
+		form.FirmName = strings.TrimSpace(form.FirmName)
+		form.CheckField(validator.MaxChars(form.BarRegistrationNumber, 100), "bar_registration_number", "This field cannot be more than 100 characters long")
+		form.CheckField(validator.Between(form.YearsOfExperience, 0, 70), "years_of_experience", "Please enter a number between 0 and 70")
+		form.CheckField(validator.MaxChars(form.FirmName, 255), "firm_name", "This field cannot be more than 255 characters long")
+	case models.RoleRecruiter:
+		form.CompanyName = strings.TrimSpace(form.CompanyName)
+		form.Position = strings.TrimSpace(form.Position)
+		form.CompanyWebsite = strings.TrimSpace(form.CompanyWebsite)
+		form.CheckField(validator.NotBlank(form.CompanyName), "company_name", "This field cannot be blank")
+		form.CheckField(validator.MaxChars(form.CompanyName, 255), "company_name", "This field cannot be more than 255 characters long")
+		form.CheckField(validator.MaxChars(form.Position, 255), "position", "This field cannot be more than 255 characters long")
+		if form.CompanyWebsite != "" {
+			form.CheckField(validator.ValidURL(form.CompanyWebsite), "company_website", "This field must be a valid http(s) URL")
+		}
+	default:
+		app.notFound(w)
+		return
+	}
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.Form = form
+		data.LawyerVerification = verification
+		app.renderer(w, req, "profile.tmpl.html", http.StatusUnprocessableEntity, data)
+		return
+	}
+
+	switch user.Role {
+	case models.RoleStudent:
+		err = app.models.StudentProfiles.Upsert(&models.StudentProfile{
+			UserID:              userID,
+			University:          form.University,
+			YearOfStudy:         form.YearOfStudy,
+			Specialization:      form.Specialization,
+			MootCourtExperience: form.MootCourtExperience,
+		})
+	case models.RoleLawyer:
+		err = app.models.LawyerProfiles.Upsert(&models.LawyerProfile{
+			UserID:                userID,
+			BarRegistrationNumber: form.BarRegistrationNumber,
+			YearsOfExperience:     form.YearsOfExperience,
+			Specialization:        form.Specialization,
+			FirmName:              form.FirmName,
+			Bio:                   form.Bio,
+		})
+	case models.RoleRecruiter:
+		err = app.models.RecruiterProfiles.Upsert(&models.RecruiterProfile{
+			UserID:         userID,
+			CompanyName:    form.CompanyName,
+			Position:       form.Position,
+			CompanyWebsite: form.CompanyWebsite,
+			Bio:            form.Bio,
+			AnonymousViews: form.AnonymousViews,
+		})
+	}
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Your profile has been saved.")
+	http.Redirect(w, req, "/user/account", http.StatusSeeOther)
+}
+
+// ==================== LAWYER: PROFILE VIEWS ====================
+
+const (
+	// profileViewDays is the window for the "viewed by" list, matching the
+	// dashboard count
+	profileViewDays = stats.ProfileViewDays
+	// profileViewWeeks is the number of weeks shown in the weekly breakdown
+	profileViewWeeks = 12
+)
+
+func (app *application) lawyerProfileViews(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+	since := time.Now().AddDate(0, 0, -profileViewDays)
+
+	weekly, err := app.models.ProfileViews.Weekly(userID, profileViewWeeks)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	viewedBy, err := app.models.ProfileViews.ViewedBy(userID, since, 50)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.WeeklyViews = weekly
+	data.ProfileViews = viewedBy
+	for _, week := range weekly {
+		data.PeakWeeklyViews = max(data.PeakWeeklyViews, week.Views)
+	}
+	for _, v := range viewedBy {
+		data.ProfileViewCount += v.Views
+	}
+
+	data.Consent, err = app.models.Consents.Get(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.renderer(w, req, "profile-views.tmpl.html", http.StatusOK, data)
+}
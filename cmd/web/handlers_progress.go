@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+
+	"lawbook/internal/stats"
+)
+
+// ==================== STUDENT: PROGRESS ====================
+
+// progressCharts holds the SVG charts drawn on the progress page
+type progressCharts struct {
+	Window       int
+	Overall      *lineChart
+	Skills       []*lineChart
+	CaseTypes    *barChart
+	Difficulties *barChart
+}
+
+func (app *application) studentProgress(w http.ResponseWriter, req *http.Request) {
+	evaluations, err := app.models.Evaluations.ListForUser(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	progress := stats.NewProgress(evaluations)
+
+	charts := &progressCharts{
+		Window:       stats.MovingAverageWindow,
+		Overall:      newLineChart(progress.Overall, progress.Evaluations),
+		CaseTypes:    newBarChart(progress.ByCaseType),
+		Difficulties: newBarChart(progress.ByDifficulty),
+	}
+	for _, s := range progress.Skills {
+		charts.Skills = append(charts.Skills, newLineChart(s, progress.Evaluations))
+	}
+
+	data := app.newTemplateData(req)
+	data.Progress = progress
+	data.ProgressCharts = charts
+	data.Skills = stats.Skills
+	app.renderer(w, req, "progress.tmpl.html", http.StatusOK, data)
+}
+
+// ==================== API: PROGRESS ====================
+
+// skillScoresJSON maps each skill, including "overall", to a score. Missing
+// scores are null.
+type skillScoresJSON map[stats.Skill]*float64
+
+type progressSessionJSON struct {
+	EvaluationID  int             `json:"evaluation_id"`
+	Date          string          `json:"date"`
+	CaseType      string          `json:"case_type,omitempty"`
+	Difficulty    string          `json:"difficulty"`
+	Scores        skillScoresJSON `json:"scores"`
+	MovingAverage skillScoresJSON `json:"moving_average"`
+}
+
+type progressGroupJSON struct {
+	Name     string          `json:"name"`
+	Sessions int             `json:"sessions"`
+	Averages skillScoresJSON `json:"averages"`
+}
+
+type progressJSON struct {
+	Sessions            int                   `json:"sessions"`
+	MovingAverageWindow int                   `json:"moving_average_window"`
+	Averages            skillScoresJSON       `json:"averages"`
+	Strongest           *stats.Skill          `json:"strongest"`
+	Weakest             *stats.Skill          `json:"weakest"`
+	History             []progressSessionJSON `json:"history"`
+	ByCaseType          []progressGroupJSON   `json:"by_case_type"`
+	ByDifficulty        []progressGroupJSON   `json:"by_difficulty"`
+}
+
+func newProgressGroupsJSON(groups []*stats.Group) []progressGroupJSON {
+	all := append([]stats.Skill{stats.SkillOverall}, stats.Skills...)
+
+	js := make([]progressGroupJSON, 0, len(groups))
+	for _, g := range groups {
+		group := progressGroupJSON{Name: g.Name, Sessions: g.Sessions, Averages: make(skillScoresJSON, len(all))}
+		for i, skill := range all {
+			group.Averages[skill] = nullFloat(g.Averages[i])
+		}
+		js = append(js, group)
+	}
+	return js
+}
+
+func (app *application) apiStudentProgress(w http.ResponseWriter, req *http.Request) {
+	evaluations, err := app.models.Evaluations.ListForUser(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	progress := stats.NewProgress(evaluations)
+	series := append([]*stats.Series{progress.Overall}, progress.Skills...)
+
+	js := progressJSON{
+		Sessions:            len(progress.Evaluations),
+		MovingAverageWindow: stats.MovingAverageWindow,
+		Averages:            make(skillScoresJSON, len(series)),
+		History:             make([]progressSessionJSON, 0, len(progress.Evaluations)),
+		ByCaseType:          newProgressGroupsJSON(progress.ByCaseType),
+		ByDifficulty:        newProgressGroupsJSON(progress.ByDifficulty),
+	}
+
+	if progress.Strongest != nil {
+		js.Strongest = &progress.Strongest.Skill
+		js.Weakest = &progress.Weakest.Skill
+	}
+
+	for _, s := range series {
+		js.Averages[s.Skill] = nullFloat(s.Average)
+	}
+
+	for i, e := range progress.Evaluations {
+		session := progressSessionJSON{
+			EvaluationID:  e.ID,
+			Date:          e.CreatedAt.Format("2006-01-02"),
+			CaseType:      e.CaseType,
+			Difficulty:    e.Difficulty,
+			Scores:        make(skillScoresJSON, len(series)),
+			MovingAverage: make(skillScoresJSON, len(series)),
+		}
+		for _, s := range series {
+			session.Scores[s.Skill] = nullFloat(s.Scores[i])
+			session.MovingAverage[s.Skill] = nullFloat(s.MovingAverage[i])
+		}
+		js.History = append(js.History, session)
+	}
+
+	app.writeJSON(w, http.StatusOK, js)
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"lawbook/internal/models"
+)
+
+// candidatePageSize is the number of candidates shown per page of search results
+const candidatePageSize = 20
+
+// ==================== RECRUITER: CANDIDATE SEARCH ====================
+
+type candidateSearchForm struct {
+	Role               models.UserRole `form:"role"`
+	Specialization     string          `form:"specialization"`
+	University         string          `form:"university"`
+	AreaOfLaw          string          `form:"area"`
+	MinExperience      int             `form:"min_experience"`
+	MaxExperience      int             `form:"max_experience"`
+	MinScore           float64         `form:"min_score"`
+	MaxScore           float64         `form:"max_score"`
+	MinLegalKnowledge  float64         `form:"min_legal_knowledge"`
+	MinArgumentation   float64         `form:"min_argumentation"`
+	MinPresentation    float64         `form:"min_presentation"`
+	MinResponseQuality float64         `form:"min_response_quality"`
+	VerifiedOnly       bool            `form:"verified"`
+	Sort               string          `form:"sort"`
+	Page               int             `form:"page"`
+}
+
+// filter converts the submitted search into a model filter, ignoring values
+// that can't narrow the results
+func (f candidateSearchForm) filter() models.CandidateFilter {
+	if f.Role != models.RoleStudent && f.Role != models.RoleLawyer {
+		f.Role = ""
+	}
+
+	return models.CandidateFilter{
+		Role:               f.Role,
+		Specialization:     strings.TrimSpace(f.Specialization),
+		University:         strings.TrimSpace(f.University),
+		AreaOfLaw:          f.AreaOfLaw,
+		MinExperience:      max(f.MinExperience, 0),
+		MaxExperience:      max(f.MaxExperience, 0),
+		MinScore:           max(f.MinScore, 0),
+		MaxScore:           max(f.MaxScore, 0),
+		MinLegalKnowledge:  max(f.MinLegalKnowledge, 0),
+		MinArgumentation:   max(f.MinArgumentation, 0),
+		MinPresentation:    max(f.MinPresentation, 0),
+		MinResponseQuality: max(f.MinResponseQuality, 0),
+		VerifiedOnly:       f.VerifiedOnly,
+		Sort:               f.Sort,
+	}
+}
+
+// maxSearchQueryLength matches the columns that store saved candidate
+// searches
+const maxSearchQueryLength = 1000
+
+// candidateSearchQuery checks that a query string submitted for saving is a
+// candidate search, and returns it without the page number
+func (app *application) candidateSearchQuery(raw string) (string, error) {
+	query, err := url.ParseQuery(raw)
+	if err != nil {
+		return "", err
+	}
+	query.Del("page")
+
+	var form candidateSearchForm
+	err = app.formDecoder.Decode(&form, query)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := query.Encode()
+	if len(encoded) > maxSearchQueryLength {
+		return "", errors.New("search query is too long")
+	}
+	return encoded, nil
+}
+
+// savedCandidateFilter converts a saved search query back into a filter
+func (app *application) savedCandidateFilter(query string) (models.CandidateFilter, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return models.CandidateFilter{}, err
+	}
+
+	var form candidateSearchForm
+	err = app.formDecoder.Decode(&form, values)
+	if err != nil {
+		return models.CandidateFilter{}, err
+	}
+
+	return form.filter(), nil
+}
+
+// candidateSearchURL links to the search page with a saved search's filters
+func candidateSearchURL(query string) string {
+	if query == "" {
+		return "/recruiter/candidates"
+	}
+	return "/recruiter/candidates?" + query
+}
+
+// searchCandidates runs a candidate search and returns one page of results
+func (app *application) searchCandidates(req *http.Request, form candidateSearchForm) ([]*models.Candidate, *pagination, error) {
+	page := newPagination(form.Page, candidatePageSize, req.URL.Query())
+
+	candidates, total, err := app.models.Candidates.Search(app.authenticatedUserID(req), form.filter(), page.PageSize, page.Offset())
+	if err != nil {
+		return nil, nil, err
+	}
+	page.Total = total
+
+	return candidates, page, nil
+}
+
+func (app *application) recruiterCandidates(w http.ResponseWriter, req *http.Request) {
+	var form candidateSearchForm
+	err := app.decodeQuery(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	candidates, page, err := app.searchCandidates(req, form)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	areas, err := app.models.Candidates.AreasOfLaw()
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	blind, err := app.anonymise(app.authenticatedUserID(req), candidates...)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.Candidates = candidates
+	data.BlindReview = blind
+	data.AreasOfLaw = areas
+	data.CandidateSorts = models.CandidateSorts
+	data.Pagination = page
+
+	// The current search, less the page number, can be saved
+	query := req.URL.Query()
+	query.Del("page")
+	data.SearchQuery = query.Encode()
+	data.SearchFrequencies = models.SearchFrequencies
+
+	app.renderer(w, req, "candidates.tmpl.html", http.StatusOK, data)
+}
+
+// recruiterCandidate loads the candidate named by the ":id" parameter,
+// anonymised if the recruiter is in blind review. It writes the error
+// response and returns nil if the candidate is not visible to the recruiter.
+func (app *application) recruiterCandidate(w http.ResponseWriter, req *http.Request) *models.Candidate {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	candidate, err := app.models.Candidates.Get(id, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	_, err = app.anonymise(app.authenticatedUserID(req), candidate)
+	if err != nil {
+		app.serverError(w, err)
+		return nil
+	}
+
+	return candidate
+}
+
+func (app *application) recruiterCandidateView(w http.ResponseWriter, req *http.Request) {
+	candidate := app.recruiterCandidate(w, req)
+	if candidate == nil {
+		return
+	}
+
+	// A failure to record the view shouldn't stop the recruiter seeing the profile
+	err := app.models.ProfileViews.Record(candidate.ID, app.authenticatedUserID(req))
+	if err != nil {
+		app.errorLog.Printf("recording profile view of user %d: %s", candidate.ID, err)
+	}
+
+	form, err := app.candidateNoteForm(app.authenticatedUserID(req), candidate.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.renderCandidate(w, req, candidate, form, contactForm{}, http.StatusOK)
+}
+
+// candidateNoteForm fills in the notes form with the recruiter's saved notes
+func (app *application) candidateNoteForm(recruiterID, candidateID int) (candidateNoteForm, error) {
+	note, err := app.models.CandidateNotes.Get(recruiterID, candidateID)
+	if err != nil {
+		return candidateNoteForm{}, err
+	}
+
+	return candidateNoteForm{Note: note.Note, Tags: strings.Join(note.Tags, ", "), Shared: note.Shared}, nil
+}
+
+func (app *application) renderCandidate(w http.ResponseWriter, req *http.Request, candidate *models.Candidate, form candidateNoteForm, contact contactForm, status int) {
+	recruiterID := app.authenticatedUserID(req)
+
+	evaluations, err := app.models.Evaluations.ListShared(candidate.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	shortlists, err := app.models.Shortlists.ListForRecruiter(recruiterID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	containing, err := app.models.Shortlists.Containing(candidate.ID, recruiterID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	conversation, err := app.models.Conversations.Find(recruiterID, candidate.ID)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, err)
+		return
+	}
+
+	teamNotes, err := app.models.CandidateNotes.Team(recruiterID, candidate.ID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	blind, err := app.models.RecruiterProfiles.BlindReview(recruiterID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	var mentor *models.Mentor
+	var reviews []*models.MentoringReview
+	if candidate.Role == models.RoleLawyer {
+		mentor, reviews, err = app.mentorRecord(candidate.ID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+
+		// Students often name their judge, so comments would give away an
+		// anonymised candidate
+		if candidate.Anonymised {
+			reviews = nil
+		}
+	}
+
+	// Split the recruiter's shortlists into those the candidate is already on
+	// and those they can still be added to
+	on := make(map[int]bool, len(containing))
+	for _, id := range containing {
+		on[id] = true
+	}
+
+	data := app.newTemplateData(req)
+	for _, s := range shortlists {
+		if on[s.ID] {
+			data.CandidateShortlists = append(data.CandidateShortlists, s)
+		} else {
+			data.Shortlists = append(data.Shortlists, s)
+		}
+	}
+
+	data.Form = form
+	data.ContactForm = contact
+	data.Conversation = conversation
+	data.TeamNotes = teamNotes
+	data.Candidate = candidate
+	data.BlindReview = blind
+	data.Evaluations = evaluations
+	data.Mentor = mentor
+	data.MentoringReviews = reviews
+	app.renderer(w, req, "candidate.tmpl.html", status, data)
+}
+
+// ==================== API: CANDIDATES ====================
+
+// candidateJSON is the API representation of a candidate. Missing scores are
+// null. Candidates anonymised by blind review are named by their pseudonym,
+// without university, firm or portfolio.
+type candidateJSON struct {
+	ID                   int        `json:"id"`
+	Name                 string     `json:"name"`
+	Anonymised           bool       `json:"anonymised"`
+	Role                 string     `json:"role"`
+	University           string     `json:"university,omitempty"`
+	YearOfStudy          int        `json:"year_of_study,omitempty"`
+	Specialization       string     `json:"specialization,omitempty"`
+	YearsOfExperience    int        `json:"years_of_experience,omitempty"`
+	FirmName             string     `json:"firm_name,omitempty"`
+	Verified             bool       `json:"verified"`
+	PortfolioURL         string     `json:"portfolio_url,omitempty"`
+	Sessions             int        `json:"sessions"`
+	OverallScore         *float64   `json:"overall_score"`
+	LegalKnowledgeScore  *float64   `json:"legal_knowledge_score"`
+	ArgumentationScore   *float64   `json:"argumentation_score"`
+	PresentationScore    *float64   `json:"presentation_score"`
+	ResponseQualityScore *float64   `json:"response_quality_score"`
+	LastSessionAt        *time.Time `json:"last_session_at"`
+}
+
+func nullFloat(n sql.NullFloat64) *float64 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Float64
+}
+
+func (app *application) newCandidateJSON(c *models.Candidate) candidateJSON {
+	js := candidateJSON{
+		ID:                   c.ID,
+		Name:                 c.Name,
+		Anonymised:           c.Anonymised,
+		Role:                 string(c.Role),
+		University:           c.University,
+		YearOfStudy:          c.YearOfStudy,
+		Specialization:       c.Specialization,
+		YearsOfExperience:    c.YearsOfExperience,
+		FirmName:             c.FirmName,
+		Verified:             c.Verified,
+		Sessions:             c.Sessions,
+		OverallScore:         nullFloat(c.OverallScore),
+		LegalKnowledgeScore:  nullFloat(c.LegalKnowledgeScore),
+		ArgumentationScore:   nullFloat(c.ArgumentationScore),
+		PresentationScore:    nullFloat(c.PresentationScore),
+		ResponseQualityScore: nullFloat(c.ResponseQualityScore),
+	}
+
+	if c.PortfolioSlug.Valid {
+		js.PortfolioURL = app.config.baseURL + "/p/" + c.PortfolioSlug.String
+	}
+	if c.LastSessionAt.Valid {
+		js.LastSessionAt = &c.LastSessionAt.Time
+	}
+
+	return js
+}
+
+func (app *application) apiCandidates(w http.ResponseWriter, req *http.Request) {
+	var form candidateSearchForm
+	err := app.decodeQuery(req, &form)
+	if err != nil {
+		app.errorJSON(w, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+
+	candidates, page, err := app.searchCandidates(req, form)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	_, err = app.anonymise(app.authenticatedUserID(req), candidates...)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	results := make([]candidateJSON, 0, len(candidates))
+	for _, c := range candidates {
+		results = append(results, app.newCandidateJSON(c))
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]any{
+		"candidates": results,
+		"metadata": map[string]int{
+			"page":        page.Page,
+			"page_size":   page.PageSize,
+			"total":       page.Total,
+			"total_pages": page.TotalPages(),
+		},
+	})
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"lawbook/internal/models"
+	"lawbook/internal/notify"
+	"lawbook/internal/validator"
+)
+
+// savedSearchMatchLimit caps the candidates recorded per check of a saved
+// search. Candidates beyond it in the search's sort order are picked up once
+// they rise into it.
+const savedSearchMatchLimit = 1000
+
+// savedSearchAlertNames is the number of new candidates named in an alert
+const savedSearchAlertNames = 5
+
+// ==================== RECRUITER: SAVED SEARCHES ====================
+
+type savedSearchForm struct {
+	Name                string                 `form:"name"`
+	Query               string                 `form:"query"`
+	Frequency           models.SearchFrequency `form:"frequency"`
+	validator.Validator `form:"-"`
+}
+
+func (f *savedSearchForm) validate() {
+	f.Name = strings.TrimSpace(f.Name)
+	f.CheckField(validator.NotBlank(f.Name), "name", "This field cannot be blank")
+	f.CheckField(validator.MaxChars(f.Name, 100), "name", "This field cannot be more than 100 characters long")
+	f.CheckField(models.ValidSearchFrequency(f.Frequency), "frequency", "Choose how often to check for new matches")
+}
+
+func (app *application) recruiterSearches(w http.ResponseWriter, req *http.Request) {
+	app.renderSearches(w, req, savedSearchForm{}, http.StatusOK)
+}
+
+// renderSearches shows the recruiter's saved searches. A form with a query
+// is one that failed validation, and is shown again so it can be corrected.
+func (app *application) renderSearches(w http.ResponseWriter, req *http.Request, form savedSearchForm, status int) {
+	searches, err := app.models.SavedSearches.ListForRecruiter(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.SavedSearches = searches
+	data.SearchFrequencies = models.SearchFrequencies
+	app.renderer(w, req, "saved-searches.tmpl.html", status, data)
+}
+
+func (app *application) recruiterSearchCreatePost(w http.ResponseWriter, req *http.Request) {
+	var form savedSearchForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Query, err = app.candidateSearchQuery(form.Query)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.validate()
+	if !form.Valid() {
+		app.renderSearches(w, req, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	recruiterID := app.authenticatedUserID(req)
+
+	id, err := app.models.SavedSearches.Insert(recruiterID, form.Name, form.Query, form.Frequency)
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicateName) {
+			form.AddFieldErrors("name", "You already have a saved search with this name")
+			app.renderSearches(w, req, form, http.StatusUnprocessableEntity)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	// Record who already matches, so the first alert only names candidates
+	// who are new since the search was saved
+	search, err := app.models.SavedSearches.Get(id, recruiterID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	_, err = app.checkSavedSearch(search)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("Search saved as %q.", form.Name))
+	http.Redirect(w, req, "/recruiter/searches", http.StatusSeeOther)
+}
+
+// recruiterSearch loads the recruiter's saved search named by the ":id"
+// parameter. It writes the error response and returns nil on failure.
+func (app *application) recruiterSearch(w http.ResponseWriter, req *http.Request) *models.SavedSearch {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	search, err := app.models.SavedSearches.Get(id, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	return search
+}
+
+type searchFrequencyForm struct {
+	Frequency models.SearchFrequency `form:"frequency"`
+}
+
+func (app *application) recruiterSearchUpdatePost(w http.ResponseWriter, req *http.Request) {
+	search := app.recruiterSearch(w, req)
+	if search == nil {
+		return
+	}
+
+	var form searchFrequencyForm
+	err := app.decodePostForm(req, &form)
+	if err != nil || !models.ValidSearchFrequency(form.Frequency) {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	err = app.models.SavedSearches.SetFrequency(search.ID, search.RecruiterID, form.Frequency)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("Alerts for %q updated.", search.Name))
+	http.Redirect(w, req, "/recruiter/searches", http.StatusSeeOther)
+}
+
+func (app *application) recruiterSearchDeletePost(w http.ResponseWriter, req *http.Request) {
+	search := app.recruiterSearch(w, req)
+	if search == nil {
+		return
+	}
+
+	err := app.models.SavedSearches.Delete(search.ID, search.RecruiterID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("%q has been deleted.", search.Name))
+	http.Redirect(w, req, "/recruiter/searches", http.StatusSeeOther)
+}
+
+// checkSavedSearch runs a saved search, records its matches and returns the
+// candidates it hadn't matched before
+func (app *application) checkSavedSearch(search *models.SavedSearch) ([]*models.Candidate, error) {
+	filter, err := app.savedCandidateFilter(search.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, _, err := app.models.Candidates.Search(search.RecruiterID, filter, savedSearchMatchLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.ID
+	}
+
+	added, err := app.models.SavedSearches.RecordMatches(search.ID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	isNew := make(map[int]bool, len(added))
+	for _, id := range added {
+		isNew[id] = true
+	}
+
+	var matches []*models.Candidate
+	for _, c := range candidates {
+		if isNew[c.ID] {
+			matches = append(matches, c)
+		}
+	}
+	return matches, nil
+}
+
+// alertSavedSearch tells a recruiter about new matches for a saved search
+func (app *application) alertSavedSearch(search *models.SavedSearch, matches []*models.Candidate) {
+	title := fmt.Sprintf("1 new candidate matches %q", search.Name)
+	if len(matches) != 1 {
+		title = fmt.Sprintf("%d new candidates match %q", len(matches), search.Name)
+	}
+
+	named := matches[:min(len(matches), savedSearchAlertNames)]
+
+	names := make([]string, len(named))
+	for i, c := range named {
+		names[i] = c.Name
+	}
+	body := strings.Join(names, ", ")
+	if more := len(matches) - len(named); more > 0 {
+		body += fmt.Sprintf(" and %d more", more)
+	}
+
+	app.publish(search.RecruiterID, notify.Event{
+		Type:     models.NotifySavedSearch,
+		Title:    title,
+		Body:     body + ".",
+		URL:      candidateSearchURL(search.Query),
+		Template: "saved-search.tmpl",
+		Data: map[string]any{
+			"Search":     search,
+			"Candidates": named,
+			"More":       len(matches) - len(named),
+		},
+	})
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"lawbook/internal/models"
+	"lawbook/internal/validator"
+)
+
+// maxCandidateTags is the number of tags a recruiter can put on one candidate
+const maxCandidateTags = 10
+
+// ==================== RECRUITER: SHORTLISTS ====================
+
+type shortlistForm struct {
+	Name                string `form:"name"`
+	Shared              bool   `form:"shared"`
+	validator.Validator `form:"-"`
+}
+
+func (f *shortlistForm) validate() {
+	f.Name = strings.TrimSpace(f.Name)
+	f.CheckField(validator.NotBlank(f.Name), "name", "This field cannot be blank")
+	f.CheckField(validator.MaxChars(f.Name, 100), "name", "This field cannot be more than 100 characters long")
+}
+
+func (app *application) recruiterShortlists(w http.ResponseWriter, req *http.Request) {
+	app.renderShortlists(w, req, shortlistForm{}, http.StatusOK)
+}
+
+func (app *application) renderShortlists(w http.ResponseWriter, req *http.Request, form shortlistForm, status int) {
+	shortlists, err := app.models.Shortlists.ListForRecruiter(app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.Shortlists = shortlists
+	app.renderer(w, req, "shortlists.tmpl.html", status, data)
+}
+
+func (app *application) recruiterShortlistCreatePost(w http.ResponseWriter, req *http.Request) {
+	var form shortlistForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.validate()
+	if !form.Valid() {
+		app.renderShortlists(w, req, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	id, err := app.models.Shortlists.Insert(app.authenticatedUserID(req), form.Name)
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicateName) {
+			form.AddFieldErrors("name", "You already have a shortlist with this name")
+			app.renderShortlists(w, req, form, http.StatusUnprocessableEntity)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Shortlist created.")
+	http.Redirect(w, req, fmt.Sprintf("/recruiter/shortlists/%d", id), http.StatusSeeOther)
+}
+
+// recruiterShortlist loads the shortlist named by the ":id" parameter if the
+// recruiter may see it. With ownerOnly set, colleagues' shared lists are
+// refused. It writes the error response and returns nil on failure.
+func (app *application) recruiterShortlist(w http.ResponseWriter, req *http.Request, ownerOnly bool) *models.Shortlist {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	shortlist, err := app.models.Shortlists.Get(id, app.authenticatedUserID(req))
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	if ownerOnly && shortlist.OwnerID != app.authenticatedUserID(req) {
+		app.clientError(w, http.StatusForbidden)
+		return nil
+	}
+
+	return shortlist
+}
+
+func (app *application) recruiterShortlistView(w http.ResponseWriter, req *http.Request) {
+	shortlist := app.recruiterShortlist(w, req, false)
+	if shortlist == nil {
+		return
+	}
+
+	app.renderShortlist(w, req, shortlist, shortlistForm{Name: shortlist.Name, Shared: shortlist.Shared}, http.StatusOK)
+}
+
+func (app *application) renderShortlist(w http.ResponseWriter, req *http.Request, shortlist *models.Shortlist, form shortlistForm, status int) {
+	candidates, err := app.models.Shortlists.Candidates(shortlist.ID, app.authenticatedUserID(req))
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	ids := make([]int, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.ID
+	}
+
+	tags, err := app.models.CandidateNotes.Tags(app.authenticatedUserID(req), ids)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	blind, err := app.anonymise(app.authenticatedUserID(req), candidates...)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.Shortlist = shortlist
+	data.Candidates = candidates
+	data.BlindReview = blind
+	data.CandidateTags = tags
+	app.renderer(w, req, "shortlist.tmpl.html", status, data)
+}
+
+func (app *application) recruiterShortlistUpdatePost(w http.ResponseWriter, req *http.Request) {
+	shortlist := app.recruiterShortlist(w, req, true)
+	if shortlist == nil {
+		return
+	}
+
+	var form shortlistForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.validate()
+	if !form.Valid() {
+		app.renderShortlist(w, req, shortlist, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = app.models.Shortlists.Update(shortlist.ID, shortlist.OwnerID, form.Name, form.Shared)
+	if err != nil {
+		if errors.Is(err, models.ErrDuplicateName) {
+			form.AddFieldErrors("name", "You already have a shortlist with this name")
+			app.renderShortlist(w, req, shortlist, form, http.StatusUnprocessableEntity)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Shortlist updated.")
+	http.Redirect(w, req, fmt.Sprintf("/recruiter/shortlists/%d", shortlist.ID), http.StatusSeeOther)
+}
+
+func (app *application) recruiterShortlistDeletePost(w http.ResponseWriter, req *http.Request) {
+	shortlist := app.recruiterShortlist(w, req, true)
+	if shortlist == nil {
+		return
+	}
+
+	err := app.models.Shortlists.Delete(shortlist.ID, shortlist.OwnerID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	app.stats.Invalidate(shortlist.OwnerID)
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("%q has been deleted.", shortlist.Name))
+	http.Redirect(w, req, "/recruiter/shortlists", http.StatusSeeOther)
+}
+
+func (app *application) recruiterShortlistRemovePost(w http.ResponseWriter, req *http.Request) {
+	shortlist := app.recruiterShortlist(w, req, false)
+	if shortlist == nil {
+		return
+	}
+
+	candidateID, err := readIntParam(req, "candidate")
+	if err != nil {
+		app.notFound(w)
+		return
+	}
+
+	err = app.models.Shortlists.RemoveCandidate(shortlist.ID, candidateID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	app.stats.Invalidate(shortlist.OwnerID)
+	app.stats.Invalidate(candidateID)
+
+	app.sessionManager.Put(req.Context(), "flash", "Candidate removed from the shortlist.")
+	http.Redirect(w, req, fmt.Sprintf("/recruiter/shortlists/%d", shortlist.ID), http.StatusSeeOther)
+}
+
+// ==================== RECRUITER: CANDIDATE NOTES ====================
+
+type shortlistAddForm struct {
+	ShortlistID int `form:"shortlist_id"`
+}
+
+func (app *application) recruiterCandidateShortlistPost(w http.ResponseWriter, req *http.Request) {
+	candidate := app.recruiterCandidate(w, req)
+	if candidate == nil {
+		return
+	}
+
+	var form shortlistAddForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	recruiterID := app.authenticatedUserID(req)
+
+	shortlist, err := app.models.Shortlists.Get(form.ShortlistID, recruiterID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.clientError(w, http.StatusBadRequest)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	err = app.models.Shortlists.AddCandidate(shortlist.ID, candidate.ID, recruiterID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	app.stats.Invalidate(shortlist.OwnerID)
+	app.stats.Invalidate(candidate.ID)
+
+	app.sessionManager.Put(req.Context(), "flash", fmt.Sprintf("%s has been added to %q.", candidate.Name, shortlist.Name))
+	http.Redirect(w, req, fmt.Sprintf("/recruiter/candidates/%d", candidate.ID), http.StatusSeeOther)
+}
+
+type candidateNoteForm struct {
+	Note                string `form:"note"`
+	Tags                string `form:"tags"`
+	Shared              bool   `form:"shared"`
+	validator.Validator `form:"-"`
+}
+
+// parseTags splits a comma-separated list into distinct lowercase tags
+func parseTags(s string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+func (app *application) recruiterCandidateNotePost(w http.ResponseWriter, req *http.Request) {
+	candidate := app.recruiterCandidate(w, req)
+	if candidate == nil {
+		return
+	}
+
+	var form candidateNoteForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Note = strings.TrimSpace(form.Note)
+	tags := parseTags(form.Tags)
+
+	form.CheckField(validator.MaxChars(form.Note, 5000), "note", "This field cannot be more than 5000 characters long")
+	form.CheckField(len(tags) <= maxCandidateTags, "tags", fmt.Sprintf("You can add up to %d tags", maxCandidateTags))
+	for _, tag := range tags {
+		form.CheckField(validator.MaxChars(tag, 30), "tags", "Each tag cannot be more than 30 characters long")
+	}
+
+	if !form.Valid() {
+		app.renderCandidate(w, req, candidate, form, contactForm{}, http.StatusUnprocessableEntity)
+		return
+	}
+
+	err = app.models.CandidateNotes.Save(&models.CandidateNote{
+		RecruiterID: app.authenticatedUserID(req),
+		CandidateID: candidate.ID,
+		Note:        form.Note,
+		Shared:      form.Shared,
+		Tags:        tags,
+	})
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Your notes have been saved.")
+	http.Redirect(w, req, fmt.Sprintf("/recruiter/candidates/%d", candidate.ID), http.StatusSeeOther)
+}
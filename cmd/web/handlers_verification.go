@@ -0,0 +1,411 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"lawbook/internal/models"
+	"lawbook/internal/notify"
+	"lawbook/internal/validator"
+)
+
+// maxDocumentSize is the largest verification document a lawyer can upload
+const maxDocumentSize = 5 << 20
+
+// documentExtensions maps the accepted document types to the extension they
+// are stored under
+var documentExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// ==================== LAWYER: BAR VERIFICATION ====================
+
+type verificationForm struct {
+	EnrolmentNumber     string `form:"enrolment_number"`
+	StateBarCouncil     string `form:"state_bar_council"`
+	validator.Validator `form:"-"`
+}
+
+// currentVerification returns the bar registration submission that decides a
+// lawyer's badge, or nil if the user isn't a lawyer or hasn't submitted one
+func (app *application) currentVerification(user *models.User) (*models.LawyerVerification, error) {
+	if user.Role != models.RoleLawyer {
+		return nil, nil
+	}
+
+	v, err := app.models.LawyerVerifications.Current(user.ID)
+	if errors.Is(err, models.ErrNoRecord) {
+		return nil, nil
+	}
+	return v, err
+}
+
+func (app *application) lawyerVerification(w http.ResponseWriter, req *http.Request) {
+	app.renderVerification(w, req, verificationForm{}, http.StatusOK)
+}
+
+// renderVerification shows the lawyer's latest submission alongside the form
+// for a new one
+func (app *application) renderVerification(w http.ResponseWriter, req *http.Request, form verificationForm, status int) {
+	userID := app.authenticatedUserID(req)
+
+	latest, err := app.models.LawyerVerifications.Latest(userID)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, err)
+		return
+	}
+
+	verified, err := app.models.LawyerVerifications.IsVerified(userID)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.Form = form
+	data.LawyerVerification = latest
+	data.LawyerVerified = verified
+	data.StateBarCouncils = models.StateBarCouncils
+	app.renderer(w, req, "lawyer-verification.tmpl.html", status, data)
+}
+
+func (app *application) lawyerVerificationPost(w http.ResponseWriter, req *http.Request) {
+	userID := app.authenticatedUserID(req)
+
+	latest, err := app.models.LawyerVerifications.Latest(userID)
+	if err != nil && !errors.Is(err, models.ErrNoRecord) {
+		app.serverError(w, err)
+		return
+	}
+	if latest != nil && latest.Pending() {
+		app.sessionManager.Put(req.Context(), "flash", "Your previous submission is still being reviewed.")
+		http.Redirect(w, req, "/lawyer/verification", http.StatusSeeOther)
+		return
+	}
+
+	// The multipart form has already been parsed by the CSRF check
+	var form verificationForm
+	err = app.formDecoder.Decode(&form, req.PostForm)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.EnrolmentNumber = strings.ToUpper(strings.TrimSpace(form.EnrolmentNumber))
+	form.CheckField(validator.NotBlank(form.EnrolmentNumber), "enrolment_number", "This field cannot be blank")
+	form.CheckField(validator.MaxChars(form.EnrolmentNumber, 100), "enrolment_number", "This field cannot be more than 100 characters long")
+	form.CheckField(models.ValidStateBarCouncil(form.StateBarCouncil), "state_bar_council", "Please select your state bar council")
+
+	file, header, err := req.FormFile("document")
+	switch {
+	case errors.Is(err, http.ErrMissingFile):
+		form.AddFieldErrors("document", "Please attach your enrolment certificate or bar council ID card")
+	case err != nil:
+		app.clientError(w, http.StatusBadRequest)
+		return
+	default:
+		defer file.Close()
+		if header.Size > maxDocumentSize {
+			form.AddFieldErrors("document", "The document must be 5 MB or smaller")
+		}
+	}
+
+	var contentType string
+	if form.FieldErrors["document"] == "" {
+		contentType, err = sniffContentType(file)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		if _, ok := documentExtensions[contentType]; !ok {
+			form.AddFieldErrors("document", "The document must be a PDF, JPEG or PNG file")
+		}
+	}
+
+	if !form.Valid() {
+		app.renderVerification(w, req, form, http.StatusUnprocessableEntity)
+		return
+	}
+
+	path, err := app.saveDocument(file, documentExtensions[contentType])
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	_, err = app.models.LawyerVerifications.Insert(&models.LawyerVerification{
+		UserID:          userID,
+		EnrolmentNumber: form.EnrolmentNumber,
+		StateBarCouncil: form.StateBarCouncil,
+		DocumentPath:    path,
+		DocumentName:    filepath.Base(header.Filename),
+		DocumentType:    contentType,
+	})
+	if err != nil {
+		os.Remove(filepath.Join(app.config.uploadDir, path))
+		app.serverError(w, err)
+		return
+	}
+
+	app.sessionManager.Put(req.Context(), "flash", "Thanks! An administrator will review your bar registration shortly.")
+	http.Redirect(w, req, "/lawyer/verification", http.StatusSeeOther)
+}
+
+// sniffContentType detects a file's type from its contents rather than
+// trusting the name or the browser, then rewinds it
+func sniffContentType(file multipart.File) (string, error) {
+	buf := make([]byte, 512)
+	n, err := file.Read(buf)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", err
+	}
+
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// saveDocument stores an uploaded document under a random name in the upload
+// directory and returns its path relative to that directory
+func (app *application) saveDocument(file io.Reader, ext string) (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(app.config.uploadDir, "verifications")
+	err = os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return "", err
+	}
+
+	name := hex.EncodeToString(b) + ext
+
+	dst, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(dst, file)
+	if err != nil {
+		dst.Close()
+		return "", err
+	}
+
+	err = dst.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join("verifications", name), nil
+}
+
+// ==================== ADMIN: LAWYER VERIFICATIONS ====================
+
+type adminVerificationFilter struct {
+	Status models.VerificationStatus `form:"status"`
+	Page   int                       `form:"page"`
+}
+
+func (app *application) adminVerifications(w http.ResponseWriter, req *http.Request) {
+	// The queue of pending submissions is shown unless another status is chosen
+	filter := adminVerificationFilter{Status: models.VerificationPending}
+	err := app.decodeQuery(req, &filter)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	page := newPagination(filter.Page, adminPageSize, req.URL.Query())
+
+	verifications, total, err := app.models.LawyerVerifications.List(filter.Status, page.PageSize, page.Offset())
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+	page.Total = total
+
+	data := app.newTemplateData(req)
+	data.Form = filter
+	data.LawyerVerifications = verifications
+	data.Pagination = page
+	app.renderer(w, req, "admin-verifications.tmpl.html", http.StatusOK, data)
+}
+
+// adminVerification loads the submission named by the ":id" parameter. It
+// writes the error response and returns nil on failure.
+func (app *application) adminVerification(w http.ResponseWriter, req *http.Request) *models.LawyerVerification {
+	id, err := readIDParam(req)
+	if err != nil {
+		app.notFound(w)
+		return nil
+	}
+
+	v, err := app.models.LawyerVerifications.Get(id)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return nil
+	}
+
+	return v
+}
+
+func (app *application) adminVerificationView(w http.ResponseWriter, req *http.Request) {
+	v := app.adminVerification(w, req)
+	if v == nil {
+		return
+	}
+
+	data := app.newTemplateData(req)
+	data.LawyerVerification = v
+	data.Form = verificationReviewForm{}
+	app.renderer(w, req, "admin-verification.tmpl.html", http.StatusOK, data)
+}
+
+func (app *application) adminVerificationDocument(w http.ResponseWriter, req *http.Request) {
+	v := app.adminVerification(w, req)
+	if v == nil {
+		return
+	}
+
+	f, err := os.Open(filepath.Join(app.config.uploadDir, v.DocumentPath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			app.notFound(w)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", v.DocumentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", v.DocumentName))
+	http.ServeContent(w, req, "", v.CreatedAt, f)
+}
+
+type verificationReviewForm struct {
+	Reason              string `form:"reason"`
+	validator.Validator `form:"-"`
+}
+
+func (app *application) adminVerificationApprovePost(w http.ResponseWriter, req *http.Request) {
+	v := app.adminVerification(w, req)
+	if v == nil {
+		return
+	}
+
+	app.reviewVerification(w, req, v, models.VerificationApproved, "")
+}
+
+func (app *application) adminVerificationRejectPost(w http.ResponseWriter, req *http.Request) {
+	v := app.adminVerification(w, req)
+	if v == nil {
+		return
+	}
+
+	var form verificationReviewForm
+	err := app.decodePostForm(req, &form)
+	if err != nil {
+		app.clientError(w, http.StatusBadRequest)
+		return
+	}
+
+	form.Reason = strings.TrimSpace(form.Reason)
+	form.CheckField(validator.NotBlank(form.Reason), "reason", "Please tell the lawyer why their submission was rejected")
+	form.CheckField(validator.MaxChars(form.Reason, 500), "reason", "This field cannot be more than 500 characters long")
+
+	if !form.Valid() {
+		data := app.newTemplateData(req)
+		data.LawyerVerification = v
+		data.Form = form
+		app.renderer(w, req, "admin-verification.tmpl.html", http.StatusUnprocessableEntity, data)
+		return
+	}
+
+	app.reviewVerification(w, req, v, models.VerificationRejected, form.Reason)
+}
+
+// reviewVerification records the decision on a submission, audits it, lets
+// the lawyer know by email and returns to the queue
+func (app *application) reviewVerification(w http.ResponseWriter, req *http.Request, v *models.LawyerVerification, status models.VerificationStatus, reason string) {
+	adminID := app.authenticatedUserID(req)
+
+	if v.UserID == adminID {
+		app.sessionManager.Put(req.Context(), "flash", "You can't review your own submission.")
+		http.Redirect(w, req, fmt.Sprintf("/admin/verifications/%d", v.ID), http.StatusSeeOther)
+		return
+	}
+
+	err := app.models.LawyerVerifications.Review(v.ID, adminID, status, reason)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			app.sessionManager.Put(req.Context(), "flash", "That submission has already been reviewed.")
+			http.Redirect(w, req, fmt.Sprintf("/admin/verifications/%d", v.ID), http.StatusSeeOther)
+		} else {
+			app.serverError(w, err)
+		}
+		return
+	}
+
+	action := models.AuditRejectLawyer
+	details := fmt.Sprintf("%s (%s): %s", v.EnrolmentNumber, v.StateBarCouncil, reason)
+	flash := fmt.Sprintf("%s's bar registration has been rejected.", v.UserName)
+
+	if status == models.VerificationApproved {
+		// The verified enrolment number replaces whatever was typed on the profile
+		err = app.models.LawyerProfiles.SetBarRegistration(v.UserID, v.EnrolmentNumber)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+
+		action = models.AuditApproveLawyer
+		details = fmt.Sprintf("%s (%s)", v.EnrolmentNumber, v.StateBarCouncil)
+		flash = fmt.Sprintf("%s is now a verified lawyer.", v.UserName)
+	}
+
+	err = app.models.AuditLog.Insert(adminID, action, v.UserID, details)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	title := "Your bar registration has been verified"
+	if status != models.VerificationApproved {
+		title = "Your bar registration could not be verified"
+	}
+
+	app.publish(v.UserID, notify.Event{
+		Type:     models.NotifyVerification,
+		Title:    title,
+		Body:     reason,
+		URL:      "/lawyer/verification",
+		Template: "lawyer-verification.tmpl",
+		Data: map[string]any{
+			"Approved": status == models.VerificationApproved,
+			"Reason":   reason,
+		},
+	})
+
+	app.sessionManager.Put(req.Context(), "flash", flash)
+	http.Redirect(w, req, "/admin/verifications", http.StatusSeeOther)
+}
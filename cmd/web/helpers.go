@@ -2,13 +2,20 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"runtime/debug"
+	"strconv"
 	"time"
 
+	"lawbook/internal/models"
+
 	"github.com/go-playground/form/v4"
+	"github.com/julienschmidt/httprouter"
 	"github.com/justinas/nosurf"
 )
 
@@ -75,6 +82,82 @@ func (app *application) decodePostForm(req *http.Request, dst interface{}) error
 	return nil
 }
 
+// decodeQuery decodes URL query parameters into a destination struct
+func (app *application) decodeQuery(req *http.Request, dst interface{}) error {
+	err := app.formDecoder.Decode(dst, req.URL.Query())
+	if err != nil {
+		var invalidDecoderError *form.InvalidDecoderError
+		if errors.As(err, &invalidDecoderError) {
+			panic(err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// readIDParam returns the positive integer ":id" route parameter, or an error
+func readIDParam(req *http.Request) (int, error) {
+	return readIntParam(req, "id")
+}
+
+// readIntParam reads a positive integer route parameter
+func readIntParam(req *http.Request, name string) (int, error) {
+	params := httprouter.ParamsFromContext(req.Context())
+
+	id, err := strconv.Atoi(params.ByName(name))
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid %s parameter", name)
+	}
+
+	return id, nil
+}
+
+// pagination describes one page of a longer list for templates
+type pagination struct {
+	Page     int
+	PageSize int
+	Total    int
+	query    url.Values
+}
+
+// newPagination normalises the requested page number. The query values are
+// kept so page links preserve the current filters.
+func newPagination(page, pageSize int, query url.Values) *pagination {
+	if page < 1 {
+		page = 1
+	}
+	return &pagination{Page: page, PageSize: pageSize, query: query}
+}
+
+// Offset returns the number of rows to skip for the current page
+func (p *pagination) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// TotalPages returns the number of pages needed to show every row
+func (p *pagination) TotalPages() int {
+	if p.Total == 0 {
+		return 1
+	}
+	return (p.Total + p.PageSize - 1) / p.PageSize
+}
+
+func (p *pagination) HasPrev() bool { return p.Page > 1 }
+func (p *pagination) HasNext() bool { return p.Page < p.TotalPages() }
+func (p *pagination) PrevPage() int { return p.Page - 1 }
+func (p *pagination) NextPage() int { return p.Page + 1 }
+
+// PageURL returns a relative link to another page with the same filters
+func (p *pagination) PageURL(page int) string {
+	q := url.Values{}
+	for k, v := range p.query {
+		q[k] = v
+	}
+	q.Set("page", strconv.Itoa(page))
+	return "?" + q.Encode()
+}
+
 // isAuthenticated checks if the current request is from an authenticated user
 func (app *application) isAuthenticated(req *http.Request) bool {
 	isAuthenticated, ok := req.Context().Value(isAuthenticatedContextKey).(bool)
@@ -84,6 +167,94 @@ func (app *application) isAuthenticated(req *http.Request) bool {
 	return isAuthenticated
 }
 
+// authenticatedUserID returns the ID of the user making the request, or 0 if
+// the request is anonymous. It works for both session and bearer-token requests.
+func (app *application) authenticatedUserID(req *http.Request) int {
+	id, ok := req.Context().Value(authenticatedUserIDContextKey).(int)
+	if !ok {
+		return 0
+	}
+	return id
+}
+
+// apiToken returns the API token used to authenticate the request, if any
+func (app *application) apiToken(req *http.Request) *models.APIToken {
+	token, ok := req.Context().Value(apiTokenContextKey).(*models.APIToken)
+	if !ok {
+		return nil
+	}
+	return token
+}
+
+// writeJSON encodes data as JSON and writes it with the given status code
+func (app *application) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	js, err := json.Marshal(data)
+	if err != nil {
+		app.serverError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(js)
+}
+
+// errorJSON sends a JSON error message with the given status code
+func (app *application) errorJSON(w http.ResponseWriter, status int, message string) {
+	app.writeJSON(w, status, map[string]string{"error": message})
+}
+
+// invalidTokenResponse rejects a request whose bearer token is unknown or expired
+func (app *application) invalidTokenResponse(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	app.errorJSON(w, http.StatusUnauthorized, "invalid or expired API token")
+}
+
+// clientIP returns the IP address of the client making the request
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// registerDevice records a new login in the user's session registry and
+// ties it to the current scs session
+func (app *application) registerDevice(req *http.Request, userID int) error {
+	token, err := app.models.Sessions.Insert(userID, req.UserAgent(), clientIP(req))
+	if err != nil {
+		return err
+	}
+
+	app.sessionManager.Put(req.Context(), "deviceToken", token)
+	return nil
+}
+
+// checkDeviceSession reports whether the current login is still present in
+// the session registry, refreshing its last-seen time if so. Logins made
+// before the registry existed are registered on first use.
+func (app *application) checkDeviceSession(req *http.Request, userID int) (bool, error) {
+	token := app.sessionManager.GetString(req.Context(), "deviceToken")
+	if token == "" {
+		return true, app.registerDevice(req, userID)
+	}
+
+	session, err := app.models.Sessions.Get(token)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) || errors.Is(err, models.ErrExpiredSession) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if session.UserID != userID {
+		return false, nil
+	}
+
+	return true, app.models.Sessions.Touch(token, clientIP(req))
+}
+
 // newTemplateData creates a new templateData struct with default values
 func (app *application) newTemplateData(req *http.Request) *templateData {
 	data := &templateData{
@@ -95,11 +266,24 @@ func (app *application) newTemplateData(req *http.Request) *templateData {
 
 	// Add user info if authenticated
 	if data.IsAuthenticated {
-		userID := app.sessionManager.GetInt(req.Context(), "authenticatedUserId")
-		user, err := app.models.Users.Get(userID)
+		user, err := app.models.Users.Get(app.authenticatedUserID(req))
 		if err == nil {
 			data.User = user
 		}
+
+		// A failure to count messages or notifications shouldn't stop the
+		// page rendering
+		if user != nil && user.Role != models.RoleAdmin {
+			data.UnreadMessages, err = app.models.Conversations.UnreadCount(user.ID)
+			if err != nil {
+				app.errorLog.Printf("counting unread messages for user %d: %s", user.ID, err)
+			}
+
+			data.UnreadNotifications, err = app.models.Notifications.UnreadCount(user.ID)
+			if err != nil {
+				app.errorLog.Printf("counting unread notifications for user %d: %s", user.ID, err)
+			}
+		}
 	}
 
 	return data
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"lawbook/internal/jobs"
+	"lawbook/internal/mailer"
+	"lawbook/internal/models"
+	"lawbook/internal/notify"
+)
+
+// emailTemplateDir holds the templates rendered by mailer.Render
+const emailTemplateDir = "./ui/email"
+
+// notificationRetention is how long read notifications are kept
+const notificationRetention = 90 * 24 * time.Hour
+
+// notifyEvaluationsJobName is also used to find where the previous run left
+// off
+const notifyEvaluationsJobName = "notify-evaluations"
+
+// newScheduler registers the application's background jobs
+func (app *application) newScheduler(db *sql.DB) (*jobs.Scheduler, error) {
+	scheduler := jobs.New(db, app.models.JobRuns, app.infoLog, app.errorLog)
+
+	schedule := []struct {
+		name string
+		spec string
+		fn   jobs.Func
+	}{
+		{"cleanup-sessions", "@every 30m", app.cleanupSessionsJob},
+		{"cleanup-stale-invites", "15 2 * * *", app.cleanupStaleInvitesJob},
+		{"weekly-digest", "0 8 * * 1", app.weeklyDigestJob},
+		{awardAchievementsJobName, "@every 5m", app.awardAchievementsJob},
+		{notifyEvaluationsJobName, "@every 5m", app.notifyEvaluationsJob},
+		{"saved-search-alerts", "@every 15m", app.savedSearchAlertsJob},
+		{"interview-reminders", "@every 15m", app.interviewRemindersJob},
+		{"moot-reminders", "@every 5m", app.mootRemindersJob},
+		{"start-scheduled-moots", "@every 1m", app.startScheduledMootsJob},
+		{"prune-notifications", "30 3 * * *", app.pruneNotificationsJob},
+		{"prune-job-runs", "45 3 * * *", app.pruneJobRunsJob},
+	}
+
+	for _, j := range schedule {
+		err := scheduler.Add(j.name, j.spec, j.fn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return scheduler, nil
+}
+
+// cleanupSessionsJob removes expired device sessions, API tokens and
+// password reset links
+func (app *application) cleanupSessionsJob(ctx context.Context) error {
+	err := app.models.Sessions.CleanupExpired()
+	if err != nil {
+		return err
+	}
+
+	err = app.models.APITokens.DeleteExpired()
+	if err != nil {
+		return err
+	}
+
+	return app.models.PasswordResets.DeleteExpired()
+}
+
+// cleanupStaleInvitesJob removes moot sessions whose invited participants
+// never turned up
+func (app *application) cleanupStaleInvitesJob(ctx context.Context) error {
+	removed, err := app.models.MootSessions.DeleteStaleSetup(7 * 24 * time.Hour)
+	if err != nil {
+		return err
+	}
+
+	if removed > 0 {
+		app.infoLog.Printf("Removed %d stale moot session invites", removed)
+	}
+	return nil
+}
+
+// digestCandidates is the most new candidates listed in a recruiter's digest
+const digestCandidates = 5
+
+// weeklyDigestJob emails each subscriber a summary of their past week.
+// Subscribers already sent a digest in the past six days are skipped, so a
+// rerun after a partial failure doesn't send anyone two.
+func (app *application) weeklyDigestJob(ctx context.Context) error {
+	now := time.Now()
+
+	subscribers, err := app.models.Digests.Subscribers(now.AddDate(0, 0, -6))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, s := range subscribers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := app.sendDigest(s, now.AddDate(0, 0, -7))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", s.UserID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sendDigest emails one subscriber their digest for the period since the
+// given time. Students and lawyers hear about their practice; recruiters
+// hear about new candidates matching their saved search, and only when
+// there are some.
+func (app *application) sendDigest(s *models.DigestSubscriber, since time.Time) error {
+	unsubscribeURL := app.digestUnsubscribeURL(s.UserID)
+
+	data := map[string]any{
+		"Name":           s.Name,
+		"Role":           s.Role,
+		"BaseURL":        app.config.baseURL,
+		"SettingsURL":    app.config.baseURL + "/user/account/notifications",
+		"UnsubscribeURL": unsubscribeURL,
+	}
+
+	switch s.Role {
+	case models.RoleStudent, models.RoleLawyer:
+		summary, err := app.models.Evaluations.ActivitySince(s.UserID, since)
+		if err != nil {
+			return err
+		}
+		data["Summary"] = summary
+
+	case models.RoleRecruiter:
+		filter, err := app.savedCandidateFilter(s.CandidateFilter)
+		if err != nil {
+			return err
+		}
+		filter.JoinedSince = since
+
+		candidates, total, err := app.models.Candidates.Search(s.UserID, filter, digestCandidates, 0)
+		if err != nil {
+			return err
+		}
+		if total == 0 {
+			return app.models.Digests.MarkSent(s.UserID)
+		}
+
+		_, err = app.anonymise(s.UserID, candidates...)
+		if err != nil {
+			return err
+		}
+
+		data["Candidates"] = candidates
+		data["NewCandidates"] = total
+		data["SearchURL"] = app.config.baseURL + candidateSearchURL(s.CandidateFilter)
+
+	default:
+		return nil
+	}
+
+	msg, err := mailer.Render(emailTemplateDir, "digest.tmpl", s.Email, data)
+	if err != nil {
+		return err
+	}
+
+	// One-click unsubscribe (RFC 8058), which mail clients show beside the
+	// sender
+	msg.Headers = map[string]string{
+		"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+
+	err = app.mailer.Send(msg)
+	if err != nil {
+		return err
+	}
+
+	return app.models.Digests.MarkSent(s.UserID)
+}
+
+// notifyEvaluationsJob tells candidates about evaluations created since the
+// last successful run. The first run only looks back over one interval, so
+// existing evaluations aren't announced all at once.
+func (app *application) notifyEvaluationsJob(ctx context.Context) error {
+	since, err := app.models.JobRuns.LastSuccess(notifyEvaluationsJobName)
+	if err != nil {
+		if !errors.Is(err, models.ErrNoRecord) {
+			return err
+		}
+		since = time.Now().Add(-5 * time.Minute)
+	}
+
+	evaluations, err := app.models.Evaluations.CreatedSince(since)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range evaluations {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		user, err := app.models.Users.Get(e.UserID)
+		if err != nil {
+			return err
+		}
+
+		url := "/lawyer/dashboard"
+		if user.Role == models.RoleStudent {
+			url = "/student/progress"
+		}
+
+		caseType := e.CaseType
+		if caseType == "" {
+			caseType = "General"
+		}
+
+		app.publish(e.UserID, notify.Event{
+			Type:  models.NotifyEvaluation,
+			Title: "Your moot court evaluation is ready",
+			Body:  fmt.Sprintf("%s, %s difficulty: overall score %s.", caseType, e.Difficulty, score(e.OverallScore)),
+			URL:   url,
+		})
+	}
+
+	return nil
+}
+
+// savedSearchAlertsJob checks the saved searches that are due and alerts
+// recruiters to new matches. Searches are treated as due up to one run early,
+// so that a daily search is checked at about the same time each day rather
+// than drifting later by a run every day.
+func (app *application) savedSearchAlertsJob(ctx context.Context) error {
+	searches, err := app.models.SavedSearches.Due(time.Now().Add(15 * time.Minute))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, search := range searches {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		matches, err := app.checkSavedSearch(search)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("saved search %d: %w", search.ID, err))
+			continue
+		}
+
+		if len(matches) == 0 {
+			continue
+		}
+
+		_, err = app.anonymise(search.RecruiterID, matches...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("saved search %d: %w", search.ID, err))
+			continue
+		}
+
+		app.alertSavedSearch(search, matches)
+	}
+
+	return errors.Join(errs...)
+}
+
+// interviewRemindersJob reminds the participants of interviews starting
+// within interviewReminderLead. Each interview is only reminded once, unless
+// it is rescheduled.
+func (app *application) interviewRemindersJob(ctx context.Context) error {
+	interviews, err := app.models.Interviews.DueReminders(time.Now().Add(interviewReminderLead))
+	if err != nil {
+		return err
+	}
+
+	for _, i := range interviews {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := app.models.Interviews.MarkReminded(i.ID)
+		if err != nil {
+			return err
+		}
+
+		app.remindInterview(i)
+	}
+
+	return nil
+}
+
+// mootRemindersJob reminds the participants of scheduled moots starting
+// within mootReminderLead
+func (app *application) mootRemindersJob(ctx context.Context) error {
+	moots, err := app.models.MootSessions.DueReminders(time.Now().Add(mootReminderLead))
+	if err != nil {
+		return err
+	}
+
+	for _, s := range moots {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := app.models.MootSessions.MarkReminded(s.ID)
+		if err != nil {
+			return err
+		}
+
+		app.remindMoot(s)
+	}
+
+	return nil
+}
+
+// startScheduledMootsJob starts scheduled moots once everyone has checked in,
+// or once the check-in grace period has passed, applying each moot's no-show
+// policy to anyone missing
+func (app *application) startScheduledMootsJob(ctx context.Context) error {
+	moots, err := app.models.MootSessions.DueToStart(time.Now().Add(-mootCheckInGrace))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, s := range moots {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := app.startMoot(s)
+		if err != nil && !errors.Is(err, models.ErrNoRecord) {
+			errs = append(errs, fmt.Errorf("moot %d: %w", s.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// pruneNotificationsJob removes notifications read more than
+// notificationRetention ago
+func (app *application) pruneNotificationsJob(ctx context.Context) error {
+	removed, err := app.models.Notifications.DeleteReadBefore(time.Now().Add(-notificationRetention))
+	if err != nil {
+		return err
+	}
+
+	if removed > 0 {
+		app.infoLog.Printf("Removed %d read notifications", removed)
+	}
+	return nil
+}
+
+// pruneJobRunsJob keeps the job run history to the last 30 days
+func (app *application) pruneJobRunsJob(ctx context.Context) error {
+	return app.models.JobRuns.DeleteOlderThan(30 * 24 * time.Hour)
+}
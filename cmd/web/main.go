@@ -1,7 +1,9 @@
 package main
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"flag"
 	"html/template"
 	"log"
@@ -9,7 +11,14 @@ import (
 	"os"
 	"time"
 
+	// Interview times are entered in IANA time zones, which shouldn't
+	// depend on the server having a time zone database installed
+	_ "time/tzdata"
+
+	"lawbook/internal/mailer"
 	"lawbook/internal/models"
+	"lawbook/internal/notify"
+	"lawbook/internal/stats"
 
 	"github.com/alexedwards/scs/mysqlstore"
 	"github.com/alexedwards/scs/v2"
@@ -17,15 +26,40 @@ import (
 	_ "github.com/go-sql-driver/mysql"
 )
 
+// config holds the settings supplied on the command line
+type config struct {
+	addr                   string
+	dsn                    string
+	baseURL                string
+	secretKey              string
+	jobs                   bool
+	uploadDir              string
+	requireVerifiedLawyers bool
+	smtp                   struct {
+		host     string
+		port     int
+		username string
+		password string
+		sender   string
+	}
+}
+
 type application struct {
+	config         config
 	infoLog        *log.Logger
 	errorLog       *log.Logger
 	models         *models.Models
 	tempCache      map[string]*template.Template
 	formDecoder    *form.Decoder
 	sessionManager *scs.SessionManager
+	mailer         mailer.Mailer
+	stats          *stats.Service
+	notifier       *notify.Publisher
 }
 
+// dashboardStatsTTL is how long a user's dashboard figures are cached
+const dashboardStatsTTL = time.Minute
+
 func openDB(dsn string) (*sql.DB, error) {
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
@@ -37,25 +71,60 @@ func openDB(dsn string) (*sql.DB, error) {
 	return db, nil
 }
 
+// randomKey generates a signing key for when none is configured
+func randomKey() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 func main() {
-	addr := flag.String("addr", ":4000", "HTTP network address")
-	dsn := flag.String("dsn", os.Getenv("LAWBOOK_DB_DSN"), "MySQL data source name")
+	var cfg config
+
+	flag.StringVar(&cfg.addr, "addr", ":4000", "HTTP network address")
+	flag.StringVar(&cfg.dsn, "dsn", os.Getenv("LAWBOOK_DB_DSN"), "MySQL data source name")
+	flag.StringVar(&cfg.baseURL, "base-url", "http://localhost:4000", "Public URL of the site, used in emailed links")
+	flag.StringVar(&cfg.secretKey, "secret-key", os.Getenv("LAWBOOK_SECRET_KEY"), "Key for signing links sent by email, such as digest unsubscribe links")
+	flag.BoolVar(&cfg.jobs, "jobs", true, "Run scheduled background jobs on this instance")
+	flag.StringVar(&cfg.uploadDir, "upload-dir", "./uploads", "Directory for uploaded verification documents")
+	flag.BoolVar(&cfg.requireVerifiedLawyers, "require-verified-lawyers", false, "Restrict lawyer features to lawyers with an approved bar registration")
+	flag.StringVar(&cfg.smtp.host, "smtp-host", os.Getenv("LAWBOOK_SMTP_HOST"), "SMTP host (emails are logged when empty)")
+	flag.IntVar(&cfg.smtp.port, "smtp-port", 587, "SMTP port")
+	flag.StringVar(&cfg.smtp.username, "smtp-username", os.Getenv("LAWBOOK_SMTP_USERNAME"), "SMTP username")
+	flag.StringVar(&cfg.smtp.password, "smtp-password", os.Getenv("LAWBOOK_SMTP_PASSWORD"), "SMTP password")
+	flag.StringVar(&cfg.smtp.sender, "smtp-sender", "Lawbook <no-reply@mylawbook.in>", "SMTP sender")
 	flag.Parse()
 
-	if *dsn == "" {
+	if cfg.dsn == "" {
 		// fallback for local development
-		*dsn = "root:password@tcp(localhost:3306)/lawbookauth?parseTime=true&multiStatements=true"
+		cfg.dsn = "root:password@tcp(localhost:3306)/lawbookauth?parseTime=true&multiStatements=true"
 	}
 
 	infoLog := log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
 	errorLog := log.New(os.Stderr, "ERROR\t", log.Ldate|log.Ltime|log.Lshortfile)
 
-	db, err := openDB(*dsn)
+	db, err := openDB(cfg.dsn)
 	if err != nil {
 		errorLog.Fatal(err)
 	}
 	defer db.Close()
 
+	if cfg.secretKey == "" {
+		cfg.secretKey, err = randomKey()
+		if err != nil {
+			errorLog.Fatal(err)
+		}
+		errorLog.Print("No -secret-key set; emailed unsubscribe links will stop working when the server restarts")
+	}
+
+	err = os.MkdirAll(cfg.uploadDir, 0o750)
+	if err != nil {
+		errorLog.Fatal(err)
+	}
+
 	tempCache, err := newTemplateCache()
 	if err != nil {
 		errorLog.Fatal(err)
@@ -65,24 +134,47 @@ func main() {
 
 	sessionManager := scs.New()
 	sessionManager.Store = mysqlstore.New(db)
-	sessionManager.Lifetime = 12 * time.Hour
+	sessionManager.Lifetime = models.SessionLifetime
 	sessionManager.Cookie.Secure = true
 	sessionManager.Cookie.HttpOnly = true
 	sessionManager.Cookie.SameSite = http.SameSiteLaxMode
 	sessionManager.Cookie.Path = "/"
 	sessionManager.Cookie.Name = "lawbook_session"
 
+	var mail mailer.Mailer = &mailer.Log{Logger: infoLog}
+	if cfg.smtp.host != "" {
+		mail = &mailer.SMTP{
+			Host:     cfg.smtp.host,
+			Port:     cfg.smtp.port,
+			Username: cfg.smtp.username,
+			Password: cfg.smtp.password,
+			Sender:   cfg.smtp.sender,
+		}
+	}
+
 	app := &application{
+		config:         cfg,
 		errorLog:       errorLog,
 		infoLog:        infoLog,
 		models:         models.NewModels(db),
 		tempCache:      tempCache,
 		formDecoder:    formDecoder,
 		sessionManager: sessionManager,
+		mailer:         mail,
+	}
+	app.stats = stats.New(app.models, dashboardStatsTTL)
+	app.notifier = notify.New(app.models, mail, emailTemplateDir, cfg.baseURL, errorLog)
+
+	if cfg.jobs {
+		scheduler, err := app.newScheduler(db)
+		if err != nil {
+			errorLog.Fatal(err)
+		}
+		scheduler.Start()
 	}
 
 	srv := &http.Server{
-		Addr:         *addr,
+		Addr:         cfg.addr,
 		ErrorLog:     errorLog,
 		Handler:      app.routes(),
 		IdleTimeout:  time.Minute,
@@ -90,7 +182,7 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 	}
 
-	infoLog.Printf("Starting Lawbook server on %s", *addr)
+	infoLog.Printf("Starting Lawbook server on %s", cfg.addr)
 	err = srv.ListenAndServe()
 	errorLog.Fatal(err)
 }
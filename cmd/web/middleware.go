@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"lawbook/internal/models"
 
@@ -26,6 +28,17 @@ func secureHeaders(next http.Handler) http.Handler {
 	})
 }
 
+// maxRequestBody caps the size of request bodies. It must run before noSurf,
+// which parses the body to find the CSRF token.
+func maxRequestBody(n int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.Body = http.MaxBytesReader(w, req.Body, n)
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
 // logRequest logs each HTTP request
 func (app *application) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
@@ -72,6 +85,14 @@ func noSurf(next http.Handler) http.Handler {
 		SameSite: http.SameSiteLaxMode,
 	})
 
+	// API requests that authenticated with a bearer token carry no cookies,
+	// so they cannot be forged cross-site
+	csrfHandler.ExemptFunc(isTokenAuthenticatedAPI)
+
+	// Mail clients unsubscribe from the digest with a cookieless POST
+	// (RFC 8058). The signed token in the link stands in for the CSRF token.
+	csrfHandler.ExemptPath("/digest/unsubscribe")
+
 	csrfHandler.SetFailureHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "CSRF token validation failed: "+nosurf.Reason(r).Error(), http.StatusBadRequest)
 	}))
@@ -82,20 +103,42 @@ func noSurf(next http.Handler) http.Handler {
 // authenticate checks if a user is authenticated and adds user info to context
 func (app *application) authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// API requests already authenticated by authenticateToken act as the
+		// token's user, whatever session cookie they carry
+		if app.apiToken(req) != nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
 		id := app.sessionManager.GetInt(req.Context(), "authenticatedUserId")
 		if id == 0 {
 			next.ServeHTTP(w, req)
 			return
 		}
 
-		exists, err := app.models.Users.Exists(id)
+		active, err := app.models.Users.IsActive(id)
 		if err != nil {
 			app.serverError(w, err)
 			return
 		}
 
-		if exists {
+		if active {
+			// Check the device is still in the user's session registry; it is
+			// removed when the user signs it out from another device.
+			registered, err := app.checkDeviceSession(req, id)
+			if err != nil {
+				app.serverError(w, err)
+				return
+			}
+			if !registered {
+				app.sessionManager.Remove(req.Context(), "authenticatedUserId")
+				app.sessionManager.Remove(req.Context(), "deviceToken")
+				next.ServeHTTP(w, req)
+				return
+			}
+
 			ctx := context.WithValue(req.Context(), isAuthenticatedContextKey, true)
+			ctx = context.WithValue(ctx, authenticatedUserIDContextKey, id)
 			req = req.WithContext(ctx)
 		}
 
@@ -103,13 +146,119 @@ func (app *application) authenticate(next http.Handler) http.Handler {
 	})
 }
 
+// authenticateToken authenticates requests carrying an "Authorization: Bearer"
+// API token. It populates the same context as authenticate, plus the token
+// itself so scopes can be checked. Requests without the header pass through.
+func (app *application) authenticateToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Vary", "Authorization")
+
+		if !hasBearerToken(req) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		plaintext := strings.TrimSpace(req.Header.Get("Authorization")[len("Bearer "):])
+
+		token, err := app.models.APITokens.GetByPlaintext(plaintext)
+		if err != nil {
+			if errors.Is(err, models.ErrNoRecord) || errors.Is(err, models.ErrExpiredToken) {
+				app.invalidTokenResponse(w)
+			} else {
+				app.serverError(w, err)
+			}
+			return
+		}
+
+		active, err := app.models.Users.IsActive(token.UserID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+		if !active {
+			app.invalidTokenResponse(w)
+			return
+		}
+
+		err = app.models.APITokens.Touch(token.ID)
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+
+		ctx := context.WithValue(req.Context(), isAuthenticatedContextKey, true)
+		ctx = context.WithValue(ctx, authenticatedUserIDContextKey, token.UserID)
+		ctx = context.WithValue(ctx, apiTokenContextKey, token)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// requireAPIAuthentication is the JSON equivalent of requireAuthentication
+func (app *application) requireAPIAuthentication(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !app.isAuthenticated(req) {
+			app.errorJSON(w, http.StatusUnauthorized, "not authenticated")
+			return
+		}
+
+		w.Header().Add("Cache-Control", "no-store")
+		next.ServeHTTP(w, req)
+	})
+}
+
+// requireScope checks that a token-authenticated request was granted scope.
+// Requests authenticated by the session cookie are not restricted by scopes.
+func (app *application) requireScope(scope models.APIScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			token := app.apiToken(req)
+			if token != nil && !token.HasScope(scope) {
+				app.errorJSON(w, http.StatusForbidden, fmt.Sprintf("token is missing the %q scope", scope))
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// requireAPIRole is requireRole for JSON endpoints
+func (app *application) requireAPIRole(role models.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			user, err := app.models.Users.Get(app.authenticatedUserID(req))
+			if err != nil {
+				app.serverError(w, err)
+				return
+			}
+
+			if user.Role != role {
+				app.errorJSON(w, http.StatusForbidden, fmt.Sprintf("this endpoint is only available to %s accounts", role))
+				return
+			}
+
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// hasBearerToken reports whether the request carries a bearer Authorization header
+func hasBearerToken(req *http.Request) bool {
+	return strings.HasPrefix(req.Header.Get("Authorization"), "Bearer ")
+}
+
+// isTokenAuthenticatedAPI reports whether req is for the JSON API and was
+// authenticated with a valid API token by authenticateToken
+func isTokenAuthenticatedAPI(req *http.Request) bool {
+	_, ok := req.Context().Value(apiTokenContextKey).(*models.APIToken)
+	return ok && strings.HasPrefix(req.URL.Path, "/api/")
+}
+
 // requireRole creates middleware that checks if user has a specific role
 func (app *application) requireRole(role models.UserRole) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			userID := app.sessionManager.GetInt(req.Context(), "authenticatedUserId")
-
-			user, err := app.models.Users.Get(userID)
+			user, err := app.models.Users.Get(app.authenticatedUserID(req))
 			if err != nil {
 				app.serverError(w, err)
 				return
@@ -130,9 +279,7 @@ func (app *application) requireRole(role models.UserRole) func(http.Handler) htt
 func (app *application) requireAnyRole(roles ...models.UserRole) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			userID := app.sessionManager.GetInt(req.Context(), "authenticatedUserId")
-
-			user, err := app.models.Users.Get(userID)
+			user, err := app.models.Users.Get(app.authenticatedUserID(req))
 			if err != nil {
 				app.serverError(w, err)
 				return
@@ -156,3 +303,37 @@ func (app *application) requireAnyRole(roles ...models.UserRole) func(http.Handl
 		})
 	}
 }
+
+// requireVerifiedLawyer sends lawyers without an approved bar registration to
+// the verification page when the -require-verified-lawyers policy is on.
+// Other roles pass straight through.
+func (app *application) requireVerifiedLawyer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !app.config.requireVerifiedLawyers {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		user, err := app.models.Users.Get(app.authenticatedUserID(req))
+		if err != nil {
+			app.serverError(w, err)
+			return
+		}
+
+		if user.Role == models.RoleLawyer {
+			verified, err := app.models.LawyerVerifications.IsVerified(user.ID)
+			if err != nil {
+				app.serverError(w, err)
+				return
+			}
+
+			if !verified {
+				app.sessionManager.Put(req.Context(), "flash", "Please verify your bar registration to use this feature.")
+				http.Redirect(w, req, "/lawyer/verification", http.StatusSeeOther)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
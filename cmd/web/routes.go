@@ -23,6 +23,7 @@ func (app *application) routes() http.Handler {
 		app.recoverPanic,
 		app.logRequest,
 		secureHeaders,
+		maxRequestBody(maxDocumentSize+1<<20),
 	)
 
 	// Dynamic middleware (with sessions, CSRF, and auth check)
@@ -39,38 +40,199 @@ func (app *application) routes() http.Handler {
 	studentOnly := protected.Append(app.requireRole(models.RoleStudent))
 	lawyerOnly := protected.Append(app.requireRole(models.RoleLawyer))
 	recruiterOnly := protected.Append(app.requireRole(models.RoleRecruiter))
+	adminOnly := protected.Append(app.requireRole(models.RoleAdmin))
 
-	// Lawyers and students can access moot court
-	mootCourtAccess := protected.Append(app.requireAnyRole(models.RoleStudent, models.RoleLawyer))
+	// JSON API (session cookie or "Authorization: Bearer" API token). Tokens
+	// are checked before CSRF protection, which token requests are exempt from.
+	api := standard.Append(
+		app.sessionManager.LoadAndSave,
+		app.authenticateToken,
+		noSurf,
+		app.authenticate,
+		app.requireAPIAuthentication,
+	)
+
+	// Lawyers and students can access moot court. Lawyers may need a verified
+	// bar registration first (-require-verified-lawyers).
+	mootCourtAccess := protected.Append(app.requireAnyRole(models.RoleStudent, models.RoleLawyer), app.requireVerifiedLawyer)
+
+	// Verified lawyers mentor students, when verification is required
+	mentorOnly := lawyerOnly.Append(app.requireVerifiedLawyer)
+
+	// Lawyers and students can publish a portfolio and choose what recruiters see
+	candidateOnly := protected.Append(app.requireAnyRole(models.RoleStudent, models.RoleLawyer))
+
+	// Recruiters message students and lawyers
+	messagingAccess := protected.Append(app.requireAnyRole(models.RoleStudent, models.RoleLawyer, models.RoleRecruiter))
 
 	// ==================== PUBLIC ROUTES ====================
 	router.Handler(http.MethodGet, "/", dynamic.ThenFunc(app.home))
 	router.Handler(http.MethodGet, "/about", dynamic.ThenFunc(app.about))
-	// Add under PUBLIC ROUTES
-	router.Handler(http.MethodGet, "/api/user/me", dynamic.ThenFunc(app.apiUserMe))
+	router.Handler(http.MethodGet, "/p/:slug", dynamic.ThenFunc(app.portfolioView))
+	router.Handler(http.MethodGet, "/digest/unsubscribe", dynamic.ThenFunc(app.digestUnsubscribe))
+	router.Handler(http.MethodPost, "/digest/unsubscribe", dynamic.ThenFunc(app.digestUnsubscribePost))
+
+	// Calendar applications fetch feeds without a session, authenticated by
+	// the secret in the link, which users can reset
+	router.Handler(http.MethodGet, "/calendar.ics", standard.ThenFunc(app.calendarFeed))
+	router.Handler(http.MethodPost, "/calendar/reset", protected.ThenFunc(app.calendarResetPost))
 
 	// Authentication routes
 	router.Handler(http.MethodGet, "/user/signup", dynamic.ThenFunc(app.userSignup))
 	router.Handler(http.MethodPost, "/user/signup", dynamic.ThenFunc(app.userSignupPost))
 	router.Handler(http.MethodGet, "/user/login", dynamic.ThenFunc(app.userLogin))
 	router.Handler(http.MethodPost, "/user/login", dynamic.ThenFunc(app.userLoginPost))
+	router.Handler(http.MethodGet, "/user/password/reset", dynamic.ThenFunc(app.userPasswordReset))
+	router.Handler(http.MethodPost, "/user/password/reset", dynamic.ThenFunc(app.userPasswordResetPost))
 
 	// ==================== PROTECTED ROUTES ====================
 	router.Handler(http.MethodPost, "/user/logout", protected.ThenFunc(app.userLogout))
 	router.Handler(http.MethodGet, "/user/account", protected.ThenFunc(app.accountView))
+	router.Handler(http.MethodGet, "/user/account/profile", protected.ThenFunc(app.profileEdit))
+	router.Handler(http.MethodPost, "/user/account/profile", protected.ThenFunc(app.profileEditPost))
+	router.Handler(http.MethodGet, "/user/account/portfolio", candidateOnly.ThenFunc(app.portfolioEdit))
+	router.Handler(http.MethodPost, "/user/account/portfolio", candidateOnly.ThenFunc(app.portfolioEditPost))
+	router.Handler(http.MethodGet, "/user/account/privacy", candidateOnly.ThenFunc(app.consentEdit))
+	router.Handler(http.MethodPost, "/user/account/privacy", candidateOnly.ThenFunc(app.consentEditPost))
+	router.Handler(http.MethodGet, "/user/account/sessions", protected.ThenFunc(app.accountSessions))
+	router.Handler(http.MethodPost, "/user/account/sessions/revoke/:id", protected.ThenFunc(app.accountSessionRevokePost))
+	router.Handler(http.MethodPost, "/user/account/sessions/revoke-others", protected.ThenFunc(app.accountSessionRevokeOthersPost))
+	router.Handler(http.MethodPost, "/user/account/tokens", protected.ThenFunc(app.apiTokenCreatePost))
+	router.Handler(http.MethodPost, "/user/account/tokens/:id/revoke", protected.ThenFunc(app.apiTokenRevokePost))
+	router.Handler(http.MethodGet, "/user/account/notifications", protected.ThenFunc(app.notificationPreferences))
+	router.Handler(http.MethodPost, "/user/account/notifications", protected.ThenFunc(app.notificationPreferencesPost))
+
+	// Notification centre
+	router.Handler(http.MethodGet, "/notifications", protected.ThenFunc(app.notifications))
+	router.Handler(http.MethodPost, "/notifications", protected.ThenFunc(app.notificationsReadAllPost))
+	router.Handler(http.MethodGet, "/notifications/:id", protected.ThenFunc(app.notificationOpen))
+	router.Handler(http.MethodPost, "/notifications/:id/read", protected.ThenFunc(app.notificationReadPost))
+
+	// ==================== JOB BOARD (Students & Lawyers) ====================
+	router.Handler(http.MethodGet, "/jobs", candidateOnly.ThenFunc(app.jobs))
+	router.Handler(http.MethodGet, "/jobs/:id", candidateOnly.ThenFunc(app.jobView))
+	router.Handler(http.MethodPost, "/jobs/:id/apply", candidateOnly.ThenFunc(app.jobApplyPost))
 
 	// ==================== STUDENT ROUTES ====================
 	router.Handler(http.MethodGet, "/student/dashboard", studentOnly.ThenFunc(app.studentDashboard))
+	router.Handler(http.MethodGet, "/student/progress", studentOnly.ThenFunc(app.studentProgress))
 
 	// ==================== LAWYER ROUTES ====================
 	router.Handler(http.MethodGet, "/lawyer/dashboard", lawyerOnly.ThenFunc(app.lawyerDashboard))
+	router.Handler(http.MethodGet, "/lawyer/verification", lawyerOnly.ThenFunc(app.lawyerVerification))
+	router.Handler(http.MethodPost, "/lawyer/verification", lawyerOnly.ThenFunc(app.lawyerVerificationPost))
+	router.Handler(http.MethodGet, "/lawyer/profile-views", lawyerOnly.ThenFunc(app.lawyerProfileViews))
+	router.Handler(http.MethodGet, "/lawyer/mentoring", mentorOnly.ThenFunc(app.mentorProfileEdit))
+	router.Handler(http.MethodPost, "/lawyer/mentoring", mentorOnly.ThenFunc(app.mentorProfileEditPost))
+	router.Handler(http.MethodPost, "/lawyer/mentoring/slots", mentorOnly.ThenFunc(app.mentorSlotsPost))
+	router.Handler(http.MethodPost, "/lawyer/mentoring/slots/:id/delete", mentorOnly.ThenFunc(app.mentorSlotDeletePost))
 
 	// ==================== RECRUITER ROUTES ====================
 	router.Handler(http.MethodGet, "/recruiter/dashboard", recruiterOnly.ThenFunc(app.recruiterDashboard))
+	router.Handler(http.MethodGet, "/recruiter/candidates", recruiterOnly.ThenFunc(app.recruiterCandidates))
+	router.Handler(http.MethodGet, "/recruiter/candidates/:id", recruiterOnly.ThenFunc(app.recruiterCandidateView))
+	router.Handler(http.MethodPost, "/recruiter/candidates/:id/shortlist", recruiterOnly.ThenFunc(app.recruiterCandidateShortlistPost))
+	router.Handler(http.MethodPost, "/recruiter/candidates/:id/notes", recruiterOnly.ThenFunc(app.recruiterCandidateNotePost))
+	router.Handler(http.MethodPost, "/recruiter/candidates/:id/contact", recruiterOnly.ThenFunc(app.recruiterCandidateContactPost))
+	router.Handler(http.MethodPost, "/recruiter/candidates/:id/reveal", recruiterOnly.ThenFunc(app.recruiterCandidateRevealPost))
+	router.Handler(http.MethodGet, "/recruiter/candidates/:id/interview", recruiterOnly.ThenFunc(app.recruiterInterviewNew))
+	router.Handler(http.MethodPost, "/recruiter/candidates/:id/interview", recruiterOnly.ThenFunc(app.recruiterInterviewNewPost))
+	router.Handler(http.MethodPost, "/recruiter/blind-review", recruiterOnly.ThenFunc(app.recruiterBlindReviewPost))
+	router.Handler(http.MethodGet, "/recruiter/searches", recruiterOnly.ThenFunc(app.recruiterSearches))
+	router.Handler(http.MethodPost, "/recruiter/searches", recruiterOnly.ThenFunc(app.recruiterSearchCreatePost))
+	router.Handler(http.MethodPost, "/recruiter/searches/:id", recruiterOnly.ThenFunc(app.recruiterSearchUpdatePost))
+	router.Handler(http.MethodPost, "/recruiter/searches/:id/delete", recruiterOnly.ThenFunc(app.recruiterSearchDeletePost))
+	router.Handler(http.MethodPost, "/recruiter/digest-filter", recruiterOnly.ThenFunc(app.recruiterDigestFilterPost))
+	router.Handler(http.MethodGet, "/recruiter/compare", recruiterOnly.ThenFunc(app.recruiterCompare))
+	router.Handler(http.MethodGet, "/recruiter/jobs", recruiterOnly.ThenFunc(app.recruiterJobs))
+	router.Handler(http.MethodPost, "/recruiter/jobs", recruiterOnly.ThenFunc(app.recruiterJobCreatePost))
+	router.Handler(http.MethodGet, "/recruiter/jobs/:id", recruiterOnly.ThenFunc(app.recruiterJobView))
+	router.Handler(http.MethodPost, "/recruiter/jobs/:id", recruiterOnly.ThenFunc(app.recruiterJobUpdatePost))
+	router.Handler(http.MethodGet, "/recruiter/jobs/:id/applications/:application", recruiterOnly.ThenFunc(app.recruiterApplicationView))
+	router.Handler(http.MethodPost, "/recruiter/jobs/:id/applications/:application/stage", recruiterOnly.ThenFunc(app.recruiterApplicationStagePost))
+	router.Handler(http.MethodGet, "/recruiter/organisation", recruiterOnly.ThenFunc(app.recruiterOrganisation))
+	router.Handler(http.MethodPost, "/recruiter/organisation", recruiterOnly.ThenFunc(app.recruiterOrganisationCreatePost))
+	router.Handler(http.MethodPost, "/recruiter/organisation/domain", recruiterOnly.ThenFunc(app.recruiterOrganisationDomainPost))
+	router.Handler(http.MethodPost, "/recruiter/organisation/invites", recruiterOnly.ThenFunc(app.recruiterOrganisationInvitePost))
+	router.Handler(http.MethodPost, "/recruiter/organisation/invites/:id/revoke", recruiterOnly.ThenFunc(app.recruiterOrganisationInviteRevokePost))
+	router.Handler(http.MethodPost, "/recruiter/organisation/invites/:id/accept", recruiterOnly.ThenFunc(app.recruiterOrganisationInviteAcceptPost))
+	router.Handler(http.MethodPost, "/recruiter/organisation/invites/:id/decline", recruiterOnly.ThenFunc(app.recruiterOrganisationInviteDeclinePost))
+	router.Handler(http.MethodPost, "/recruiter/organisation/members/:id/role", recruiterOnly.ThenFunc(app.recruiterOrganisationRolePost))
+	router.Handler(http.MethodPost, "/recruiter/organisation/members/:id/remove", recruiterOnly.ThenFunc(app.recruiterOrganisationRemovePost))
+	router.Handler(http.MethodPost, "/recruiter/organisation/leave", recruiterOnly.ThenFunc(app.recruiterOrganisationLeavePost))
+	router.Handler(http.MethodPost, "/recruiter/organisation/delete", recruiterOnly.ThenFunc(app.recruiterOrganisationDeletePost))
+	router.Handler(http.MethodGet, "/recruiter/shortlists", recruiterOnly.ThenFunc(app.recruiterShortlists))
+	router.Handler(http.MethodPost, "/recruiter/shortlists", recruiterOnly.ThenFunc(app.recruiterShortlistCreatePost))
+	router.Handler(http.MethodGet, "/recruiter/shortlists/:id", recruiterOnly.ThenFunc(app.recruiterShortlistView))
+	router.Handler(http.MethodPost, "/recruiter/shortlists/:id", recruiterOnly.ThenFunc(app.recruiterShortlistUpdatePost))
+	router.Handler(http.MethodPost, "/recruiter/shortlists/:id/delete", recruiterOnly.ThenFunc(app.recruiterShortlistDeletePost))
+	router.Handler(http.MethodPost, "/recruiter/shortlists/:id/remove/:candidate", recruiterOnly.ThenFunc(app.recruiterShortlistRemovePost))
+
+	// ==================== ADMIN ROUTES ====================
+	router.Handler(http.MethodGet, "/admin/users", adminOnly.ThenFunc(app.adminUsers))
+	router.Handler(http.MethodGet, "/admin/users/:id", adminOnly.ThenFunc(app.adminUserView))
+	router.Handler(http.MethodPost, "/admin/users/:id/deactivate", adminOnly.ThenFunc(app.adminDeactivateUserPost))
+	router.Handler(http.MethodPost, "/admin/users/:id/reactivate", adminOnly.ThenFunc(app.adminReactivateUserPost))
+	router.Handler(http.MethodPost, "/admin/users/:id/verify-email", adminOnly.ThenFunc(app.adminVerifyEmailPost))
+	router.Handler(http.MethodPost, "/admin/users/:id/role", adminOnly.ThenFunc(app.adminChangeRolePost))
+	router.Handler(http.MethodPost, "/admin/users/:id/password-reset", adminOnly.ThenFunc(app.adminPasswordResetPost))
+	router.Handler(http.MethodGet, "/admin/verifications", adminOnly.ThenFunc(app.adminVerifications))
+	router.Handler(http.MethodGet, "/admin/verifications/:id", adminOnly.ThenFunc(app.adminVerificationView))
+	router.Handler(http.MethodGet, "/admin/verifications/:id/document", adminOnly.ThenFunc(app.adminVerificationDocument))
+	router.Handler(http.MethodPost, "/admin/verifications/:id/approve", adminOnly.ThenFunc(app.adminVerificationApprovePost))
+	router.Handler(http.MethodPost, "/admin/verifications/:id/reject", adminOnly.ThenFunc(app.adminVerificationRejectPost))
+	router.Handler(http.MethodGet, "/admin/organisations", adminOnly.ThenFunc(app.adminOrganisations))
+	router.Handler(http.MethodPost, "/admin/organisations/:id/domain", adminOnly.ThenFunc(app.adminOrganisationDomainPost))
+	router.Handler(http.MethodGet, "/admin/audit", adminOnly.ThenFunc(app.adminAuditLog))
+	router.Handler(http.MethodGet, "/admin/reports", adminOnly.ThenFunc(app.adminReports))
+	router.Handler(http.MethodGet, "/admin/conversations/:id", adminOnly.ThenFunc(app.adminConversationView))
+
+	// ==================== MESSAGING ROUTES (Recruiters, Students & Lawyers) ====================
+	router.Handler(http.MethodGet, "/messages", messagingAccess.ThenFunc(app.messages))
+	router.Handler(http.MethodGet, "/messages/:id", messagingAccess.ThenFunc(app.conversationView))
+	router.Handler(http.MethodPost, "/messages/:id", messagingAccess.ThenFunc(app.conversationReplyPost))
+	router.Handler(http.MethodPost, "/messages/:id/accept", messagingAccess.ThenFunc(app.conversationAcceptPost))
+	router.Handler(http.MethodPost, "/messages/:id/decline", messagingAccess.ThenFunc(app.conversationDeclinePost))
+	router.Handler(http.MethodPost, "/messages/:id/block", messagingAccess.ThenFunc(app.conversationBlockPost))
+	router.Handler(http.MethodPost, "/messages/:id/unblock", messagingAccess.ThenFunc(app.conversationUnblockPost))
+	router.Handler(http.MethodPost, "/messages/:id/report", messagingAccess.ThenFunc(app.conversationReportPost))
+
+	// ==================== INTERVIEW ROUTES (Recruiters, Students & Lawyers) ====================
+	router.Handler(http.MethodGet, "/interviews", messagingAccess.ThenFunc(app.interviews))
+	router.Handler(http.MethodGet, "/interviews/:id", messagingAccess.ThenFunc(app.interviewView))
+	router.Handler(http.MethodGet, "/interviews/:id/ics", messagingAccess.ThenFunc(app.interviewICS))
+	router.Handler(http.MethodPost, "/interviews/:id/accept", messagingAccess.ThenFunc(app.interviewAcceptPost))
+	router.Handler(http.MethodPost, "/interviews/:id/reschedule", messagingAccess.ThenFunc(app.interviewReschedulePost))
+	router.Handler(http.MethodPost, "/interviews/:id/cancel", messagingAccess.ThenFunc(app.interviewCancelPost))
 
 	// ==================== MOOT COURT ROUTES (Students & Lawyers) ====================
 	router.Handler(http.MethodGet, "/moot/setup", mootCourtAccess.ThenFunc(app.mootCourtSetup))
 	router.Handler(http.MethodGet, "/moot/session", mootCourtAccess.ThenFunc(app.mootCourtSession))
+	router.Handler(http.MethodGet, "/moot/schedule", mootCourtAccess.ThenFunc(app.mootSchedule))
+	router.Handler(http.MethodPost, "/moot/schedule", mootCourtAccess.ThenFunc(app.mootSchedulePost))
+	router.Handler(http.MethodGet, "/moot/sessions", mootCourtAccess.ThenFunc(app.moots))
+	router.Handler(http.MethodGet, "/moot/sessions/:id", mootCourtAccess.ThenFunc(app.mootView))
+	router.Handler(http.MethodGet, "/moot/sessions/:id/ics", mootCourtAccess.ThenFunc(app.mootICS))
+	router.Handler(http.MethodPost, "/moot/sessions/:id/rsvp", mootCourtAccess.ThenFunc(app.mootRSVPPost))
+	router.Handler(http.MethodPost, "/moot/sessions/:id/check-in", mootCourtAccess.ThenFunc(app.mootCheckInPost))
+	router.Handler(http.MethodPost, "/moot/sessions/:id/cancel", mootCourtAccess.ThenFunc(app.mootCancelPost))
+
+	// ==================== MENTORING ROUTES (Students & Lawyers) ====================
+	router.Handler(http.MethodGet, "/mentors", mootCourtAccess.ThenFunc(app.mentors))
+	router.Handler(http.MethodGet, "/mentors/:id", mootCourtAccess.ThenFunc(app.mentorView))
+	router.Handler(http.MethodPost, "/mentors/:id/request", studentOnly.ThenFunc(app.mentorRequestPost))
+	router.Handler(http.MethodGet, "/mentoring", mootCourtAccess.ThenFunc(app.mentoring))
+	router.Handler(http.MethodGet, "/mentoring/:id", mootCourtAccess.ThenFunc(app.mentoringView))
+	router.Handler(http.MethodPost, "/mentoring/:id/accept", mentorOnly.ThenFunc(app.mentoringAcceptPost))
+	router.Handler(http.MethodPost, "/mentoring/:id/decline", mentorOnly.ThenFunc(app.mentoringDeclinePost))
+	router.Handler(http.MethodPost, "/mentoring/:id/cancel", mootCourtAccess.ThenFunc(app.mentoringCancelPost))
+	router.Handler(http.MethodPost, "/mentoring/:id/review", mootCourtAccess.ThenFunc(app.mentoringReviewPost))
+
+	// ==================== JSON API ROUTES ====================
+	router.Handler(http.MethodGet, "/api/user/me", api.Append(app.requireScope(models.ScopeUserRead)).ThenFunc(app.apiUserMe))
+	router.Handler(http.MethodGet, "/api/candidates", api.Append(app.requireScope(models.ScopeCandidatesRead), app.requireAPIRole(models.RoleRecruiter)).ThenFunc(app.apiCandidates))
+	router.Handler(http.MethodGet, "/api/candidates/compare", api.Append(app.requireScope(models.ScopeCandidatesRead), app.requireAPIRole(models.RoleRecruiter)).ThenFunc(app.apiCompareCandidates))
+	router.Handler(http.MethodGet, "/api/student/progress", api.Append(app.requireScope(models.ScopeProgressRead), app.requireAPIRole(models.RoleStudent)).ThenFunc(app.apiStudentProgress))
 
 	return dynamic.Then(router)
 }
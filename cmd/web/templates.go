@@ -1,8 +1,13 @@
 package main
 
 import (
+	"database/sql"
+	"fmt"
 	"html/template"
+	"math"
+	"net/url"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"lawbook/internal/models"
@@ -50,8 +55,21 @@ func newTemplateCache() (map[string]*template.Template, error) {
 
 // Template functions available in templates
 var functions = template.FuncMap{
-	"humanDate":   humanDate,
-	"roleDisplay": roleDisplay,
+	"humanDate":          humanDate,
+	"shortDate":          shortDate,
+	"zonedDate":          zonedDate,
+	"roleDisplay":        roleDisplay,
+	"deviceName":         deviceName,
+	"score":              score,
+	"trend":              trend,
+	"percent":            percent,
+	"scoreWidth":         scoreWidth,
+	"searchSummary":      searchSummary,
+	"candidateSearchURL": candidateSearchURL,
+	"frequencyDisplay":   frequencyDisplay,
+	"mootRoleDisplay":    mootRoleDisplay,
+	"mootTitle":          mootTitle,
+	"caseTypeLabel":      caseTypeLabel,
 }
 
 // humanDate returns a nicely formatted string representation of a time.Time
@@ -62,6 +80,27 @@ func humanDate(t time.Time) string {
 	return t.Format("02 Jan 2006 at 15:04")
 }
 
+// shortDate formats a date without its time of day
+func shortDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("02 Jan 2006")
+}
+
+// zonedDate formats a time in the named time zone, such as
+// "Mon 02 Jan 2006 at 15:04 IST". Unknown zones show the time in UTC.
+func zonedDate(t time.Time, zone string) string {
+	if t.IsZero() {
+		return ""
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("Mon 02 Jan 2006 at 15:04 MST")
+}
+
 // roleDisplay returns a human-readable version of the role
 func roleDisplay(role models.UserRole) string {
 	switch role {
@@ -71,7 +110,168 @@ func roleDisplay(role models.UserRole) string {
 		return "Lawyer"
 	case models.RoleRecruiter:
 		return "Recruiter"
+	case models.RoleAdmin:
+		return "Administrator"
 	default:
 		return string(role)
 	}
 }
+
+// mootRoleDisplay returns a human-readable version of a moot role
+func mootRoleDisplay(role models.MootRole) string {
+	switch role {
+	case models.MootAppellant:
+		return "Appellant Counsel"
+	case models.MootRespondent:
+		return "Respondent Counsel"
+	case models.MootJudge:
+		return "Judge"
+	default:
+		return string(role)
+	}
+}
+
+// sessionTypeDisplay describes who takes part in a moot
+func sessionTypeDisplay(sessionType string) string {
+	switch sessionType {
+	case "single_player":
+		return "solo"
+	case "dual_player":
+		return "two players"
+	case "trio":
+		return "trio"
+	default:
+		return sessionType
+	}
+}
+
+// frequencyDisplay describes how often a saved search is checked
+func frequencyDisplay(f models.SearchFrequency) string {
+	switch f {
+	case models.SearchDaily:
+		return "Daily"
+	case models.SearchWeekly:
+		return "Weekly"
+	case models.SearchOff:
+		return "Off"
+	default:
+		return string(f)
+	}
+}
+
+// deviceName summarises a User-Agent header as "Browser on OS"
+func deviceName(userAgent string) string {
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	case strings.Contains(userAgent, "curl/"):
+		browser = "curl"
+	}
+
+	platform := "unknown OS"
+	switch {
+	case strings.Contains(userAgent, "Android"):
+		platform = "Android"
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		platform = "iOS"
+	case strings.Contains(userAgent, "Windows"):
+		platform = "Windows"
+	case strings.Contains(userAgent, "Mac OS X"):
+		platform = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		platform = "Linux"
+	}
+
+	return browser + " on " + platform
+}
+
+// score formats an evaluation score, or a dash when there isn't one
+func score(n sql.NullFloat64) string {
+	if !n.Valid {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", n.Float64)
+}
+
+// trend formats a change in score with its direction, such as "▲ 2.5"
+func trend(n sql.NullFloat64) string {
+	switch {
+	case !n.Valid:
+		return ""
+	case n.Float64 >= 0.05:
+		return fmt.Sprintf("▲ %.1f", n.Float64)
+	case n.Float64 <= -0.05:
+		return fmt.Sprintf("▼ %.1f", -n.Float64)
+	default:
+		return "No change"
+	}
+}
+
+// percent returns n as a whole percentage of total, or 0 if total is zero
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return n * 100 / total
+}
+
+// scoreWidth returns a score as a percentage of outOf, clamped to 0-100, for
+// sizing bar charts. Missing scores are 0.
+func scoreWidth(n sql.NullFloat64, outOf float64) int {
+	if !n.Valid || outOf <= 0 {
+		return 0
+	}
+	return int(math.Round(math.Max(0, math.Min(100, n.Float64*100/outOf))))
+}
+
+// searchFilterLabels names the candidate search parameters, in the order
+// they appear on the search page
+var searchFilterLabels = []struct{ key, label string }{
+	{"role", "Type"},
+	{"specialization", "Specialization"},
+	{"university", "University"},
+	{"area", "Area of law"},
+	{"min_experience", "Min. experience"},
+	{"max_experience", "Max. experience"},
+	{"min_score", "Min. score"},
+	{"max_score", "Max. score"},
+	{"min_legal_knowledge", "Min. legal knowledge"},
+	{"min_argumentation", "Min. argumentation"},
+	{"min_presentation", "Min. presentation"},
+	{"min_response_quality", "Min. response quality"},
+}
+
+// searchSummary describes a saved candidate search query, such as
+// "Area of law: Criminal · Min. score: 7"
+func searchSummary(query string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return query
+	}
+
+	var parts []string
+	for _, f := range searchFilterLabels {
+		v := strings.TrimSpace(values.Get(f.key))
+		if v == "" || v == "0" {
+			continue
+		}
+		parts = append(parts, f.label+": "+v)
+	}
+	if values.Get("verified") == "true" {
+		parts = append(parts, "Verified lawyers only")
+	}
+
+	if len(parts) == 0 {
+		return "All candidates"
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,160 @@
+// Package achievements defines the badges students and lawyers earn by
+// practising moots, and decides which of them a moot history has earned.
+//
+// Each badge is a Rule made of declarative conditions, so adding one is a
+// matter of adding an entry to Rules. Codes are stored against the users who
+// earn them and must never change once released.
+package achievements
+
+import (
+	"time"
+
+	"lawbook/internal/models"
+	"lawbook/internal/stats"
+)
+
+// Rule is a badge and the conditions for earning it. Every condition that is
+// set must hold.
+type Rule struct {
+	Code        string
+	Name        string
+	Description string
+
+	// Sessions is the number of completed sessions needed, counting only
+	// sessions at Difficulty when it is set
+	Sessions   int
+	Difficulty string
+
+	// Skill and MinScore need at least one session scoring MinScore or more
+	// for Skill
+	Skill    stats.Skill
+	MinScore float64
+
+	// StreakDays needs sessions on this many consecutive days
+	StreakDays int
+}
+
+// Rules lists every badge in the order they are shown
+var Rules = []*Rule{
+	{
+		Code:        "first-moot",
+		Name:        "First Moot",
+		Description: "Complete your first moot court session",
+		Sessions:    1,
+	},
+	{
+		Code:        "ten-sessions",
+		Name:        "Regular Counsel",
+		Description: "Complete ten moot court sessions",
+		Sessions:    10,
+	},
+	{
+		Code:        "five-hard",
+		Name:        "Hard Cases",
+		Description: "Complete five sessions on hard difficulty",
+		Sessions:    5,
+		Difficulty:  "hard",
+	},
+	{
+		Code:        "sharp-argument",
+		Name:        "Sharp Argument",
+		Description: "Score 80 or more for argumentation",
+		Skill:       stats.SkillArgumentation,
+		MinScore:    80,
+	},
+	{
+		Code:        "three-day-streak",
+		Name:        "On a Roll",
+		Description: "Practise on three days in a row",
+		StreakDays:  3,
+	},
+	{
+		Code:        "seven-day-streak",
+		Name:        "Seven-Day Streak",
+		Description: "Practise on seven days in a row",
+		StreakDays:  7,
+	},
+}
+
+// Met reports whether a candidate's evaluations satisfy the rule
+func (r *Rule) Met(evaluations []*models.Evaluation) bool {
+	if r.Sessions > 0 {
+		n := 0
+		for _, e := range evaluations {
+			if r.Difficulty == "" || e.Difficulty == r.Difficulty {
+				n++
+			}
+		}
+		if n < r.Sessions {
+			return false
+		}
+	}
+
+	if r.Skill != "" {
+		met := false
+		for _, e := range evaluations {
+			if score := r.Skill.Score(e); score.Valid && score.Float64 >= r.MinScore {
+				met = true
+				break
+			}
+		}
+		if !met {
+			return false
+		}
+	}
+
+	if r.StreakDays > 0 {
+		times := make([]time.Time, len(evaluations))
+		for i, e := range evaluations {
+			times[i] = e.CreatedAt
+		}
+		if stats.LongestStreak(times) < r.StreakDays {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Earned returns the rules satisfied by a candidate's evaluations
+func Earned(evaluations []*models.Evaluation) []*Rule {
+	var earned []*Rule
+	for _, r := range Rules {
+		if r.Met(evaluations) {
+			earned = append(earned, r)
+		}
+	}
+	return earned
+}
+
+// Badge is a rule as shown to a user, with whether and when they earned it
+type Badge struct {
+	*Rule
+	Earned   bool
+	EarnedAt time.Time
+
+	// New is set until the user has seen the badge on their dashboard
+	New bool
+}
+
+// Badges returns a badge for every rule, marking the ones a user has earned.
+// Earned codes that no longer have a rule are left out.
+func Badges(earned []*models.Achievement) []*Badge {
+	byCode := make(map[string]*models.Achievement, len(earned))
+	for _, a := range earned {
+		byCode[a.Code] = a
+	}
+
+	badges := make([]*Badge, 0, len(Rules))
+	for _, r := range Rules {
+		b := &Badge{Rule: r}
+		if a, ok := byCode[r.Code]; ok {
+			b.Earned = true
+			b.EarnedAt = a.EarnedAt
+			b.New = !a.SeenAt.Valid
+		}
+		badges = append(badges, b)
+	}
+
+	return badges
+}
@@ -0,0 +1,152 @@
+// Package ical writes iCalendar (RFC 5545) files, both invitations sent by
+// email and the feeds calendar applications subscribe to.
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ContentType is the MIME type of an iCalendar file
+const ContentType = "text/calendar; charset=utf-8"
+
+// Methods for calendars sent by email (RFC 5546). Feeds have no method.
+const (
+	MethodRequest = "REQUEST"
+	MethodCancel  = "CANCEL"
+)
+
+// Event statuses
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// Person is an event's organiser or attendee
+type Person struct {
+	Name  string
+	Email string
+}
+
+// Event is a calendar event. Times are converted to UTC, so calendar
+// applications show them in each user's own time zone.
+type Event struct {
+	// UID identifies the event across updates, which are distinguished by
+	// an increasing Sequence
+	UID      string
+	Sequence int
+
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Status      string
+
+	Organizer Person
+	Attendees []Person
+}
+
+// Calendar is a set of events
+type Calendar struct {
+	// Method is MethodRequest or MethodCancel for invitations and empty for
+	// feeds
+	Method string
+
+	// Name is the name calendar applications give a subscribed feed
+	Name string
+
+	Events []Event
+}
+
+// Bytes renders the calendar. Every event is stamped with now.
+func (c *Calendar) Bytes(now time.Time) []byte {
+	w := &writer{}
+
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//Lawbook//Lawbook//EN")
+	w.line("CALSCALE:GREGORIAN")
+	if c.Method != "" {
+		w.line("METHOD:" + c.Method)
+	}
+	if c.Name != "" {
+		w.line("X-WR-CALNAME:" + escape(c.Name))
+	}
+
+	for _, e := range c.Events {
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + escape(e.UID))
+		w.line("SEQUENCE:" + strconv.Itoa(e.Sequence))
+		w.line("DTSTAMP:" + utc(now))
+		w.line("DTSTART:" + utc(e.Start))
+		w.line("DTEND:" + utc(e.End))
+		w.line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" {
+			w.line("DESCRIPTION:" + escape(e.Description))
+		}
+		if e.Location != "" {
+			w.line("LOCATION:" + escape(e.Location))
+		}
+		if e.URL != "" {
+			w.line("URL:" + e.URL)
+		}
+		if e.Status != "" {
+			w.line("STATUS:" + e.Status)
+		}
+		if e.Organizer.Email != "" {
+			w.line("ORGANIZER;CN=" + param(e.Organizer.Name) + ":mailto:" + e.Organizer.Email)
+		}
+		for _, a := range e.Attendees {
+			w.line("ATTENDEE;CN=" + param(a.Name) + ";ROLE=REQ-PARTICIPANT:mailto:" + a.Email)
+		}
+		w.line("END:VEVENT")
+	}
+
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+// writer folds content lines longer than 75 octets, as RFC 5545 requires,
+// without splitting UTF-8 characters
+type writer struct {
+	buf bytes.Buffer
+}
+
+func (w *writer) line(s string) {
+	// Continuation lines start with a space, which counts towards the limit
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
+
+// escaper escapes TEXT values
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// param quotes a parameter value, which may not contain double quotes
+func param(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "'") + `"`
+}
+
+func utc(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
@@ -0,0 +1,192 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule reports when a job should next run
+type Schedule interface {
+	// Next returns the first activation time strictly after t
+	Next(t time.Time) time.Time
+}
+
+// Parse parses a schedule specification. It accepts the standard five-field
+// cron syntax ("minute hour day-of-month month day-of-week", with *, lists,
+// ranges and steps), the shortcuts @hourly, @daily, @weekly and @monthly,
+// and "@every <duration>" for fixed intervals such as "@every 15m".
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	switch spec {
+	case "@hourly":
+		spec = "0 * * * *"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("jobs: invalid interval in %q: %w", spec, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("jobs: interval in %q must be at least one second", spec)
+		}
+		return everySchedule{interval: d}, nil
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("jobs: expected 5 fields in %q, found %d", spec, len(fields))
+	}
+
+	var s cronSchedule
+	var err error
+
+	if s.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+
+	// Both 0 and 7 mean Sunday
+	if s.dow.bits&(1<<7) != 0 {
+		s.dow.bits |= 1
+	}
+
+	return s, nil
+}
+
+// everySchedule runs at a fixed interval
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// field is the set of permitted values for one cron field
+type field struct {
+	bits uint64
+	any  bool // true if the field was "*"
+}
+
+func (f field) has(v int) bool {
+	return f.bits&(1<<uint(v)) != 0
+}
+
+// cronSchedule is a parsed five-field cron expression
+type cronSchedule struct {
+	minute, hour, dom, month, dow field
+}
+
+// dayMatches applies the cron rule that when both day fields are
+// restricted, a day matching either of them is accepted
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	domOK := s.dom.has(t.Day())
+	dowOK := s.dow.has(int(t.Weekday()))
+
+	if s.dom.any || s.dow.any {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+func (s cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// Give up after five years; an expression like "0 0 30 2 *" never matches
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !s.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hour.has(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minute.has(t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// parseField parses one comma-separated cron field such as "*/15" or "1-5,10"
+func parseField(expr string, min, max int) (field, error) {
+	var f field
+
+	for _, part := range strings.Split(expr, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return field{}, fmt.Errorf("jobs: invalid step in %q", expr)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+		switch {
+		case part == "*":
+			if step == 1 {
+				f.any = true
+			}
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(bounds[0])
+			hi, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return field{}, fmt.Errorf("jobs: invalid range in %q", expr)
+			}
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return field{}, fmt.Errorf("jobs: invalid value in %q", expr)
+			}
+			lo = n
+			if step == 1 {
+				hi = n
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return field{}, fmt.Errorf("jobs: %q is out of range %d-%d", expr, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			f.bits |= 1 << uint(v)
+		}
+	}
+
+	return f, nil
+}
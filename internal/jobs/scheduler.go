@@ -0,0 +1,204 @@
+// Package jobs runs recurring background work on cron-like schedules.
+//
+// Every run takes a MySQL named lock first, so when several application
+// instances share a database only one of them runs a given job at a time.
+// Each run that acquires the lock is recorded in the job_runs table.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Func is the work performed by a job
+type Func func(ctx context.Context) error
+
+// RunRecorder stores job run history
+type RunRecorder interface {
+	Start(jobName, instance string) (int, error)
+	Finish(id int, runErr error) error
+}
+
+type job struct {
+	name     string
+	spec     string
+	schedule Schedule
+	fn       Func
+}
+
+// Scheduler runs registered jobs on their schedules until stopped
+type Scheduler struct {
+	db       *sql.DB
+	runs     RunRecorder
+	infoLog  *log.Logger
+	errorLog *log.Logger
+	instance string
+
+	jobs   []*job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New returns a scheduler that locks on db and records runs with runs
+func New(db *sql.DB, runs RunRecorder, infoLog, errorLog *log.Logger) *Scheduler {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return &Scheduler{
+		db:       db,
+		runs:     runs,
+		infoLog:  infoLog,
+		errorLog: errorLog,
+		instance: fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+	}
+}
+
+// Add registers a job. It must be called before Start.
+func (s *Scheduler) Add(name, spec string, fn Func) error {
+	// MySQL limits lock names to 64 characters
+	if len(lockName(name)) > 64 {
+		return fmt.Errorf("jobs: job name %q is too long", name)
+	}
+
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	s.jobs = append(s.jobs, &job{name: name, spec: spec, schedule: schedule, fn: fn})
+	return nil
+}
+
+// Start begins running jobs in the background
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+
+	s.infoLog.Printf("Started job scheduler with %d jobs on %s", len(s.jobs), s.instance)
+}
+
+// Stop cancels any running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// loop waits for each activation of a job and runs it. Because the next
+// activation is computed after the previous run returns, a slow job never
+// overlaps with itself within one instance.
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.errorLog.Printf("job %s: schedule %q never fires", j.name, j.spec)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.run(ctx, j)
+		}
+	}
+}
+
+// run executes one activation of a job if this instance can take its lock
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	conn, locked, err := s.lock(ctx, j.name)
+	if err != nil {
+		s.errorLog.Printf("job %s: acquiring lock: %s", j.name, err)
+		return
+	}
+	if !locked {
+		// Another instance is already running this job
+		return
+	}
+	defer s.unlock(conn, j.name)
+
+	runID, err := s.runs.Start(j.name, s.instance)
+	if err != nil {
+		s.errorLog.Printf("job %s: recording start: %s", j.name, err)
+		return
+	}
+
+	started := time.Now()
+	runErr := s.call(ctx, j)
+
+	if runErr != nil {
+		s.errorLog.Printf("job %s failed after %s: %s", j.name, time.Since(started).Round(time.Millisecond), runErr)
+	} else {
+		s.infoLog.Printf("job %s succeeded in %s", j.name, time.Since(started).Round(time.Millisecond))
+	}
+
+	err = s.runs.Finish(runID, runErr)
+	if err != nil {
+		s.errorLog.Printf("job %s: recording finish: %s", j.name, err)
+	}
+}
+
+// call invokes the job function, converting a panic into an error
+func (s *Scheduler) call(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	return j.fn(ctx)
+}
+
+// lock tries to take the job's named lock without waiting. MySQL named locks
+// belong to a connection, so the connection is held until unlock.
+func (s *Scheduler) lock(ctx context.Context, name string) (*sql.Conn, bool, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var acquired sql.NullInt64
+	err = conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, 0)`, lockName(name)).Scan(&acquired)
+	if err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+
+	if !acquired.Valid || acquired.Int64 != 1 {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	return conn, true, nil
+}
+
+func (s *Scheduler) unlock(conn *sql.Conn, name string) {
+	defer conn.Close()
+
+	_, err := conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK(?)`, lockName(name))
+	if err != nil {
+		s.errorLog.Printf("job %s: releasing lock: %s", name, err)
+	}
+}
+
+func lockName(jobName string) string {
+	return "lawbook.job." + jobName
+}
@@ -0,0 +1,238 @@
+// Package mailer builds and delivers transactional email.
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Message is an email ready to be sent
+type Message struct {
+	To        string
+	Subject   string
+	PlainBody string
+	HTMLBody  string
+	// Headers holds extra headers such as List-Unsubscribe
+	Headers map[string]string
+
+	Attachments []Attachment
+}
+
+// Attachment is a file sent with a message, such as a calendar invite
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Mailer delivers email messages. Implementations must be safe for
+// concurrent use.
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// SMTP delivers mail through an SMTP server
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Sender   string
+}
+
+// Send delivers msg using STARTTLS when the server supports it
+func (m *SMTP) Send(msg *Message) error {
+	body, err := m.build(msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	from, err := mailAddress(m.Sender)
+	if err != nil {
+		return err
+	}
+
+	addr := m.Host + ":" + strconv.Itoa(m.Port)
+	return smtp.SendMail(addr, auth, from, []string{msg.To}, body)
+}
+
+// build renders msg as a multipart/alternative MIME message, wrapped in
+// multipart/mixed when it has attachments
+func (m *SMTP) build(msg *Message) ([]byte, error) {
+	var body bytes.Buffer
+	contentType, err := writeAlternative(&body, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msg.Attachments) > 0 {
+		var mixed bytes.Buffer
+		contentType, err = writeMixed(&mixed, contentType, body.Bytes(), msg.Attachments)
+		if err != nil {
+			return nil, err
+		}
+		body = mixed
+	}
+
+	headers := map[string]string{
+		"From":         m.Sender,
+		"To":           msg.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-ID":   messageID(m.Host),
+		"MIME-Version": "1.0",
+		"Content-Type": contentType,
+	}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var head bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&head, "%s: %s\r\n", k, headers[k])
+	}
+	head.WriteString("\r\n")
+
+	return append(head.Bytes(), body.Bytes()...), nil
+}
+
+// writeAlternative writes the plain-text and HTML bodies of msg as
+// multipart/alternative parts and returns the content type describing them
+func writeAlternative(w io.Writer, msg *Message) (string, error) {
+	mw := multipart.NewWriter(w)
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", msg.PlainBody},
+		{"text/html; charset=utf-8", msg.HTMLBody},
+	}
+
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return "", err
+		}
+
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return "", err
+		}
+		if err := qp.Close(); err != nil {
+			return "", err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	return "multipart/alternative; boundary=" + mw.Boundary(), nil
+}
+
+// writeMixed writes an already rendered body followed by base64-encoded
+// attachments as multipart/mixed parts and returns the content type
+// describing them
+func writeMixed(w io.Writer, bodyType string, body []byte, attachments []Attachment) (string, error) {
+	mw := multipart.NewWriter(w)
+
+	pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {bodyType}})
+	if err != nil {
+		return "", err
+	}
+	if _, err := pw.Write(body); err != nil {
+		return "", err
+	}
+
+	for _, a := range attachments {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return "", err
+		}
+
+		// RFC 2045 limits encoded lines to 76 characters
+		encoded := base64.StdEncoding.EncodeToString(a.Data)
+		for len(encoded) > 76 {
+			if _, err := io.WriteString(pw, encoded[:76]+"\r\n"); err != nil {
+				return "", err
+			}
+			encoded = encoded[76:]
+		}
+		if _, err := io.WriteString(pw, encoded+"\r\n"); err != nil {
+			return "", err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	return "multipart/mixed; boundary=" + mw.Boundary(), nil
+}
+
+// Log writes messages to a logger instead of sending them. It is used in
+// development when no SMTP server is configured.
+type Log struct {
+	Logger *log.Logger
+}
+
+// Send logs the recipient, subject, plain-text body and attachment names of
+// msg
+func (m *Log) Send(msg *Message) error {
+	m.Logger.Printf("email to %s: %q\n%s", msg.To, msg.Subject, msg.PlainBody)
+	for _, a := range msg.Attachments {
+		m.Logger.Printf("attachment %s (%s, %d bytes)", a.Filename, a.ContentType, len(a.Data))
+	}
+	return nil
+}
+
+func mailAddress(sender string) (string, error) {
+	addr, err := mail.ParseAddress(sender)
+	if err != nil {
+		return "", fmt.Errorf("mailer: invalid sender %q: %w", sender, err)
+	}
+	return addr.Address, nil
+}
+
+func messageID(host string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + host + ">"
+}
@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Render builds a message from an email template file in dir. The file must
+// define "subject" and "plainBody" templates and may define "htmlBody".
+func Render(dir, name, recipient string, data any) (*Message, error) {
+	path := filepath.Join(dir, name)
+
+	tmpl, err := template.New(name).ParseFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	plainBody := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(plainBody, "plainBody", data); err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		To:        recipient,
+		Subject:   strings.TrimSpace(subject.String()),
+		PlainBody: plainBody.String(),
+	}
+
+	// The HTML part is parsed separately with html/template so that data
+	// is escaped correctly
+	htmlTmpl, err := htmltemplate.New(name).ParseFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if htmlTmpl.Lookup("htmlBody") != nil {
+		htmlBody := new(bytes.Buffer)
+		if err := htmlTmpl.ExecuteTemplate(htmlBody, "htmlBody", data); err != nil {
+			return nil, err
+		}
+		msg.HTMLBody = htmlBody.String()
+	}
+
+	return msg, nil
+}
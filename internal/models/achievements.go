@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Achievement records that a user earned the badge with the given code
+type Achievement struct {
+	UserID   int
+	Code     string
+	EarnedAt time.Time
+	SeenAt   sql.NullTime
+}
+
+// AchievementModel wraps a database connection pool
+type AchievementModel struct {
+	DB *sql.DB
+}
+
+// Award records that a user has earned an achievement. It reports false if
+// they already had it.
+func (m *AchievementModel) Award(userID int, code string) (bool, error) {
+	stmt := `INSERT IGNORE INTO achievements (user_id, code, earned_at) VALUES (?, ?, UTC_TIMESTAMP())`
+
+	result, err := m.DB.Exec(stmt, userID, code)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// ForUser returns a user's achievements, most recent first
+func (m *AchievementModel) ForUser(userID int) ([]*Achievement, error) {
+	stmt := `SELECT user_id, code, earned_at, seen_at FROM achievements
+		WHERE user_id = ?
+		ORDER BY earned_at DESC, code`
+
+	rows, err := m.DB.Query(stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var achievements []*Achievement
+
+	for rows.Next() {
+		var a Achievement
+		err = rows.Scan(&a.UserID, &a.Code, &a.EarnedAt, &a.SeenAt)
+		if err != nil {
+			return nil, err
+		}
+		achievements = append(achievements, &a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return achievements, nil
+}
+
+// MarkSeen records that a user has seen all of their achievements
+func (m *AchievementModel) MarkSeen(userID int) error {
+	stmt := `UPDATE achievements SET seen_at = UTC_TIMESTAMP() WHERE user_id = ? AND seen_at IS NULL`
+
+	_, err := m.DB.Exec(stmt, userID)
+	return err
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Admin actions recorded in the audit log
+const (
+	AuditDeactivateUser = "deactivate_user"
+	AuditReactivateUser = "reactivate_user"
+	AuditVerifyEmail    = "verify_email"
+	AuditChangeRole     = "change_role"
+	AuditPasswordReset  = "password_reset"
+	AuditApproveLawyer  = "approve_lawyer"
+	AuditRejectLawyer   = "reject_lawyer"
+	AuditVerifyDomain   = "verify_domain"
+	AuditUnverifyDomain = "unverify_domain"
+)
+
+// AuditEntry is one action taken by an administrator
+type AuditEntry struct {
+	ID           int
+	AdminID      sql.NullInt64
+	AdminName    sql.NullString
+	Action       string
+	TargetUserID sql.NullInt64
+	TargetName   sql.NullString
+	Details      string
+	CreatedAt    time.Time
+}
+
+// AuditLogModel wraps a database connection pool
+type AuditLogModel struct {
+	DB *sql.DB
+}
+
+// Insert records an admin action. targetUserID may be zero when the action
+// does not concern a particular user.
+func (m *AuditLogModel) Insert(adminID int, action string, targetUserID int, details string) error {
+	var target sql.NullInt64
+	if targetUserID != 0 {
+		target = sql.NullInt64{Int64: int64(targetUserID), Valid: true}
+	}
+
+	stmt := `INSERT INTO admin_audit_log (admin_id, action, target_user_id, details)
+		VALUES (?, ?, ?, ?)`
+
+	_, err := m.DB.Exec(stmt, adminID, action, target, truncate(details, 500))
+	return err
+}
+
+// Latest returns a page of the audit log, newest first, and the total number of entries
+func (m *AuditLogModel) Latest(limit, offset int) ([]*AuditEntry, int, error) {
+	stmt := `SELECT COUNT(*) OVER(), l.id, l.admin_id, a.name, l.action, l.target_user_id, t.name, l.details, l.created_at
+		FROM admin_audit_log l
+		LEFT JOIN users a ON a.id = l.admin_id
+		LEFT JOIN users t ON t.id = l.target_user_id
+		ORDER BY l.created_at DESC, l.id DESC LIMIT ? OFFSET ?`
+
+	rows, err := m.DB.Query(stmt, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+	total := 0
+
+	for rows.Next() {
+		var e AuditEntry
+		err = rows.Scan(
+			&total,
+			&e.ID,
+			&e.AdminID,
+			&e.AdminName,
+			&e.Action,
+			&e.TargetUserID,
+			&e.TargetName,
+			&e.Details,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, &e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,276 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// CandidateSort is an ordering a recruiter can choose for search results
+type CandidateSort struct {
+	Key   string
+	Label string
+}
+
+// CandidateSorts lists the available orderings, the default first
+var CandidateSorts = []CandidateSort{
+	{"score", "Highest average score"},
+	{"sessions", "Most sessions"},
+	{"recent", "Recently active"},
+	{"experience", "Most experience"},
+	{"name", "Name"},
+}
+
+var candidateOrderBy = map[string]string{
+	"score":      "s.overall_score DESC, s.sessions DESC, u.id",
+	"sessions":   "s.sessions DESC, s.overall_score DESC, u.id",
+	"recent":     "s.last_session_at DESC, u.id",
+	"experience": "lp.years_of_experience DESC, s.overall_score DESC, u.id",
+	"name":       "u.name, u.id",
+}
+
+// CandidateFilter narrows a candidate search. Zero values leave a filter off.
+type CandidateFilter struct {
+	Role           UserRole
+	Specialization string
+	University     string
+	AreaOfLaw      string
+	MinExperience  int
+	MaxExperience  int
+	MinScore       float64
+	MaxScore       float64
+
+	// Minimum averages for the individual scoring dimensions
+	MinLegalKnowledge  float64
+	MinArgumentation   float64
+	MinPresentation    float64
+	MinResponseQuality float64
+
+	VerifiedOnly bool
+
+	// JoinedSince limits the search to candidates who signed up after it
+	JoinedSince time.Time
+
+	Sort string
+}
+
+// Candidate is a student or lawyer as shown to recruiters, with their
+// profile and averages over every evaluated moot session
+type Candidate struct {
+	ID                int
+	Name              string
+	Role              UserRole
+	University        string
+	YearOfStudy       int
+	Specialization    string
+	YearsOfExperience int
+	FirmName          string
+	Verified          bool
+	PortfolioSlug     sql.NullString
+
+	Sessions             int
+	OverallScore         sql.NullFloat64
+	LegalKnowledgeScore  sql.NullFloat64
+	ArgumentationScore   sql.NullFloat64
+	PresentationScore    sql.NullFloat64
+	ResponseQualityScore sql.NullFloat64
+	LastSessionAt        sql.NullTime
+	JoinedAt             time.Time
+
+	// Anonymised is set when blind review has replaced the candidate's name
+	// with a pseudonym and removed the details that would identify them
+	Anonymised bool
+}
+
+// CandidateModel wraps a database connection pool
+type CandidateModel struct {
+	DB *sql.DB
+}
+
+// candidateColumns and candidateFrom select every candidate visible to a
+// recruiter, with their profile, verification status and averages over the
+// evaluations they share with recruiters. A lawyer is verified while their
+// latest bar registration submission is approved. candidateFrom takes the
+// recruiter's ID as its first parameter.
+const candidateColumns = `u.id, u.name, u.role,
+	COALESCE(sp.university, ''), COALESCE(sp.year_of_study, 0),
+	COALESCE(sp.specialization, lp.specialization, ''),
+	COALESCE(lp.years_of_experience, 0), COALESCE(lp.firm_name, ''),
+	COALESCE(lv.status = 'approved', FALSE), po.slug,
+	COALESCE(s.sessions, 0), s.overall_score, s.legal_knowledge_score, s.argumentation_score,
+	s.presentation_score, s.response_quality_score, s.last_session_at, u.created_at`
+
+const candidateFrom = `FROM users u
+	LEFT JOIN student_profiles sp ON sp.user_id = u.id AND u.role = 'student'
+	LEFT JOIN lawyer_profiles lp ON lp.user_id = u.id AND u.role = 'lawyer'
+	LEFT JOIN lawyer_verifications lv ON lv.id = (
+		SELECT MAX(id) FROM lawyer_verifications WHERE user_id = u.id AND status = 'approved')
+	LEFT JOIN portfolios po ON po.user_id = u.id AND po.is_public = TRUE
+	LEFT JOIN (
+		SELECT user_id, COUNT(*) AS sessions, AVG(overall_score) AS overall_score,
+		AVG(legal_knowledge_score) AS legal_knowledge_score, AVG(argumentation_score) AS argumentation_score,
+		AVG(presentation_score) AS presentation_score, AVG(response_quality_score) AS response_quality_score,
+		MAX(created_at) AS last_session_at
+		FROM performance_evaluations WHERE share_with_recruiters = TRUE GROUP BY user_id
+	) s ON s.user_id = u.id
+	WHERE u.is_active = TRUE AND u.role IN ('student', 'lawyer') AND ` + recruiterConsent
+
+func scanCandidate(row rowScanner, extra ...any) (*Candidate, error) {
+	var c Candidate
+
+	dest := append(extra,
+		&c.ID,
+		&c.Name,
+		&c.Role,
+		&c.University,
+		&c.YearOfStudy,
+		&c.Specialization,
+		&c.YearsOfExperience,
+		&c.FirmName,
+		&c.Verified,
+		&c.PortfolioSlug,
+		&c.Sessions,
+		&c.OverallScore,
+		&c.LegalKnowledgeScore,
+		&c.ArgumentationScore,
+		&c.PresentationScore,
+		&c.ResponseQualityScore,
+		&c.LastSessionAt,
+		&c.JoinedAt,
+	)
+
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Search returns a page of candidates matching the filter and the total
+// number of matches. Only active students and lawyers who have agreed to be
+// seen by the recruiter are ever returned.
+func (m *CandidateModel) Search(recruiterID int, f CandidateFilter, limit, offset int) ([]*Candidate, int, error) {
+	orderBy, ok := candidateOrderBy[f.Sort]
+	if !ok {
+		orderBy = candidateOrderBy["score"]
+	}
+
+	stmt := `SELECT COUNT(*) OVER(), ` + candidateColumns + ` ` + candidateFrom + `
+		AND (? = '' OR u.role = ?)
+		AND (? = '' OR COALESCE(sp.specialization, lp.specialization) LIKE ?)
+		AND (? = '' OR sp.university LIKE ?)
+		AND (? = '' OR EXISTS (
+			SELECT 1 FROM performance_evaluations pe
+			JOIN moot_sessions ms ON ms.id = pe.session_id
+			WHERE pe.user_id = u.id AND pe.share_with_recruiters = TRUE AND ms.case_type = ?))
+		AND (? = 0 OR lp.years_of_experience >= ?)
+		AND (? = 0 OR lp.years_of_experience <= ?)
+		AND (? = 0 OR s.overall_score >= ?)
+		AND (? = 0 OR s.overall_score <= ?)
+		AND (? = 0 OR s.legal_knowledge_score >= ?)
+		AND (? = 0 OR s.argumentation_score >= ?)
+		AND (? = 0 OR s.presentation_score >= ?)
+		AND (? = 0 OR s.response_quality_score >= ?)
+		AND (? = FALSE OR lv.status = 'approved')
+		AND (? = FALSE OR u.created_at >= ?)
+		ORDER BY ` + orderBy + ` LIMIT ? OFFSET ?`
+
+	specialization := likePattern(f.Specialization)
+	university := likePattern(f.University)
+
+	rows, err := m.DB.Query(stmt,
+		recruiterID,
+		f.Role, f.Role,
+		f.Specialization, specialization,
+		f.University, university,
+		f.AreaOfLaw, f.AreaOfLaw,
+		f.MinExperience, f.MinExperience,
+		f.MaxExperience, f.MaxExperience,
+		f.MinScore, f.MinScore,
+		f.MaxScore, f.MaxScore,
+		f.MinLegalKnowledge, f.MinLegalKnowledge,
+		f.MinArgumentation, f.MinArgumentation,
+		f.MinPresentation, f.MinPresentation,
+		f.MinResponseQuality, f.MinResponseQuality,
+		f.VerifiedOnly,
+		!f.JoinedSince.IsZero(), f.JoinedSince.UTC(),
+		limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var candidates []*Candidate
+	total := 0
+
+	for rows.Next() {
+		c, err := scanCandidate(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		candidates = append(candidates, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return candidates, total, nil
+}
+
+// Get retrieves a single candidate. Users who aren't visible to the
+// recruiter are reported as ErrNoRecord.
+func (m *CandidateModel) Get(id, recruiterID int) (*Candidate, error) {
+	stmt := `SELECT ` + candidateColumns + ` ` + candidateFrom + ` AND u.id = ?`
+
+	c, err := scanCandidate(m.DB.QueryRow(stmt, recruiterID, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// CountWithScores returns the number of candidates visible to the recruiter
+// who share at least one evaluated moot session
+func (m *CandidateModel) CountWithScores(recruiterID int) (int, error) {
+	stmt := `SELECT COUNT(*) FROM users u
+		WHERE u.is_active = TRUE AND u.role IN ('student', 'lawyer') AND ` + recruiterConsent + `
+		AND EXISTS (SELECT 1 FROM performance_evaluations pe
+			WHERE pe.user_id = u.id AND pe.share_with_recruiters = TRUE)`
+
+	var count int
+	err := m.DB.QueryRow(stmt, recruiterID).Scan(&count)
+	return count, err
+}
+
+// AreasOfLaw lists the case types that moot sessions have covered
+func (m *CandidateModel) AreasOfLaw() ([]string, error) {
+	stmt := `SELECT DISTINCT case_type FROM moot_sessions
+		WHERE case_type IS NOT NULL AND case_type <> '' ORDER BY case_type`
+
+	rows, err := m.DB.Query(stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var areas []string
+
+	for rows.Next() {
+		var area string
+		if err = rows.Scan(&area); err != nil {
+			return nil, err
+		}
+		areas = append(areas, area)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return areas, nil
+}
@@ -0,0 +1,242 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxCompareCandidates is the most candidates that can be compared at once
+const MaxCompareCandidates = 5
+
+// Difficulties lists the moot session difficulty levels, easiest first
+var Difficulties = []string{"easy", "medium", "hard"}
+
+// CandidateReport compares one candidate's moot performance with everyone
+// else's. Standard scores put each evaluation on a common scale before
+// averaging: 50 is the average for sessions of the same case type, and every
+// 10 points is one standard deviation above or below it.
+type CandidateReport struct {
+	Candidate *Candidate
+
+	OverallStandard         sql.NullFloat64
+	LegalKnowledgeStandard  sql.NullFloat64
+	ArgumentationStandard   sql.NullFloat64
+	PresentationStandard    sql.NullFloat64
+	ResponseQualityStandard sql.NullFloat64
+
+	// Sessions per difficulty level
+	Difficulty map[string]int
+
+	// Average overall score per month, oldest first
+	Trend []TrendPoint
+}
+
+// TrendPoint is a candidate's average overall score for one month
+type TrendPoint struct {
+	Month time.Time
+	Score sql.NullFloat64
+}
+
+// TrendChange returns the difference between the latest and earliest months
+// that have a score, or an invalid value if fewer than two months do
+func (r *CandidateReport) TrendChange() sql.NullFloat64 {
+	var first, last sql.NullFloat64
+	for _, p := range r.Trend {
+		if !p.Score.Valid {
+			continue
+		}
+		if !first.Valid {
+			first = p.Score
+		} else {
+			last = p.Score
+		}
+	}
+
+	if !last.Valid {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: last.Float64 - first.Float64, Valid: true}
+}
+
+// standardScore averages a score column as standard scores against the
+// caseTypeStats subquery (aliased ct). Evaluations in a case type where
+// everyone scored the same sit at the average.
+func standardScore(col string) string {
+	return fmt.Sprintf(`AVG(CASE WHEN pe.%[1]s IS NULL THEN NULL
+		ELSE 50 + 10 * COALESCE((pe.%[1]s - ct.%[1]s_avg) / NULLIF(ct.%[1]s_sd, 0), 0) END)`, col)
+}
+
+func caseTypeStat(col string) string {
+	return fmt.Sprintf(`AVG(pe.%[1]s) AS %[1]s_avg, STDDEV_POP(pe.%[1]s) AS %[1]s_sd`, col)
+}
+
+var scoreColumns = []string{"overall_score", "legal_knowledge_score", "argumentation_score",
+	"presentation_score", "response_quality_score"}
+
+// caseTypeStats is the population mean and standard deviation of every score
+// column for each case type, over the evaluations shared with recruiters.
+// Sessions without a case type form their own group.
+var caseTypeStats = func() string {
+	stats := make([]string, len(scoreColumns))
+	for i, col := range scoreColumns {
+		stats[i] = caseTypeStat(col)
+	}
+	return `SELECT COALESCE(ms.case_type, '') AS case_type, ` + strings.Join(stats, ", ") + `
+		FROM performance_evaluations pe
+		JOIN moot_sessions ms ON ms.id = pe.session_id
+		WHERE pe.share_with_recruiters = TRUE
+		GROUP BY COALESCE(ms.case_type, '')`
+}()
+
+var standardScores = func() string {
+	scores := make([]string, len(scoreColumns))
+	for i, col := range scoreColumns {
+		scores[i] = standardScore(col)
+	}
+	return strings.Join(scores, ", ")
+}()
+
+// Compare builds a report for each of the given candidates, in the order
+// given, with a score trend covering the last n months. Candidates who are
+// not visible to the recruiter are left out, and only evaluations shared
+// with recruiters are counted.
+func (m *CandidateModel) Compare(ids []int, recruiterID, months int) ([]*CandidateReport, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	in := `(?` + strings.Repeat(", ?", len(ids)-1) + `)`
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	// Candidates
+	stmt := `SELECT ` + candidateColumns + ` ` + candidateFrom + ` AND u.id IN ` + in
+
+	rows, err := m.DB.Query(stmt, append([]any{recruiterID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := make(map[int]*CandidateReport, len(ids))
+
+	for rows.Next() {
+		c, err := scanCandidate(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports[c.ID] = &CandidateReport{Candidate: c, Difficulty: make(map[string]int)}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Standard scores
+	stmt = `SELECT pe.user_id, ` + standardScores + `
+		FROM performance_evaluations pe
+		JOIN moot_sessions ms ON ms.id = pe.session_id
+		JOIN (` + caseTypeStats + `) ct ON ct.case_type = COALESCE(ms.case_type, '')
+		WHERE pe.user_id IN ` + in + ` AND pe.share_with_recruiters = TRUE
+		GROUP BY pe.user_id`
+
+	err = m.eachRow(stmt, args, func(rows *sql.Rows) error {
+		var id int
+		var s CandidateReport
+		err := rows.Scan(&id, &s.OverallStandard, &s.LegalKnowledgeStandard, &s.ArgumentationStandard,
+			&s.PresentationStandard, &s.ResponseQualityStandard)
+		if r, ok := reports[id]; ok && err == nil {
+			r.OverallStandard = s.OverallStandard
+			r.LegalKnowledgeStandard = s.LegalKnowledgeStandard
+			r.ArgumentationStandard = s.ArgumentationStandard
+			r.PresentationStandard = s.PresentationStandard
+			r.ResponseQualityStandard = s.ResponseQualityStandard
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Difficulty mix
+	stmt = `SELECT pe.user_id, ms.difficulty_level, COUNT(*)
+		FROM performance_evaluations pe
+		JOIN moot_sessions ms ON ms.id = pe.session_id
+		WHERE pe.user_id IN ` + in + ` AND pe.share_with_recruiters = TRUE
+		GROUP BY pe.user_id, ms.difficulty_level`
+
+	err = m.eachRow(stmt, args, func(rows *sql.Rows) error {
+		var id, count int
+		var difficulty string
+		err := rows.Scan(&id, &difficulty, &count)
+		if r, ok := reports[id]; ok && err == nil {
+			r.Difficulty[difficulty] = count
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Monthly trend
+	now := time.Now().UTC()
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(months - 1), 0)
+
+	for _, r := range reports {
+		r.Trend = make([]TrendPoint, months)
+		for i := range r.Trend {
+			r.Trend[i].Month = first.AddDate(0, i, 0)
+		}
+	}
+
+	stmt = `SELECT user_id, YEAR(created_at), MONTH(created_at), AVG(overall_score)
+		FROM performance_evaluations
+		WHERE user_id IN ` + in + ` AND share_with_recruiters = TRUE AND created_at >= ?
+		GROUP BY user_id, YEAR(created_at), MONTH(created_at)`
+
+	err = m.eachRow(stmt, append(args, first), func(rows *sql.Rows) error {
+		var id, year, month int
+		var score sql.NullFloat64
+		err := rows.Scan(&id, &year, &month, &score)
+		if r, ok := reports[id]; ok && err == nil {
+			i := (year-first.Year())*12 + month - int(first.Month())
+			if i >= 0 && i < len(r.Trend) {
+				r.Trend[i].Score = score
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]*CandidateReport, 0, len(reports))
+	for _, id := range ids {
+		if r, ok := reports[id]; ok {
+			ordered = append(ordered, r)
+			delete(reports, id)
+		}
+	}
+
+	return ordered, nil
+}
+
+// eachRow runs a query and calls fn for every row
+func (m *CandidateModel) eachRow(stmt string, args []any, fn func(*sql.Rows) error) error {
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = fn(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,299 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecruiterVisibility controls which recruiters can find a student or lawyer
+type RecruiterVisibility string
+
+const (
+	VisibleToAll      RecruiterVisibility = "all"
+	VisibleToApproved RecruiterVisibility = "approved"
+	VisibleToNone     RecruiterVisibility = "hidden"
+)
+
+// RecruiterVisibilities lists the visibility settings, most open first
+var RecruiterVisibilities = []RecruiterVisibility{VisibleToAll, VisibleToApproved, VisibleToNone}
+
+// ValidRecruiterVisibility reports whether v is a known visibility setting
+func ValidRecruiterVisibility(v RecruiterVisibility) bool {
+	for _, known := range RecruiterVisibilities {
+		if v == known {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxApprovedCompanies is the most organisations a user can approve
+const MaxApprovedCompanies = 50
+
+// recruiterConsent limits users (aliased u) to those who have agreed to be
+// seen by the recruiter given as its one parameter. Every query that shows
+// candidates to recruiters must include it. Approval is by membership of an
+// organisation with a verified domain, never by the company name recruiters
+// enter on their own profile.
+const recruiterConsent = `(u.recruiter_visibility = 'all' OR (u.recruiter_visibility = 'approved' AND EXISTS (
+		SELECT 1 FROM candidate_approved_organisations ao
+		JOIN organisations ao_o ON ao_o.id = ao.organisation_id AND ao_o.domain_verified = TRUE
+		JOIN organisation_members ao_m ON ao_m.organisation_id = ao.organisation_id
+		WHERE ao.user_id = u.id AND ao_m.user_id = ?)))`
+
+// Consent is what a user currently shares with recruiters
+type Consent struct {
+	UserID     int
+	Visibility RecruiterVisibility
+
+	// ApprovedOrganisations are the IDs of the verified organisations whose
+	// recruiters can find the user
+	ApprovedOrganisations []int
+	WithheldEvaluations   []int
+}
+
+// ConsentChange is a snapshot of a user's consent, recorded each time they
+// change it
+type ConsentChange struct {
+	ID                  int
+	UserID              int
+	Visibility          RecruiterVisibility
+	ApprovedCompanies   []string
+	WithheldEvaluations []int
+	IPAddress           string
+	CreatedAt           time.Time
+}
+
+// ConsentModel wraps a database connection pool
+type ConsentModel struct {
+	DB *sql.DB
+}
+
+// Get retrieves a user's current consent settings
+func (m *ConsentModel) Get(userID int) (*Consent, error) {
+	c := &Consent{UserID: userID}
+
+	stmt := `SELECT recruiter_visibility FROM users WHERE id = ?`
+
+	err := m.DB.QueryRow(stmt, userID).Scan(&c.Visibility)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	c.ApprovedOrganisations, err = m.approvedOrganisations(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.WithheldEvaluations, err = m.withheldEvaluations(m.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func (m *ConsentModel) approvedOrganisations(userID int) ([]int, error) {
+	rows, err := m.DB.Query(`SELECT organisation_id FROM candidate_approved_organisations
+		WHERE user_id = ? ORDER BY organisation_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// approvedCompanies returns the names of the organisations a user has
+// approved, as recorded in their consent history
+func (m *ConsentModel) approvedCompanies(q querier, userID int) ([]string, error) {
+	rows, err := q.Query(`SELECT o.name FROM candidate_approved_organisations ao
+		JOIN organisations o ON o.id = ao.organisation_id
+		WHERE ao.user_id = ? ORDER BY o.name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var companies []string
+
+	for rows.Next() {
+		var company string
+		if err = rows.Scan(&company); err != nil {
+			return nil, err
+		}
+		companies = append(companies, company)
+	}
+
+	return companies, rows.Err()
+}
+
+func (m *ConsentModel) withheldEvaluations(q querier, userID int) ([]int, error) {
+	rows, err := q.Query(`SELECT id FROM performance_evaluations
+		WHERE user_id = ? AND share_with_recruiters = FALSE ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Update replaces a user's consent settings and records the result in their
+// consent history. Withheld evaluations that don't belong to the user, and
+// organisations without a verified domain, are ignored.
+func (m *ConsentModel) Update(c *Consent, ipAddress string) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE users SET recruiter_visibility = ?, updated_at = UTC_TIMESTAMP() WHERE id = ?`,
+		c.Visibility, c.UserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM candidate_approved_organisations WHERE user_id = ?`, c.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range c.ApprovedOrganisations {
+		_, err = tx.Exec(`INSERT IGNORE INTO candidate_approved_organisations (user_id, organisation_id, created_at)
+			SELECT ?, id, UTC_TIMESTAMP() FROM organisations WHERE id = ? AND domain_verified = TRUE`, c.UserID, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE performance_evaluations SET share_with_recruiters = TRUE WHERE user_id = ?`, c.UserID)
+	if err != nil {
+		return err
+	}
+
+	if len(c.WithheldEvaluations) > 0 {
+		args := []any{c.UserID}
+		for _, id := range c.WithheldEvaluations {
+			args = append(args, id)
+		}
+
+		stmt := `UPDATE performance_evaluations SET share_with_recruiters = FALSE
+			WHERE user_id = ? AND id IN (?` + strings.Repeat(", ?", len(c.WithheldEvaluations)-1) + `)`
+
+		_, err = tx.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Record what was actually saved rather than what was asked for
+	companies, err := m.approvedCompanies(tx, c.UserID)
+	if err != nil {
+		return err
+	}
+
+	withheld, err := m.withheldEvaluations(tx, c.UserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO consent_history
+		(user_id, recruiter_visibility, approved_companies, withheld_evaluations, ip_address, created_at)
+		VALUES (?, ?, ?, ?, ?, UTC_TIMESTAMP())`,
+		c.UserID, c.Visibility, strings.Join(companies, "\n"), joinInts(withheld), truncate(ipAddress, 45))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// History returns a user's consent changes, newest first
+func (m *ConsentModel) History(userID, limit int) ([]*ConsentChange, error) {
+	stmt := `SELECT id, user_id, recruiter_visibility, approved_companies, withheld_evaluations,
+		ip_address, created_at
+		FROM consent_history WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC LIMIT ?`
+
+	rows, err := m.DB.Query(stmt, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*ConsentChange
+
+	for rows.Next() {
+		var c ConsentChange
+		var companies, withheld string
+
+		err = rows.Scan(&c.ID, &c.UserID, &c.Visibility, &companies, &withheld, &c.IPAddress, &c.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if companies != "" {
+			c.ApprovedCompanies = strings.Split(companies, "\n")
+		}
+		c.WithheldEvaluations = splitInts(withheld)
+
+		changes = append(changes, &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func joinInts(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ",")
+}
+
+func splitInts(s string) []int {
+	var ns []int
+	for _, field := range strings.Split(s, ",") {
+		if n, err := strconv.Atoi(field); err == nil {
+			ns = append(ns, n)
+		}
+	}
+	return ns
+}
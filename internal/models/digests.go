@@ -0,0 +1,107 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// DigestSettings is a user's choice about the weekly digest email
+type DigestSettings struct {
+	UserID     int
+	Subscribed bool
+
+	// CandidateFilter is the candidate search, as a URL query string, whose
+	// new matches are listed in a recruiter's digest
+	CandidateFilter string
+
+	LastSentAt sql.NullTime
+}
+
+// DigestSubscriber is a user who receives the weekly digest
+type DigestSubscriber struct {
+	UserID          int
+	Name            string
+	Email           string
+	Role            UserRole
+	CandidateFilter string
+}
+
+// DigestModel wraps a database connection pool
+type DigestModel struct {
+	DB *sql.DB
+}
+
+// Get returns a user's digest settings. Users who have never chosen are not
+// subscribed.
+func (m *DigestModel) Get(userID int) (*DigestSettings, error) {
+	s := DigestSettings{UserID: userID}
+
+	stmt := `SELECT subscribed, candidate_filter, last_sent_at FROM digest_settings WHERE user_id = ?`
+	err := m.DB.QueryRow(stmt, userID).Scan(&s.Subscribed, &s.CandidateFilter, &s.LastSentAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// SetSubscribed subscribes a user to the weekly digest or unsubscribes them
+func (m *DigestModel) SetSubscribed(userID int, subscribed bool) error {
+	stmt := `INSERT INTO digest_settings (user_id, subscribed) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE subscribed = VALUES(subscribed)`
+
+	_, err := m.DB.Exec(stmt, userID, subscribed)
+	return err
+}
+
+// SetCandidateFilter saves the candidate search used in a recruiter's digest
+func (m *DigestModel) SetCandidateFilter(userID int, filter string) error {
+	stmt := `INSERT INTO digest_settings (user_id, candidate_filter) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE candidate_filter = VALUES(candidate_filter)`
+
+	_, err := m.DB.Exec(stmt, userID, filter)
+	return err
+}
+
+// Subscribers returns every active user subscribed to the digest who hasn't
+// been sent one since the given time
+func (m *DigestModel) Subscribers(notSentSince time.Time) ([]*DigestSubscriber, error) {
+	stmt := `SELECT u.id, u.name, u.email, u.role, d.candidate_filter
+		FROM digest_settings d
+		JOIN users u ON u.id = d.user_id
+		WHERE d.subscribed = TRUE AND u.is_active = TRUE
+		AND (d.last_sent_at IS NULL OR d.last_sent_at < ?)
+		ORDER BY u.id`
+
+	rows, err := m.DB.Query(stmt, notSentSince.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscribers []*DigestSubscriber
+
+	for rows.Next() {
+		var s DigestSubscriber
+		err = rows.Scan(&s.UserID, &s.Name, &s.Email, &s.Role, &s.CandidateFilter)
+		if err != nil {
+			return nil, err
+		}
+		subscribers = append(subscribers, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscribers, nil
+}
+
+// MarkSent records that a user has been sent their digest
+func (m *DigestModel) MarkSent(userID int) error {
+	stmt := `UPDATE digest_settings SET last_sent_at = UTC_TIMESTAMP() WHERE user_id = ?`
+
+	_, err := m.DB.Exec(stmt, userID)
+	return err
+}
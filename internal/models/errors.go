@@ -12,9 +12,33 @@ var (
 	// ErrDuplicateEmail is returned when trying to create a user with an email that already exists
 	ErrDuplicateEmail = errors.New("models: duplicate email")
 
+	// ErrDuplicateSlug is returned when a portfolio slug is already taken
+	ErrDuplicateSlug = errors.New("models: duplicate slug")
+
+	// ErrDuplicateName is returned when a recruiter already has a shortlist or saved search with the same name
+	ErrDuplicateName = errors.New("models: duplicate name")
+
+	// ErrDuplicateConversation is returned when a recruiter has already contacted a candidate
+	ErrDuplicateConversation = errors.New("models: duplicate conversation")
+
+	// ErrDuplicateApplication is returned when a user has already applied for a job posting
+	ErrDuplicateApplication = errors.New("models: duplicate application")
+
+	// ErrDuplicateDomain is returned when another organisation has claimed an email domain
+	ErrDuplicateDomain = errors.New("models: duplicate domain")
+
+	// ErrDuplicateReview is returned when someone has already reviewed a mentored moot
+	ErrDuplicateReview = errors.New("models: duplicate review")
+
+	// ErrAlreadyMember is returned when a recruiter already belongs to an organisation
+	ErrAlreadyMember = errors.New("models: already a member of an organisation")
+
 	// ErrInactiveAccount is returned when a user's account is deactivated
 	ErrInactiveAccount = errors.New("models: account is inactive")
 
 	// ErrExpiredSession is returned when a session has expired
 	ErrExpiredSession = errors.New("models: session has expired")
+
+	// ErrExpiredToken is returned when an API token has passed its expiry time
+	ErrExpiredToken = errors.New("models: API token has expired")
 )
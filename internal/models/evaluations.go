@@ -0,0 +1,299 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ActivitySummary totals a user's evaluations over a period
+type ActivitySummary struct {
+	Sessions     int
+	AverageScore sql.NullFloat64
+
+	// PreviousScore averages the overall score of every evaluation before
+	// the period
+	PreviousScore sql.NullFloat64
+}
+
+// Change is the difference between the average overall score during the
+// period and before it. It is invalid unless both are known.
+func (s *ActivitySummary) Change() sql.NullFloat64 {
+	if !s.AverageScore.Valid || !s.PreviousScore.Valid {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: s.AverageScore.Float64 - s.PreviousScore.Float64, Valid: true}
+}
+
+// EvaluationModel wraps a database connection pool
+type EvaluationModel struct {
+	DB *sql.DB
+}
+
+// ActivitySince summarises a user's evaluations created since the given time
+func (m *EvaluationModel) ActivitySince(userID int, since time.Time) (*ActivitySummary, error) {
+	stmt := `SELECT COUNT(CASE WHEN created_at >= ? THEN 1 END),
+			AVG(CASE WHEN created_at >= ? THEN overall_score END),
+			AVG(CASE WHEN created_at < ? THEN overall_score END)
+		FROM performance_evaluations WHERE user_id = ?`
+
+	since = since.UTC()
+
+	var s ActivitySummary
+	err := m.DB.QueryRow(stmt, since, since, since, userID).Scan(&s.Sessions, &s.AverageScore, &s.PreviousScore)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// CandidatesSince returns the active students and lawyers who have received
+// an evaluation since the given time
+func (m *EvaluationModel) CandidatesSince(since time.Time) ([]int, error) {
+	stmt := `SELECT DISTINCT u.id
+		FROM performance_evaluations pe
+		JOIN users u ON u.id = pe.user_id
+		WHERE pe.created_at >= ? AND u.is_active = TRUE AND u.role IN ('student', 'lawyer')`
+
+	rows, err := m.DB.Query(stmt, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Evaluation is the AI assessment of one participant in a moot session
+type Evaluation struct {
+	ID                   int
+	SessionID            int
+	UserID               int
+	CaseType             string
+	Difficulty           string
+	OverallScore         sql.NullFloat64
+	LegalKnowledgeScore  sql.NullFloat64
+	ArgumentationScore   sql.NullFloat64
+	PresentationScore    sql.NullFloat64
+	ResponseQualityScore sql.NullFloat64
+	SharedWithRecruiters bool
+	CreatedAt            time.Time
+}
+
+// PerformanceHighlights summarises all of a user's evaluations
+type PerformanceHighlights struct {
+	Sessions             int
+	BestScore            sql.NullFloat64
+	OverallScore         sql.NullFloat64
+	LegalKnowledgeScore  sql.NullFloat64
+	ArgumentationScore   sql.NullFloat64
+	PresentationScore    sql.NullFloat64
+	ResponseQualityScore sql.NullFloat64
+}
+
+// ScoreTrend compares the average overall score of a user's most recent
+// evaluations with the same number of evaluations before them
+type ScoreTrend struct {
+	Recent   sql.NullFloat64
+	Previous sql.NullFloat64
+}
+
+const evaluationColumns = `pe.id, pe.session_id, pe.user_id, COALESCE(ms.case_type, ''), ms.difficulty_level,
+	pe.overall_score, pe.legal_knowledge_score, pe.argumentation_score, pe.presentation_score,
+	pe.response_quality_score, pe.share_with_recruiters, pe.created_at`
+
+func scanEvaluation(row rowScanner) (*Evaluation, error) {
+	var e Evaluation
+	err := row.Scan(
+		&e.ID,
+		&e.SessionID,
+		&e.UserID,
+		&e.CaseType,
+		&e.Difficulty,
+		&e.OverallScore,
+		&e.LegalKnowledgeScore,
+		&e.ArgumentationScore,
+		&e.PresentationScore,
+		&e.ResponseQualityScore,
+		&e.SharedWithRecruiters,
+		&e.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (m *EvaluationModel) query(stmt string, args ...any) ([]*Evaluation, error) {
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var evaluations []*Evaluation
+
+	for rows.Next() {
+		e, err := scanEvaluation(rows)
+		if err != nil {
+			return nil, err
+		}
+		evaluations = append(evaluations, e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return evaluations, nil
+}
+
+// ListForUser returns a user's evaluations, newest first
+func (m *EvaluationModel) ListForUser(userID int) ([]*Evaluation, error) {
+	stmt := `SELECT ` + evaluationColumns + `
+		FROM performance_evaluations pe
+		JOIN moot_sessions ms ON ms.id = pe.session_id
+		WHERE pe.user_id = ?
+		ORDER BY pe.created_at DESC, pe.id DESC`
+
+	return m.query(stmt, userID)
+}
+
+// ListShared returns the evaluations a user shares with recruiters, newest
+// first
+func (m *EvaluationModel) ListShared(userID int) ([]*Evaluation, error) {
+	stmt := `SELECT ` + evaluationColumns + `
+		FROM performance_evaluations pe
+		JOIN moot_sessions ms ON ms.id = pe.session_id
+		WHERE pe.user_id = ? AND pe.share_with_recruiters = TRUE
+		ORDER BY pe.created_at DESC, pe.id DESC`
+
+	return m.query(stmt, userID)
+}
+
+// CreatedSince returns the evaluations of active students and lawyers
+// created since the given time, oldest first
+func (m *EvaluationModel) CreatedSince(since time.Time) ([]*Evaluation, error) {
+	stmt := `SELECT ` + evaluationColumns + `
+		FROM performance_evaluations pe
+		JOIN moot_sessions ms ON ms.id = pe.session_id
+		JOIN users u ON u.id = pe.user_id
+		WHERE pe.created_at >= ? AND u.is_active = TRUE AND u.role IN ('student', 'lawyer')
+		ORDER BY pe.created_at, pe.id`
+
+	return m.query(stmt, since.UTC())
+}
+
+// Featured returns the evaluations a user has chosen to show on their
+// portfolio, best score first
+func (m *EvaluationModel) Featured(userID int) ([]*Evaluation, error) {
+	stmt := `SELECT ` + evaluationColumns + `
+		FROM portfolio_evaluations pf
+		JOIN performance_evaluations pe ON pe.id = pf.evaluation_id
+		JOIN moot_sessions ms ON ms.id = pe.session_id
+		WHERE pf.user_id = ? AND pe.user_id = pf.user_id
+		ORDER BY pe.overall_score DESC, pe.created_at DESC`
+
+	return m.query(stmt, userID)
+}
+
+// Attached returns the evaluations attached to a job application, best score
+// first
+func (m *EvaluationModel) Attached(applicationID int) ([]*Evaluation, error) {
+	stmt := `SELECT ` + evaluationColumns + `
+		FROM job_application_evaluations ae
+		JOIN job_applications a ON a.id = ae.application_id
+		JOIN performance_evaluations pe ON pe.id = ae.evaluation_id
+		JOIN moot_sessions ms ON ms.id = pe.session_id
+		WHERE ae.application_id = ? AND pe.user_id = a.applicant_id
+		ORDER BY pe.overall_score DESC, pe.created_at DESC`
+
+	return m.query(stmt, applicationID)
+}
+
+// Highlights returns the average scores across all of a user's evaluations
+func (m *EvaluationModel) Highlights(userID int) (*PerformanceHighlights, error) {
+	stmt := `SELECT COUNT(*), MAX(overall_score), AVG(overall_score), AVG(legal_knowledge_score),
+		AVG(argumentation_score), AVG(presentation_score), AVG(response_quality_score)
+		FROM performance_evaluations WHERE user_id = ?`
+
+	var h PerformanceHighlights
+	err := m.DB.QueryRow(stmt, userID).Scan(
+		&h.Sessions,
+		&h.BestScore,
+		&h.OverallScore,
+		&h.LegalKnowledgeScore,
+		&h.ArgumentationScore,
+		&h.PresentationScore,
+		&h.ResponseQualityScore,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &h, nil
+}
+
+// Trend averages the overall scores of a user's last n evaluations and of the
+// n evaluations before those
+func (m *EvaluationModel) Trend(userID, n int) (*ScoreTrend, error) {
+	stmt := `SELECT AVG(CASE WHEN t.position <= ? THEN t.overall_score END),
+			AVG(CASE WHEN t.position > ? THEN t.overall_score END)
+		FROM (
+			SELECT overall_score, ROW_NUMBER() OVER (ORDER BY created_at DESC, id DESC) AS position
+			FROM performance_evaluations WHERE user_id = ?
+		) t
+		WHERE t.position <= ?`
+
+	var t ScoreTrend
+	err := m.DB.QueryRow(stmt, n, n, userID, 2*n).Scan(&t.Recent, &t.Previous)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// PracticeDays returns the UTC dates on which a user received an evaluation,
+// most recent first
+func (m *EvaluationModel) PracticeDays(userID int) ([]time.Time, error) {
+	stmt := `SELECT DISTINCT DATE(created_at) AS day FROM performance_evaluations
+		WHERE user_id = ?
+		ORDER BY day DESC`
+
+	rows, err := m.DB.Query(stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []time.Time
+
+	for rows.Next() {
+		var day time.Time
+		if err = rows.Scan(&day); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return days, nil
+}
@@ -0,0 +1,368 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// InterviewStatus is where an interview is in being arranged
+type InterviewStatus string
+
+// A recruiter proposes an interview with some times to choose from, and it
+// is scheduled once the candidate picks one. Rescheduling proposes new times.
+const (
+	InterviewProposed  InterviewStatus = "proposed"
+	InterviewScheduled InterviewStatus = "scheduled"
+	InterviewCancelled InterviewStatus = "cancelled"
+)
+
+// Interview is an interview a recruiter has arranged with a candidate
+type Interview struct {
+	ID             int
+	RecruiterID    int
+	RecruiterName  string
+	RecruiterEmail string
+	CompanyName    string
+	CandidateID    int
+	CandidateName  string
+	CandidateEmail string
+	Title          string
+	Details        string
+	Duration       int
+
+	// TimeZone is the IANA time zone the interview was proposed in
+	TimeZone string
+
+	Status InterviewStatus
+
+	// StartsAt is set once the interview is scheduled, and kept if it's
+	// cancelled afterwards
+	StartsAt sql.NullTime
+
+	// Sequence increases with every change to the interview, so calendar
+	// applications know which invite is the latest
+	Sequence int
+
+	ReminderSentAt sql.NullTime
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	// Slots are the times offered. Filled in by Get.
+	Slots []*InterviewSlot
+}
+
+// InterviewSlot is a time offered for an interview
+type InterviewSlot struct {
+	ID       int
+	StartsAt time.Time
+}
+
+// Proposed reports whether the candidate has yet to choose a time
+func (i *Interview) Proposed() bool {
+	return i.Status == InterviewProposed
+}
+
+// Scheduled reports whether the interview has a time
+func (i *Interview) Scheduled() bool {
+	return i.Status == InterviewScheduled
+}
+
+// Cancelled reports whether either side has cancelled the interview
+func (i *Interview) Cancelled() bool {
+	return i.Status == InterviewCancelled
+}
+
+// IsRecruiter reports whether userID is the recruiter in the interview
+func (i *Interview) IsRecruiter(userID int) bool {
+	return i.RecruiterID == userID
+}
+
+// OtherID returns the ID of the participant who isn't userID
+func (i *Interview) OtherID(userID int) int {
+	if i.RecruiterID == userID {
+		return i.CandidateID
+	}
+	return i.RecruiterID
+}
+
+// EndsAt returns when the scheduled interview finishes
+func (i *Interview) EndsAt() time.Time {
+	return i.StartsAt.Time.Add(time.Duration(i.Duration) * time.Minute)
+}
+
+// Location returns the interview's time zone, or UTC if it's unknown
+func (i *Interview) Location() *time.Location {
+	loc, err := time.LoadLocation(i.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// InterviewModel wraps a database connection pool
+type InterviewModel struct {
+	DB *sql.DB
+}
+
+const interviewColumns = `i.id, i.recruiter_id, r.name, r.email, COALESCE(rp.company_name, ''),
+	i.candidate_id, u.name, u.email, i.title, i.details, i.duration_minutes, i.time_zone,
+	i.status, i.starts_at, i.sequence, i.reminder_sent_at, i.created_at, i.updated_at`
+
+const interviewFrom = `FROM interviews i
+	JOIN users r ON r.id = i.recruiter_id
+	JOIN users u ON u.id = i.candidate_id
+	LEFT JOIN recruiter_profiles rp ON rp.user_id = i.recruiter_id`
+
+func scanInterview(row rowScanner) (*Interview, error) {
+	var i Interview
+	err := row.Scan(
+		&i.ID,
+		&i.RecruiterID,
+		&i.RecruiterName,
+		&i.RecruiterEmail,
+		&i.CompanyName,
+		&i.CandidateID,
+		&i.CandidateName,
+		&i.CandidateEmail,
+		&i.Title,
+		&i.Details,
+		&i.Duration,
+		&i.TimeZone,
+		&i.Status,
+		&i.StartsAt,
+		&i.Sequence,
+		&i.ReminderSentAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// Insert proposes an interview at one of the given times and returns its ID
+func (m *InterviewModel) Insert(recruiterID, candidateID int, title, details string, duration int, timeZone string, slots []time.Time) (int, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO interviews
+		(recruiter_id, candidate_id, title, details, duration_minutes, time_zone, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP())`,
+		recruiterID, candidateID, title, details, duration, timeZone)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	err = insertInterviewSlots(tx, int(id), slots)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), tx.Commit()
+}
+
+func insertInterviewSlots(tx *sql.Tx, interviewID int, slots []time.Time) error {
+	if len(slots) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, 2*len(slots))
+	for _, s := range slots {
+		args = append(args, interviewID, s.UTC())
+	}
+
+	stmt := `INSERT INTO interview_slots (interview_id, starts_at) VALUES (?, ?)` +
+		strings.Repeat(", (?, ?)", len(slots)-1)
+
+	_, err := tx.Exec(stmt, args...)
+	return err
+}
+
+// Get retrieves an interview that userID takes part in, with the times
+// offered for it
+func (m *InterviewModel) Get(id, userID int) (*Interview, error) {
+	stmt := `SELECT ` + interviewColumns + ` ` + interviewFrom + `
+		WHERE i.id = ? AND (i.recruiter_id = ? OR i.candidate_id = ?)`
+
+	i, err := scanInterview(m.DB.QueryRow(stmt, id, userID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	rows, err := m.DB.Query(`SELECT id, starts_at FROM interview_slots
+		WHERE interview_id = ? ORDER BY starts_at`, i.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s InterviewSlot
+		if err = rows.Scan(&s.ID, &s.StartsAt); err != nil {
+			return nil, err
+		}
+		i.Slots = append(i.Slots, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// ListForUser returns every interview userID takes part in, those awaiting
+// a time first, then by time with the latest first
+func (m *InterviewModel) ListForUser(userID int) ([]*Interview, error) {
+	stmt := `SELECT ` + interviewColumns + ` ` + interviewFrom + `
+		WHERE i.recruiter_id = ? OR i.candidate_id = ?
+		ORDER BY i.status <> 'proposed', COALESCE(i.starts_at, i.created_at) DESC, i.id DESC`
+
+	return m.list(stmt, userID, userID)
+}
+
+// Feed returns the interviews for a user's calendar feed: those with a time,
+// including ones cancelled since, that end after the given time
+func (m *InterviewModel) Feed(userID int, since time.Time) ([]*Interview, error) {
+	stmt := `SELECT ` + interviewColumns + ` ` + interviewFrom + `
+		WHERE (i.recruiter_id = ? OR i.candidate_id = ?) AND i.starts_at IS NOT NULL
+		AND i.status <> 'proposed'
+		AND DATE_ADD(i.starts_at, INTERVAL i.duration_minutes MINUTE) >= ?
+		ORDER BY i.starts_at`
+
+	return m.list(stmt, userID, userID, since.UTC())
+}
+
+// DueReminders returns the scheduled interviews starting between now and
+// the given time whose participants haven't been reminded
+func (m *InterviewModel) DueReminders(before time.Time) ([]*Interview, error) {
+	stmt := `SELECT ` + interviewColumns + ` ` + interviewFrom + `
+		WHERE i.status = 'scheduled' AND i.reminder_sent_at IS NULL
+		AND i.starts_at > UTC_TIMESTAMP() AND i.starts_at <= ?
+		ORDER BY i.starts_at`
+
+	return m.list(stmt, before.UTC())
+}
+
+func (m *InterviewModel) list(stmt string, args ...any) ([]*Interview, error) {
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var interviews []*Interview
+
+	for rows.Next() {
+		i, err := scanInterview(rows)
+		if err != nil {
+			return nil, err
+		}
+		interviews = append(interviews, i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return interviews, nil
+}
+
+// Schedule books a proposed interview at the time the candidate chose. It
+// returns ErrNoRecord if the interview is no longer awaiting a time or the
+// slot has passed.
+func (m *InterviewModel) Schedule(id, candidateID, slotID int) error {
+	stmt := `UPDATE interviews i JOIN interview_slots s ON s.interview_id = i.id
+		SET i.status = 'scheduled', i.starts_at = s.starts_at, i.sequence = i.sequence + 1,
+			i.reminder_sent_at = NULL, i.updated_at = UTC_TIMESTAMP()
+		WHERE i.id = ? AND i.candidate_id = ? AND i.status = 'proposed'
+		AND s.id = ? AND s.starts_at > UTC_TIMESTAMP()`
+
+	return m.update(stmt, id, candidateID, slotID)
+}
+
+// Reschedule offers new times for an interview that hasn't been cancelled,
+// returning it to proposed. It returns ErrNoRecord if the interview has been
+// cancelled.
+func (m *InterviewModel) Reschedule(id, recruiterID int, slots []time.Time) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE interviews
+		SET status = 'proposed', starts_at = NULL, sequence = sequence + 1,
+			reminder_sent_at = NULL, updated_at = UTC_TIMESTAMP()
+		WHERE id = ? AND recruiter_id = ? AND status <> 'cancelled'`, id, recruiterID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+
+	_, err = tx.Exec(`DELETE FROM interview_slots WHERE interview_id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	err = insertInterviewSlots(tx, id, slots)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Cancel cancels an interview for both participants. It returns ErrNoRecord
+// if it has already been cancelled.
+func (m *InterviewModel) Cancel(id, userID int) error {
+	stmt := `UPDATE interviews SET status = 'cancelled', sequence = sequence + 1, updated_at = UTC_TIMESTAMP()
+		WHERE id = ? AND (recruiter_id = ? OR candidate_id = ?) AND status <> 'cancelled'`
+
+	return m.update(stmt, id, userID, userID)
+}
+
+// MarkReminded records that the participants have been reminded
+func (m *InterviewModel) MarkReminded(id int) error {
+	stmt := `UPDATE interviews SET reminder_sent_at = UTC_TIMESTAMP() WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, id)
+	return err
+}
+
+func (m *InterviewModel) update(stmt string, args ...any) error {
+	result, err := m.DB.Exec(stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
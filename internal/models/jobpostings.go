@@ -0,0 +1,436 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ApplicationStage is where a job application is in the recruiter's pipeline
+type ApplicationStage string
+
+const (
+	StageApplied     ApplicationStage = "applied"
+	StageShortlisted ApplicationStage = "shortlisted"
+	StageInterview   ApplicationStage = "interview"
+	StageOffer       ApplicationStage = "offer"
+	StageRejected    ApplicationStage = "rejected"
+)
+
+// ApplicationStages lists the pipeline stages in order
+var ApplicationStages = []ApplicationStage{StageApplied, StageShortlisted, StageInterview, StageOffer, StageRejected}
+
+// ValidApplicationStage reports whether s is a known pipeline stage
+func ValidApplicationStage(s ApplicationStage) bool {
+	for _, known := range ApplicationStages {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxApplicationEvaluations is the most moot results that can be attached to
+// one application
+const MaxApplicationEvaluations = 5
+
+// JobPosting is a role advertised by a recruiter
+type JobPosting struct {
+	ID            int
+	RecruiterID   int
+	RecruiterName string
+	CompanyName   string
+	Title         string
+	PracticeArea  string
+	Location      string
+	MinExperience int
+	MaxExperience int // 0 means no upper limit
+	Description   string
+	Deadline      time.Time
+	Closed        bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	Applications int
+}
+
+// Open reports whether the posting is still taking applications. The
+// deadline day itself is included.
+func (p *JobPosting) Open() bool {
+	return !p.Closed && time.Now().UTC().Before(p.Deadline.AddDate(0, 0, 1))
+}
+
+// JobPostingFilter narrows the list of open postings
+type JobPostingFilter struct {
+	PracticeArea string
+	Location     string
+	Experience   int // 0 matches every posting
+}
+
+// JobPostingModel wraps a database connection pool
+type JobPostingModel struct {
+	DB *sql.DB
+}
+
+const jobPostingColumns = `p.id, p.recruiter_id, u.name, COALESCE(rp.company_name, ''), p.title,
+	p.practice_area, p.location, p.min_experience, p.max_experience, p.description, p.deadline,
+	p.closed, p.created_at, p.updated_at,
+	(SELECT COUNT(*) FROM job_applications a WHERE a.posting_id = p.id)`
+
+const jobPostingFrom = `FROM job_postings p
+	JOIN users u ON u.id = p.recruiter_id
+	LEFT JOIN recruiter_profiles rp ON rp.user_id = p.recruiter_id`
+
+// openPosting limits postings (aliased p) to those still taking applications
+const openPosting = `p.closed = FALSE AND p.deadline >= UTC_DATE()`
+
+func scanJobPosting(row rowScanner, extra ...any) (*JobPosting, error) {
+	var p JobPosting
+
+	dest := append([]any{
+		&p.ID,
+		&p.RecruiterID,
+		&p.RecruiterName,
+		&p.CompanyName,
+		&p.Title,
+		&p.PracticeArea,
+		&p.Location,
+		&p.MinExperience,
+		&p.MaxExperience,
+		&p.Description,
+		&p.Deadline,
+		&p.Closed,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.Applications,
+	}, extra...)
+
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (m *JobPostingModel) query(stmt string, args ...any) ([]*JobPosting, error) {
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var postings []*JobPosting
+
+	for rows.Next() {
+		p, err := scanJobPosting(rows)
+		if err != nil {
+			return nil, err
+		}
+		postings = append(postings, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return postings, nil
+}
+
+// Insert saves a new posting and returns its ID
+func (m *JobPostingModel) Insert(p *JobPosting) (int, error) {
+	stmt := `INSERT INTO job_postings (recruiter_id, title, practice_area, location,
+		min_experience, max_experience, description, deadline)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := m.DB.Exec(stmt, p.RecruiterID, p.Title, p.PracticeArea, p.Location,
+		p.MinExperience, p.MaxExperience, p.Description, p.Deadline)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Update saves changes to a posting. Only the recruiter who posted it may
+// change it.
+func (m *JobPostingModel) Update(p *JobPosting) error {
+	stmt := `UPDATE job_postings SET title = ?, practice_area = ?, location = ?,
+		min_experience = ?, max_experience = ?, description = ?, deadline = ?, closed = ?
+		WHERE id = ? AND recruiter_id = ?`
+
+	_, err := m.DB.Exec(stmt, p.Title, p.PracticeArea, p.Location, p.MinExperience,
+		p.MaxExperience, p.Description, p.Deadline, p.Closed, p.ID, p.RecruiterID)
+	return err
+}
+
+// Get retrieves a posting by ID
+func (m *JobPostingModel) Get(id int) (*JobPosting, error) {
+	stmt := `SELECT ` + jobPostingColumns + ` ` + jobPostingFrom + ` WHERE p.id = ?`
+
+	p, err := scanJobPosting(m.DB.QueryRow(stmt, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListForRecruiter returns a recruiter's postings, newest first
+func (m *JobPostingModel) ListForRecruiter(recruiterID int) ([]*JobPosting, error) {
+	stmt := `SELECT ` + jobPostingColumns + ` ` + jobPostingFrom + `
+		WHERE p.recruiter_id = ?
+		ORDER BY p.created_at DESC, p.id DESC`
+
+	return m.query(stmt, recruiterID)
+}
+
+// ListOpen returns a page of postings still taking applications, closest
+// deadline first, and the total number of matches
+func (m *JobPostingModel) ListOpen(f JobPostingFilter, limit, offset int) ([]*JobPosting, int, error) {
+	stmt := `SELECT ` + jobPostingColumns + `, COUNT(*) OVER() ` + jobPostingFrom + `
+		WHERE ` + openPosting + `
+		AND (? = '' OR p.practice_area = ?)
+		AND (? = '' OR p.location LIKE ?)
+		AND (? = 0 OR (p.min_experience <= ? AND (p.max_experience = 0 OR p.max_experience >= ?)))
+		ORDER BY p.deadline, p.id DESC LIMIT ? OFFSET ?`
+
+	rows, err := m.DB.Query(stmt,
+		f.PracticeArea, f.PracticeArea,
+		f.Location, likePattern(f.Location),
+		f.Experience, f.Experience, f.Experience,
+		limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var postings []*JobPosting
+	total := 0
+
+	for rows.Next() {
+		p, err := scanJobPosting(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		postings = append(postings, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return postings, total, nil
+}
+
+// PracticeAreas returns the practice areas of every open posting
+func (m *JobPostingModel) PracticeAreas() ([]string, error) {
+	stmt := `SELECT DISTINCT p.practice_area FROM job_postings p WHERE ` + openPosting + `
+		ORDER BY p.practice_area`
+
+	rows, err := m.DB.Query(stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var areas []string
+
+	for rows.Next() {
+		var area string
+		if err = rows.Scan(&area); err != nil {
+			return nil, err
+		}
+		areas = append(areas, area)
+	}
+
+	return areas, rows.Err()
+}
+
+// JobApplication is a student's or lawyer's application to a posting
+type JobApplication struct {
+	ID               int
+	PostingID        int
+	PostingTitle     string
+	CompanyName      string
+	ApplicantID      int
+	ApplicantName    string
+	ApplicantRole    UserRole
+	CoverLetter      string
+	IncludePortfolio bool
+	Stage            ApplicationStage
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+
+	// PortfolioSlug is set when the applicant included their portfolio and
+	// it is public
+	PortfolioSlug sql.NullString
+
+	// EvaluationIDs are the moot results to attach when applying
+	EvaluationIDs []int
+}
+
+// JobApplicationModel wraps a database connection pool
+type JobApplicationModel struct {
+	DB *sql.DB
+}
+
+const jobApplicationColumns = `a.id, a.posting_id, p.title, COALESCE(rp.company_name, ''),
+	a.applicant_id, u.name, u.role, a.cover_letter, a.include_portfolio, a.stage,
+	a.created_at, a.updated_at, IF(a.include_portfolio, po.slug, NULL)`
+
+const jobApplicationFrom = `FROM job_applications a
+	JOIN job_postings p ON p.id = a.posting_id
+	JOIN users u ON u.id = a.applicant_id
+	LEFT JOIN recruiter_profiles rp ON rp.user_id = p.recruiter_id
+	LEFT JOIN portfolios po ON po.user_id = a.applicant_id AND po.is_public = TRUE`
+
+func scanJobApplication(row rowScanner) (*JobApplication, error) {
+	var a JobApplication
+	err := row.Scan(
+		&a.ID,
+		&a.PostingID,
+		&a.PostingTitle,
+		&a.CompanyName,
+		&a.ApplicantID,
+		&a.ApplicantName,
+		&a.ApplicantRole,
+		&a.CoverLetter,
+		&a.IncludePortfolio,
+		&a.Stage,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+		&a.PortfolioSlug,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (m *JobApplicationModel) query(stmt string, args ...any) ([]*JobApplication, error) {
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applications []*JobApplication
+
+	for rows.Next() {
+		a, err := scanJobApplication(rows)
+		if err != nil {
+			return nil, err
+		}
+		applications = append(applications, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applications, nil
+}
+
+// Apply saves an application and returns its ID. Attached evaluations that
+// don't belong to the applicant are ignored. ErrDuplicateApplication is
+// returned if the applicant has already applied.
+func (m *JobApplicationModel) Apply(a *JobApplication) (int, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO job_applications (posting_id, applicant_id, cover_letter, include_portfolio)
+		VALUES (?, ?, ?, ?)`, a.PostingID, a.ApplicantID, a.CoverLetter, a.IncludePortfolio)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 &&
+			strings.Contains(mysqlErr.Message, "unique_application") {
+			return 0, ErrDuplicateApplication
+		}
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, evaluationID := range a.EvaluationIDs {
+		_, err = tx.Exec(`INSERT IGNORE INTO job_application_evaluations (application_id, evaluation_id)
+			SELECT ?, id FROM performance_evaluations WHERE id = ? AND user_id = ?`,
+			id, evaluationID, a.ApplicantID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int(id), tx.Commit()
+}
+
+// Get retrieves an application to a posting
+func (m *JobApplicationModel) Get(id, postingID int) (*JobApplication, error) {
+	stmt := `SELECT ` + jobApplicationColumns + ` ` + jobApplicationFrom + `
+		WHERE a.id = ? AND a.posting_id = ?`
+
+	a, err := scanJobApplication(m.DB.QueryRow(stmt, id, postingID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// Find returns a user's application to a posting, if they have applied
+func (m *JobApplicationModel) Find(postingID, applicantID int) (*JobApplication, error) {
+	stmt := `SELECT ` + jobApplicationColumns + ` ` + jobApplicationFrom + `
+		WHERE a.posting_id = ? AND a.applicant_id = ?`
+
+	a, err := scanJobApplication(m.DB.QueryRow(stmt, postingID, applicantID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return a, nil
+}
+
+// ListForPosting returns every application to a posting, oldest first
+func (m *JobApplicationModel) ListForPosting(postingID int) ([]*JobApplication, error) {
+	stmt := `SELECT ` + jobApplicationColumns + ` ` + jobApplicationFrom + `
+		WHERE a.posting_id = ?
+		ORDER BY a.created_at, a.id`
+
+	return m.query(stmt, postingID)
+}
+
+// ListForApplicant returns a user's applications, newest first
+func (m *JobApplicationModel) ListForApplicant(applicantID int) ([]*JobApplication, error) {
+	stmt := `SELECT ` + jobApplicationColumns + ` ` + jobApplicationFrom + `
+		WHERE a.applicant_id = ?
+		ORDER BY a.created_at DESC, a.id DESC`
+
+	return m.query(stmt, applicantID)
+}
+
+// SetStage moves an application to another pipeline stage
+func (m *JobApplicationModel) SetStage(id, postingID int, stage ApplicationStage) error {
+	stmt := `UPDATE job_applications SET stage = ? WHERE id = ? AND posting_id = ?`
+
+	_, err := m.DB.Exec(stmt, stage, id, postingID)
+	return err
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// JobRunStatus represents the outcome of a background job run
+type JobRunStatus string
+
+const (
+	JobRunning   JobRunStatus = "running"
+	JobSucceeded JobRunStatus = "succeeded"
+	JobFailed    JobRunStatus = "failed"
+)
+
+// JobRun represents one execution of a background job
+type JobRun struct {
+	ID         int
+	JobName    string
+	Instance   string
+	StartedAt  time.Time
+	FinishedAt sql.NullTime
+	Status     JobRunStatus
+	Error      sql.NullString
+}
+
+// JobRunModel wraps a database connection pool
+type JobRunModel struct {
+	DB *sql.DB
+}
+
+// Start records that a job has started running and returns the run ID
+func (m *JobRunModel) Start(jobName, instance string) (int, error) {
+	stmt := `INSERT INTO job_runs (job_name, instance, started_at, status)
+		VALUES (?, ?, UTC_TIMESTAMP(), 'running')`
+
+	result, err := m.DB.Exec(stmt, jobName, instance)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// Finish records the outcome of a run. A nil runErr marks the run as succeeded.
+func (m *JobRunModel) Finish(id int, runErr error) error {
+	status := JobSucceeded
+	var message sql.NullString
+	if runErr != nil {
+		status = JobFailed
+		message = sql.NullString{String: runErr.Error(), Valid: true}
+	}
+
+	stmt := `UPDATE job_runs SET finished_at = UTC_TIMESTAMP(), status = ?, error = ? WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, status, message, id)
+	return err
+}
+
+// LastSuccess returns the start time of the most recent successful run of a
+// job. ErrNoRecord is returned if the job has never succeeded.
+func (m *JobRunModel) LastSuccess(jobName string) (time.Time, error) {
+	var startedAt time.Time
+
+	stmt := `SELECT started_at FROM job_runs
+		WHERE job_name = ? AND status = 'succeeded'
+		ORDER BY started_at DESC LIMIT 1`
+
+	err := m.DB.QueryRow(stmt, jobName).Scan(&startedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, ErrNoRecord
+		}
+		return time.Time{}, err
+	}
+
+	return startedAt, nil
+}
+
+// Latest returns the most recent job runs across all jobs
+func (m *JobRunModel) Latest(limit int) ([]*JobRun, error) {
+	stmt := `SELECT id, job_name, instance, started_at, finished_at, status, error
+		FROM job_runs ORDER BY started_at DESC LIMIT ?`
+
+	rows, err := m.DB.Query(stmt, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []*JobRun
+
+	for rows.Next() {
+		var run JobRun
+		err = rows.Scan(
+			&run.ID,
+			&run.JobName,
+			&run.Instance,
+			&run.StartedAt,
+			&run.FinishedAt,
+			&run.Status,
+			&run.Error,
+		)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, &run)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// DeleteOlderThan prunes run history older than the given age
+func (m *JobRunModel) DeleteOlderThan(age time.Duration) error {
+	stmt := `DELETE FROM job_runs WHERE started_at < ?`
+
+	_, err := m.DB.Exec(stmt, time.Now().UTC().Add(-age))
+	return err
+}
@@ -0,0 +1,685 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Mentor is a lawyer who offers to judge students' moots
+type Mentor struct {
+	UserID   int
+	Name     string
+	Headline string
+	IsListed bool
+
+	// TimeZone is the IANA time zone the mentor's availability is shown in
+	TimeZone string
+
+	// Expertise lists the case types the mentor judges
+	Expertise []string
+
+	YearsOfExperience int
+	Specialization    string
+	FirmName          string
+	Bio               string
+	Verified          bool
+
+	Reviews   int
+	Rating    sql.NullFloat64
+	OpenSlots int
+}
+
+// MentorSlot is a time a mentor is available
+type MentorSlot struct {
+	ID       int
+	StartsAt time.Time
+
+	// RequestID is the request holding the slot, if any
+	RequestID sql.NullInt64
+}
+
+// Open reports whether the slot can be requested
+func (s *MentorSlot) Open() bool {
+	return !s.RequestID.Valid
+}
+
+// MentorModel wraps a database connection pool
+type MentorModel struct {
+	DB *sql.DB
+}
+
+// mentorColumns and mentorFrom select active lawyers' mentor profiles, with
+// their lawyer profile, verification status and the reviews students have
+// left them. A lawyer is verified while their latest bar registration
+// submission is approved.
+const mentorColumns = `m.user_id, u.name, m.headline, m.is_listed, m.time_zone,
+	COALESCE((SELECT GROUP_CONCAT(e.case_type ORDER BY e.case_type) FROM mentor_expertise e WHERE e.user_id = m.user_id), ''),
+	COALESCE(lp.years_of_experience, 0), COALESCE(lp.specialization, ''), COALESCE(lp.firm_name, ''), COALESCE(lp.bio, ''),
+	COALESCE(lv.status = 'approved', FALSE),
+	COALESCE(r.reviews, 0), r.rating,
+	(SELECT COUNT(*) FROM mentor_slots s WHERE s.mentor_id = m.user_id AND s.request_id IS NULL AND s.starts_at > UTC_TIMESTAMP()) AS open_slots`
+
+const mentorFrom = `FROM mentor_profiles m
+	JOIN users u ON u.id = m.user_id
+	LEFT JOIN lawyer_profiles lp ON lp.user_id = m.user_id
+	LEFT JOIN lawyer_verifications lv ON lv.id = (
+		SELECT MAX(id) FROM lawyer_verifications WHERE user_id = m.user_id AND status = 'approved')
+	LEFT JOIN (
+		SELECT rv.reviewee_id, COUNT(*) AS reviews, AVG(rv.rating) AS rating
+		FROM mentoring_reviews rv JOIN mentoring_requests rq ON rq.id = rv.request_id AND rq.mentor_id = rv.reviewee_id
+		GROUP BY rv.reviewee_id
+	) r ON r.reviewee_id = m.user_id
+	WHERE u.is_active = TRUE AND u.role = 'lawyer'`
+
+func scanMentor(row rowScanner, extra ...any) (*Mentor, error) {
+	var m Mentor
+	var expertise string
+
+	dest := append(extra,
+		&m.UserID,
+		&m.Name,
+		&m.Headline,
+		&m.IsListed,
+		&m.TimeZone,
+		&expertise,
+		&m.YearsOfExperience,
+		&m.Specialization,
+		&m.FirmName,
+		&m.Bio,
+		&m.Verified,
+		&m.Reviews,
+		&m.Rating,
+		&m.OpenSlots,
+	)
+
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+
+	if expertise != "" {
+		m.Expertise = strings.Split(expertise, ",")
+	}
+	return &m, nil
+}
+
+// Get retrieves a lawyer's mentor profile
+func (m *MentorModel) Get(userID int) (*Mentor, error) {
+	stmt := `SELECT ` + mentorColumns + ` ` + mentorFrom + ` AND m.user_id = ?`
+
+	mentor, err := scanMentor(m.DB.QueryRow(stmt, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return mentor, nil
+}
+
+// List returns a page of listed mentors and the total number of matches,
+// those with open slots first. An empty caseType lists every mentor;
+// verifiedOnly leaves out lawyers who aren't verified.
+func (m *MentorModel) List(caseType string, verifiedOnly bool, limit, offset int) ([]*Mentor, int, error) {
+	stmt := `SELECT COUNT(*) OVER(), ` + mentorColumns + ` ` + mentorFrom + `
+		AND m.is_listed = TRUE
+		AND (? = '' OR EXISTS (SELECT 1 FROM mentor_expertise e WHERE e.user_id = m.user_id AND e.case_type = ?))
+		AND (? = FALSE OR lv.status = 'approved')
+		ORDER BY open_slots > 0 DESC, r.rating IS NULL, r.rating DESC, u.name LIMIT ? OFFSET ?`
+
+	rows, err := m.DB.Query(stmt, caseType, caseType, verifiedOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var mentors []*Mentor
+	total := 0
+
+	for rows.Next() {
+		mentor, err := scanMentor(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		mentors = append(mentors, mentor)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return mentors, total, nil
+}
+
+// Upsert creates or replaces a lawyer's mentor profile and expertise
+func (m *MentorModel) Upsert(userID int, headline string, listed bool, timeZone string, expertise []string) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO mentor_profiles (user_id, headline, is_listed, time_zone)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE headline = VALUES(headline), is_listed = VALUES(is_listed),
+		time_zone = VALUES(time_zone), updated_at = UTC_TIMESTAMP()`,
+		userID, headline, listed, timeZone)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM mentor_expertise WHERE user_id = ?`, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(expertise) > 0 {
+		args := make([]any, 0, 2*len(expertise))
+		for _, caseType := range expertise {
+			args = append(args, userID, caseType)
+		}
+
+		stmt := `INSERT IGNORE INTO mentor_expertise (user_id, case_type) VALUES (?, ?)` +
+			strings.Repeat(", (?, ?)", len(expertise)-1)
+
+		_, err = tx.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Slots returns a mentor's slots that haven't started, earliest first. Only
+// open slots are returned unless all is set.
+func (m *MentorModel) Slots(mentorID int, all bool) ([]*MentorSlot, error) {
+	stmt := `SELECT id, starts_at, request_id FROM mentor_slots
+		WHERE mentor_id = ? AND starts_at > UTC_TIMESTAMP() AND (? = TRUE OR request_id IS NULL)
+		ORDER BY starts_at`
+
+	rows, err := m.DB.Query(stmt, mentorID, all)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []*MentorSlot
+
+	for rows.Next() {
+		var s MentorSlot
+		err = rows.Scan(&s.ID, &s.StartsAt, &s.RequestID)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return slots, nil
+}
+
+// AddSlots publishes times a mentor is available. Times already published
+// are skipped.
+func (m *MentorModel) AddSlots(mentorID int, starts []time.Time) error {
+	args := make([]any, 0, 2*len(starts))
+	for _, t := range starts {
+		args = append(args, mentorID, t.UTC())
+	}
+
+	stmt := `INSERT IGNORE INTO mentor_slots (mentor_id, starts_at) VALUES (?, ?)` +
+		strings.Repeat(", (?, ?)", len(starts)-1)
+
+	_, err := m.DB.Exec(stmt, args...)
+	return err
+}
+
+// DeleteSlot withdraws an open slot. It returns ErrNoRecord if the slot has
+// been requested.
+func (m *MentorModel) DeleteSlot(id, mentorID int) error {
+	stmt := `DELETE FROM mentor_slots WHERE id = ? AND mentor_id = ? AND request_id IS NULL`
+
+	result, err := m.DB.Exec(stmt, id, mentorID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+// MentoringStatus is where a mentoring request is in its life
+type MentoringStatus string
+
+const (
+	MentoringPending   MentoringStatus = "pending"
+	MentoringAccepted  MentoringStatus = "accepted"
+	MentoringDeclined  MentoringStatus = "declined"
+	MentoringCancelled MentoringStatus = "cancelled"
+)
+
+// MentoringRequest is a student's request for a mentor to judge their moot
+type MentoringRequest struct {
+	ID           int
+	MentorID     int
+	MentorName   string
+	MentorEmail  string
+	StudentID    int
+	StudentName  string
+	StudentEmail string
+	StartsAt     time.Time
+	TimeZone     string
+	CaseType     string
+	Difficulty   string
+
+	// Role is the counsel role the student argues
+	Role    MootRole
+	Message string
+	Status  MentoringStatus
+
+	// MootSessionID is the moot scheduled when the request was accepted, and
+	// MootStatus its status
+	MootSessionID sql.NullInt64
+	MootStatus    MootStatus
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Pending reports whether the mentor hasn't answered and still can
+func (r *MentoringRequest) Pending() bool {
+	return r.Status == MentoringPending && r.StartsAt.After(time.Now())
+}
+
+// Expired reports whether the requested time passed without an answer
+func (r *MentoringRequest) Expired() bool {
+	return r.Status == MentoringPending && !r.StartsAt.After(time.Now())
+}
+
+// Booked reports whether the moot is scheduled and hasn't started
+func (r *MentoringRequest) Booked() bool {
+	return r.Status == MentoringAccepted && r.MootStatus == MootSetup
+}
+
+// Happened reports whether the mentored moot went ahead
+func (r *MentoringRequest) Happened() bool {
+	return r.Status == MentoringAccepted && (r.MootStatus == MootInProgress || r.MootStatus == MootCompleted)
+}
+
+// Cancelled reports whether either side called the moot off, or it was
+// cancelled because someone didn't turn up
+func (r *MentoringRequest) Cancelled() bool {
+	return r.Status == MentoringCancelled || r.MootStatus == MootCancelled
+}
+
+// IsMentor reports whether userID is the mentor asked
+func (r *MentoringRequest) IsMentor(userID int) bool {
+	return r.MentorID == userID
+}
+
+// OtherID returns the ID of the other party to userID
+func (r *MentoringRequest) OtherID(userID int) int {
+	if r.MentorID == userID {
+		return r.StudentID
+	}
+	return r.MentorID
+}
+
+// MentoringReview is what one party thought of a mentored moot
+type MentoringReview struct {
+	RequestID    int
+	ReviewerID   int
+	ReviewerName string
+	RevieweeID   int
+	Rating       int
+	Comment      string
+	CaseType     string
+	CreatedAt    time.Time
+}
+
+// MentoringRequestModel wraps a database connection pool
+type MentoringRequestModel struct {
+	DB *sql.DB
+}
+
+const mentoringRequestColumns = `q.id, q.mentor_id, mu.name, mu.email, q.student_id, su.name, su.email,
+	q.starts_at, q.time_zone, q.case_type, q.difficulty, q.role, q.message, q.status,
+	q.moot_session_id, COALESCE(ms.status, ''), q.created_at, q.updated_at`
+
+const mentoringRequestFrom = `FROM mentoring_requests q
+	JOIN users mu ON mu.id = q.mentor_id
+	JOIN users su ON su.id = q.student_id
+	LEFT JOIN moot_sessions ms ON ms.id = q.moot_session_id`
+
+func scanMentoringRequest(row rowScanner) (*MentoringRequest, error) {
+	var r MentoringRequest
+	err := row.Scan(
+		&r.ID,
+		&r.MentorID,
+		&r.MentorName,
+		&r.MentorEmail,
+		&r.StudentID,
+		&r.StudentName,
+		&r.StudentEmail,
+		&r.StartsAt,
+		&r.TimeZone,
+		&r.CaseType,
+		&r.Difficulty,
+		&r.Role,
+		&r.Message,
+		&r.Status,
+		&r.MootSessionID,
+		&r.MootStatus,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Request asks a mentor to judge a student's moot at one of their open
+// slots, holding the slot, and returns the request's ID. It returns
+// ErrNoRecord if the slot has been taken, withdrawn or has passed.
+func (m *MentoringRequestModel) Request(slotID, mentorID, studentID int, caseType, difficulty string, role MootRole, message string) (int, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var startsAt time.Time
+	var timeZone string
+
+	err = tx.QueryRow(`SELECT s.starts_at, p.time_zone FROM mentor_slots s
+		JOIN mentor_profiles p ON p.user_id = s.mentor_id
+		WHERE s.id = ? AND s.mentor_id = ? AND s.request_id IS NULL AND s.starts_at > UTC_TIMESTAMP() AND p.is_listed = TRUE
+		FOR UPDATE`, slotID, mentorID).Scan(&startsAt, &timeZone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoRecord
+		}
+		return 0, err
+	}
+
+	result, err := tx.Exec(`INSERT INTO mentoring_requests
+		(mentor_id, student_id, starts_at, time_zone, case_type, difficulty, role, message)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		mentorID, studentID, startsAt, timeZone, caseType, difficulty, role, message)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`UPDATE mentor_slots SET request_id = ? WHERE id = ?`, id, slotID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), tx.Commit()
+}
+
+// Get retrieves a request that userID made or was sent
+func (m *MentoringRequestModel) Get(id, userID int) (*MentoringRequest, error) {
+	stmt := `SELECT ` + mentoringRequestColumns + ` ` + mentoringRequestFrom + `
+		WHERE q.id = ? AND (q.mentor_id = ? OR q.student_id = ?)`
+
+	r, err := scanMentoringRequest(m.DB.QueryRow(stmt, id, userID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+// ListForUser returns the requests userID made or was sent, latest first
+func (m *MentoringRequestModel) ListForUser(userID int) ([]*MentoringRequest, error) {
+	stmt := `SELECT ` + mentoringRequestColumns + ` ` + mentoringRequestFrom + `
+		WHERE q.mentor_id = ? OR q.student_id = ?
+		ORDER BY q.starts_at DESC, q.id DESC
+		LIMIT 100`
+
+	rows, err := m.DB.Query(stmt, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*MentoringRequest
+
+	for rows.Next() {
+		r, err := scanMentoringRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// CountPending returns how many of a student's requests await an answer
+func (m *MentoringRequestModel) CountPending(studentID int) (int, error) {
+	stmt := `SELECT COUNT(*) FROM mentoring_requests
+		WHERE student_id = ? AND status = 'pending' AND starts_at > UTC_TIMESTAMP()`
+
+	var n int
+	err := m.DB.QueryRow(stmt, studentID).Scan(&n)
+	return n, err
+}
+
+// Accept agrees to a pending request, scheduling the student's moot with the
+// mentor as judge, and returns the moot's ID. Both are counted as coming, and
+// the moot is cancelled if either doesn't check in. ErrNoRecord is returned
+// if the request has been answered, cancelled or has passed.
+func (m *MentoringRequestModel) Accept(id, mentorID int) (int, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var r MentoringRequest
+	err = tx.QueryRow(`SELECT student_id, starts_at, time_zone, case_type, difficulty, role
+		FROM mentoring_requests
+		WHERE id = ? AND mentor_id = ? AND status = 'pending' AND starts_at > UTC_TIMESTAMP()
+		FOR UPDATE`, id, mentorID).Scan(&r.StudentID, &r.StartsAt, &r.TimeZone, &r.CaseType, &r.Difficulty, &r.Role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoRecord
+		}
+		return 0, err
+	}
+
+	seats := []MootSeat{
+		{UserID: r.StudentID, Role: r.Role, RSVP: RSVPYes},
+		{UserID: mentorID, Role: MootJudge, RSVP: RSVPYes},
+	}
+
+	mootID, err := insertScheduledMoot(tx, r.StudentID, "dual_player", r.CaseType, r.Difficulty, r.StartsAt, r.TimeZone, NoShowCancel, seats)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`UPDATE mentoring_requests SET status = 'accepted', moot_session_id = ?, updated_at = UTC_TIMESTAMP()
+		WHERE id = ?`, mootID, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return mootID, tx.Commit()
+}
+
+// Decline turns down a pending request, freeing its slot. It returns
+// ErrNoRecord if the request isn't pending.
+func (m *MentoringRequestModel) Decline(id, mentorID int) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE mentoring_requests SET status = 'declined', updated_at = UTC_TIMESTAMP()
+		WHERE id = ? AND mentor_id = ? AND status = 'pending'`, id, mentorID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+
+	_, err = tx.Exec(`UPDATE mentor_slots SET request_id = NULL WHERE request_id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Cancel withdraws a request, or calls off its moot if it was accepted,
+// freeing the slot. Either party can cancel until the moot starts; after
+// that, or once the request has been declined or cancelled, ErrNoRecord is
+// returned.
+func (m *MentoringRequestModel) Cancel(id, userID int) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var mootID sql.NullInt64
+	err = tx.QueryRow(`SELECT moot_session_id FROM mentoring_requests
+		WHERE id = ? AND (mentor_id = ? OR student_id = ?) AND status IN ('pending', 'accepted')
+		FOR UPDATE`, id, userID, userID).Scan(&mootID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoRecord
+		}
+		return err
+	}
+
+	if mootID.Valid {
+		var status MootStatus
+		err = tx.QueryRow(`SELECT status FROM moot_sessions WHERE id = ? FOR UPDATE`, mootID.Int64).Scan(&status)
+		if err != nil {
+			return err
+		}
+
+		switch status {
+		case MootSetup:
+			_, err = tx.Exec(`UPDATE moot_sessions SET status = 'cancelled', sequence = sequence + 1 WHERE id = ?`, mootID.Int64)
+			if err != nil {
+				return err
+			}
+		case MootInProgress, MootCompleted:
+			return ErrNoRecord
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE mentoring_requests SET status = 'cancelled', updated_at = UTC_TIMESTAMP() WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE mentor_slots SET request_id = NULL WHERE request_id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Review records what reviewerID thought of a mentored moot and of the
+// other party. It returns ErrDuplicateReview if they have already reviewed
+// it.
+func (m *MentoringRequestModel) Review(requestID, reviewerID, revieweeID, rating int, comment string) error {
+	stmt := `INSERT INTO mentoring_reviews (request_id, reviewer_id, reviewee_id, rating, comment, created_at)
+		VALUES (?, ?, ?, ?, ?, UTC_TIMESTAMP())`
+
+	_, err := m.DB.Exec(stmt, requestID, reviewerID, revieweeID, rating, comment)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return ErrDuplicateReview
+		}
+		return err
+	}
+	return nil
+}
+
+const mentoringReviewColumns = `r.request_id, r.reviewer_id, u.name, r.reviewee_id, r.rating, r.comment, q.case_type, r.created_at`
+
+const mentoringReviewFrom = `FROM mentoring_reviews r
+	JOIN users u ON u.id = r.reviewer_id
+	JOIN mentoring_requests q ON q.id = r.request_id`
+
+// Reviews returns the reviews left for a request
+func (m *MentoringRequestModel) Reviews(requestID int) ([]*MentoringReview, error) {
+	stmt := `SELECT ` + mentoringReviewColumns + ` ` + mentoringReviewFrom + `
+		WHERE r.request_id = ? ORDER BY r.created_at`
+
+	return m.listReviews(stmt, requestID)
+}
+
+// ReviewsOf returns the latest reviews students have left a mentor
+func (m *MentoringRequestModel) ReviewsOf(mentorID, limit int) ([]*MentoringReview, error) {
+	stmt := `SELECT ` + mentoringReviewColumns + ` ` + mentoringReviewFrom + `
+		WHERE r.reviewee_id = ? AND q.mentor_id = r.reviewee_id
+		ORDER BY r.created_at DESC LIMIT ?`
+
+	return m.listReviews(stmt, mentorID, limit)
+}
+
+func (m *MentoringRequestModel) listReviews(stmt string, args ...any) ([]*MentoringReview, error) {
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*MentoringReview
+
+	for rows.Next() {
+		var r MentoringReview
+		err = rows.Scan(&r.RequestID, &r.ReviewerID, &r.ReviewerName, &r.RevieweeID, &r.Rating, &r.Comment, &r.CaseType, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, &r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
@@ -0,0 +1,473 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Conversation statuses. A recruiter's first message is a contact request
+// until the candidate accepts or declines it.
+const (
+	ConversationPending  = "pending"
+	ConversationAccepted = "accepted"
+	ConversationDeclined = "declined"
+)
+
+// Conversation is a message thread between a recruiter and a candidate
+type Conversation struct {
+	ID            int
+	RecruiterID   int
+	RecruiterName string
+	CompanyName   string
+	CandidateID   int
+	CandidateName string
+	Subject       string
+	Status        string
+	CreatedAt     time.Time
+	LastMessageAt time.Time
+
+	// Filled in by ListForUser, from the point of view of the user listing
+	Unread      int
+	LastMessage string
+}
+
+// Pending reports whether the candidate has yet to answer the contact request
+func (c *Conversation) Pending() bool {
+	return c.Status == ConversationPending
+}
+
+// Accepted reports whether both sides can send messages
+func (c *Conversation) Accepted() bool {
+	return c.Status == ConversationAccepted
+}
+
+// Declined reports whether the candidate turned down the contact request
+func (c *Conversation) Declined() bool {
+	return c.Status == ConversationDeclined
+}
+
+// IsRecruiter reports whether userID is the recruiter in the conversation
+func (c *Conversation) IsRecruiter(userID int) bool {
+	return c.RecruiterID == userID
+}
+
+// OtherID returns the ID of the participant who isn't userID
+func (c *Conversation) OtherID(userID int) int {
+	if c.RecruiterID == userID {
+		return c.CandidateID
+	}
+	return c.RecruiterID
+}
+
+// Message is one message in a conversation
+type Message struct {
+	ID             int
+	ConversationID int
+	SenderID       int
+	SenderName     string
+	Body           string
+	CreatedAt      time.Time
+}
+
+// ConversationReport is a conversation reported to administrators
+type ConversationReport struct {
+	ID             int
+	ConversationID int
+	Subject        string
+	ReporterID     int
+	ReporterName   string
+	ReportedID     int
+	ReportedName   string
+	Reason         string
+	CreatedAt      time.Time
+}
+
+// ConversationModel wraps a database connection pool
+type ConversationModel struct {
+	DB *sql.DB
+}
+
+const conversationColumns = `c.id, c.recruiter_id, r.name, COALESCE(rp.company_name, ''),
+	c.candidate_id, u.name, c.subject, c.status, c.created_at, c.last_message_at`
+
+const conversationFrom = `FROM conversations c
+	JOIN users r ON r.id = c.recruiter_id
+	JOIN users u ON u.id = c.candidate_id
+	LEFT JOIN recruiter_profiles rp ON rp.user_id = c.recruiter_id`
+
+func scanConversation(row rowScanner, extra ...any) (*Conversation, error) {
+	var c Conversation
+
+	dest := append([]any{
+		&c.ID,
+		&c.RecruiterID,
+		&c.RecruiterName,
+		&c.CompanyName,
+		&c.CandidateID,
+		&c.CandidateName,
+		&c.Subject,
+		&c.Status,
+		&c.CreatedAt,
+		&c.LastMessageAt,
+	}, extra...)
+
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Start opens a conversation with the recruiter's first message and returns
+// its ID. A recruiter can only have one conversation with each candidate;
+// ErrDuplicateConversation is returned if one already exists.
+func (m *ConversationModel) Start(recruiterID, candidateID int, subject, body string) (int, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO conversations
+		(recruiter_id, candidate_id, subject, created_at, last_message_at, recruiter_read_at)
+		VALUES (?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP(), UTC_TIMESTAMP())`,
+		recruiterID, candidateID, subject)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 &&
+			strings.Contains(mysqlErr.Message, "unique_conversation") {
+			return 0, ErrDuplicateConversation
+		}
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`INSERT INTO messages (conversation_id, sender_id, body, created_at)
+		VALUES (?, ?, ?, UTC_TIMESTAMP())`, id, recruiterID, body)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), tx.Commit()
+}
+
+// Get retrieves a conversation that userID takes part in
+func (m *ConversationModel) Get(id, userID int) (*Conversation, error) {
+	stmt := `SELECT ` + conversationColumns + ` ` + conversationFrom + `
+		WHERE c.id = ? AND (c.recruiter_id = ? OR c.candidate_id = ?)`
+
+	c, err := scanConversation(m.DB.QueryRow(stmt, id, userID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reported retrieves a conversation for an administrator, but only if one of
+// its participants has reported it
+func (m *ConversationModel) Reported(id int) (*Conversation, error) {
+	stmt := `SELECT ` + conversationColumns + ` ` + conversationFrom + `
+		WHERE c.id = ? AND EXISTS (SELECT 1 FROM conversation_reports cr WHERE cr.conversation_id = c.id)`
+
+	c, err := scanConversation(m.DB.QueryRow(stmt, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// Find returns the recruiter's conversation with a candidate, if there is one
+func (m *ConversationModel) Find(recruiterID, candidateID int) (*Conversation, error) {
+	stmt := `SELECT ` + conversationColumns + ` ` + conversationFrom + `
+		WHERE c.recruiter_id = ? AND c.candidate_id = ?`
+
+	c, err := scanConversation(m.DB.QueryRow(stmt, recruiterID, candidateID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// unreadMessages counts the messages in conversation c that userID (the
+// first parameter, repeated three times) hasn't read. Candidates don't see
+// new messages in conversations they declined.
+const unreadMessages = `SELECT COUNT(*) FROM messages m
+	WHERE m.conversation_id = c.id AND m.sender_id <> ?
+	AND ((c.recruiter_id = ? AND (c.recruiter_read_at IS NULL OR m.created_at > c.recruiter_read_at))
+		OR (c.candidate_id = ? AND c.status <> 'declined'
+			AND (c.candidate_read_at IS NULL OR m.created_at > c.candidate_read_at)))`
+
+// ListForUser returns every conversation userID takes part in, most recent
+// activity first, with their unread message counts
+func (m *ConversationModel) ListForUser(userID int) ([]*Conversation, error) {
+	stmt := `SELECT ` + conversationColumns + `, (` + unreadMessages + `),
+		(SELECT body FROM messages lm WHERE lm.conversation_id = c.id ORDER BY lm.created_at DESC, lm.id DESC LIMIT 1)
+		` + conversationFrom + `
+		WHERE c.recruiter_id = ? OR c.candidate_id = ?
+		ORDER BY c.last_message_at DESC, c.id DESC`
+
+	rows, err := m.DB.Query(stmt, userID, userID, userID, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversations []*Conversation
+
+	for rows.Next() {
+		var last string
+		var unread int
+		c, err := scanConversation(rows, &unread, &last)
+		if err != nil {
+			return nil, err
+		}
+		c.Unread = unread
+		c.LastMessage = truncate(last, 120)
+		conversations = append(conversations, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return conversations, nil
+}
+
+// UnreadCount returns the number of unread messages across all of a user's
+// conversations
+func (m *ConversationModel) UnreadCount(userID int) (int, error) {
+	stmt := `SELECT COALESCE(SUM((` + unreadMessages + `)), 0) FROM conversations c
+		WHERE c.recruiter_id = ? OR c.candidate_id = ?`
+
+	var count int
+	err := m.DB.QueryRow(stmt, userID, userID, userID, userID, userID).Scan(&count)
+	return count, err
+}
+
+// Messages returns the messages in a conversation, oldest first
+func (m *ConversationModel) Messages(conversationID int) ([]*Message, error) {
+	stmt := `SELECT m.id, m.conversation_id, m.sender_id, u.name, m.body, m.created_at
+		FROM messages m
+		JOIN users u ON u.id = m.sender_id
+		WHERE m.conversation_id = ?
+		ORDER BY m.created_at, m.id`
+
+	rows, err := m.DB.Query(stmt, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*Message
+
+	for rows.Next() {
+		var msg Message
+		err = rows.Scan(&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.SenderName, &msg.Body, &msg.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, &msg)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// Send adds a message to a conversation. Sending a message also marks the
+// conversation as read by the sender.
+func (m *ConversationModel) Send(c *Conversation, senderID int, body string) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO messages (conversation_id, sender_id, body, created_at)
+		VALUES (?, ?, ?, UTC_TIMESTAMP())`, c.ID, senderID, body)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE conversations SET last_message_at = UTC_TIMESTAMP() WHERE id = ?`, c.ID)
+	if err != nil {
+		return err
+	}
+
+	err = markRead(tx, c, senderID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+func markRead(e execer, c *Conversation, userID int) error {
+	column := "candidate_read_at"
+	if c.IsRecruiter(userID) {
+		column = "recruiter_read_at"
+	}
+
+	_, err := e.Exec(`UPDATE conversations SET `+column+` = UTC_TIMESTAMP() WHERE id = ?`, c.ID)
+	return err
+}
+
+// MarkRead records that userID has read every message in the conversation
+func (m *ConversationModel) MarkRead(c *Conversation, userID int) error {
+	return markRead(m.DB, c, userID)
+}
+
+// Answer records the candidate's response to a pending contact request. It
+// returns ErrNoRecord if the request has already been answered.
+func (m *ConversationModel) Answer(id, candidateID int, accept bool) error {
+	status := ConversationDeclined
+	if accept {
+		status = ConversationAccepted
+	}
+
+	stmt := `UPDATE conversations SET status = ?, candidate_read_at = UTC_TIMESTAMP()
+		WHERE id = ? AND candidate_id = ? AND status = 'pending'`
+
+	result, err := m.DB.Exec(stmt, status, id, candidateID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
+
+// CountStartedSince returns the number of conversations a recruiter has
+// started since the given time
+func (m *ConversationModel) CountStartedSince(recruiterID int, since time.Time) (int, error) {
+	stmt := `SELECT COUNT(*) FROM conversations WHERE recruiter_id = ? AND created_at >= ?`
+
+	var count int
+	err := m.DB.QueryRow(stmt, recruiterID, since.UTC()).Scan(&count)
+	return count, err
+}
+
+// Block stops all messages between blockerID and blockedID. A pending
+// contact request between them is declined.
+func (m *ConversationModel) Block(blockerID, blockedID int) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT IGNORE INTO user_blocks (blocker_id, blocked_id, created_at)
+		VALUES (?, ?, UTC_TIMESTAMP())`, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE conversations SET status = 'declined'
+		WHERE status = 'pending' AND ((recruiter_id = ? AND candidate_id = ?) OR (recruiter_id = ? AND candidate_id = ?))`,
+		blockerID, blockedID, blockedID, blockerID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Unblock lets blockedID message blockerID again
+func (m *ConversationModel) Unblock(blockerID, blockedID int) error {
+	_, err := m.DB.Exec(`DELETE FROM user_blocks WHERE blocker_id = ? AND blocked_id = ?`, blockerID, blockedID)
+	return err
+}
+
+// HasBlocked reports whether blockerID has blocked blockedID
+func (m *ConversationModel) HasBlocked(blockerID, blockedID int) (bool, error) {
+	var blocked bool
+	err := m.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM user_blocks WHERE blocker_id = ? AND blocked_id = ?)`,
+		blockerID, blockedID).Scan(&blocked)
+	return blocked, err
+}
+
+// Blocked reports whether either user has blocked the other
+func (m *ConversationModel) Blocked(a, b int) (bool, error) {
+	var blocked bool
+	err := m.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM user_blocks
+		WHERE (blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?))`,
+		a, b, b, a).Scan(&blocked)
+	return blocked, err
+}
+
+// Report flags a conversation for administrators to review
+func (m *ConversationModel) Report(conversationID, reporterID int, reason string) error {
+	stmt := `INSERT INTO conversation_reports (conversation_id, reporter_id, reason, created_at)
+		VALUES (?, ?, ?, UTC_TIMESTAMP())`
+
+	_, err := m.DB.Exec(stmt, conversationID, reporterID, truncate(reason, 500))
+	return err
+}
+
+// Reports returns a page of reported conversations, newest first, and the
+// total number of reports
+func (m *ConversationModel) Reports(limit, offset int) ([]*ConversationReport, int, error) {
+	stmt := `SELECT COUNT(*) OVER(), cr.id, cr.conversation_id, c.subject, cr.reporter_id, rep.name,
+		IF(c.recruiter_id = cr.reporter_id, c.candidate_id, c.recruiter_id), other.name,
+		cr.reason, cr.created_at
+		FROM conversation_reports cr
+		JOIN conversations c ON c.id = cr.conversation_id
+		JOIN users rep ON rep.id = cr.reporter_id
+		JOIN users other ON other.id = IF(c.recruiter_id = cr.reporter_id, c.candidate_id, c.recruiter_id)
+		ORDER BY cr.created_at DESC, cr.id DESC LIMIT ? OFFSET ?`
+
+	rows, err := m.DB.Query(stmt, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var reports []*ConversationReport
+	total := 0
+
+	for rows.Next() {
+		var r ConversationReport
+		err = rows.Scan(&total, &r.ID, &r.ConversationID, &r.Subject, &r.ReporterID, &r.ReporterName,
+			&r.ReportedID, &r.ReportedName, &r.Reason, &r.CreatedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		reports = append(reports, &r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return reports, total, nil
+}
@@ -4,14 +4,74 @@ import "database/sql"
 
 // Models wraps all the model types
 type Models struct {
-	Users    *UserModel
-	Sessions *SessionModel
+	Users          *UserModel
+	Sessions       *SessionModel
+	APITokens      *APITokenModel
+	JobRuns        *JobRunModel
+	MootSessions   *MootSessionModel
+	Evaluations    *EvaluationModel
+	AuditLog       *AuditLogModel
+	PasswordResets *PasswordResetModel
+
+	StudentProfiles   *StudentProfileModel
+	LawyerProfiles    *LawyerProfileModel
+	RecruiterProfiles *RecruiterProfileModel
+
+	LawyerVerifications *LawyerVerificationModel
+	Portfolios          *PortfolioModel
+	Candidates          *CandidateModel
+	Shortlists          *ShortlistModel
+	CandidateNotes      *CandidateNoteModel
+	ProfileViews        *ProfileViewModel
+	Consents            *ConsentModel
+	Conversations       *ConversationModel
+	JobPostings         *JobPostingModel
+	JobApplications     *JobApplicationModel
+	Organisations       *OrganisationModel
+	Achievements        *AchievementModel
+	Notifications       *NotificationModel
+	Digests             *DigestModel
+	SavedSearches       *SavedSearchModel
+	IdentityReveals     *IdentityRevealModel
+	Interviews          *InterviewModel
+	Mentors             *MentorModel
+	MentoringRequests   *MentoringRequestModel
 }
 
 // NewModels returns a Models struct containing initialized model types
 func NewModels(db *sql.DB) *Models {
 	return &Models{
-		Users:    &UserModel{DB: db},
-		Sessions: &SessionModel{DB: db},
+		Users:          &UserModel{DB: db},
+		Sessions:       &SessionModel{DB: db},
+		APITokens:      &APITokenModel{DB: db},
+		JobRuns:        &JobRunModel{DB: db},
+		MootSessions:   &MootSessionModel{DB: db},
+		Evaluations:    &EvaluationModel{DB: db},
+		AuditLog:       &AuditLogModel{DB: db},
+		PasswordResets: &PasswordResetModel{DB: db},
+
+		StudentProfiles:   &StudentProfileModel{DB: db},
+		LawyerProfiles:    &LawyerProfileModel{DB: db},
+		RecruiterProfiles: &RecruiterProfileModel{DB: db},
+
+		LawyerVerifications: &LawyerVerificationModel{DB: db},
+		Portfolios:          &PortfolioModel{DB: db},
+		Candidates:          &CandidateModel{DB: db},
+		Shortlists:          &ShortlistModel{DB: db},
+		CandidateNotes:      &CandidateNoteModel{DB: db},
+		ProfileViews:        &ProfileViewModel{DB: db},
+		Consents:            &ConsentModel{DB: db},
+		Conversations:       &ConversationModel{DB: db},
+		JobPostings:         &JobPostingModel{DB: db},
+		JobApplications:     &JobApplicationModel{DB: db},
+		Organisations:       &OrganisationModel{DB: db},
+		Achievements:        &AchievementModel{DB: db},
+		Notifications:       &NotificationModel{DB: db},
+		Digests:             &DigestModel{DB: db},
+		SavedSearches:       &SavedSearchModel{DB: db},
+		IdentityReveals:     &IdentityRevealModel{DB: db},
+		Interviews:          &InterviewModel{DB: db},
+		Mentors:             &MentorModel{DB: db},
+		MentoringRequests:   &MentoringRequestModel{DB: db},
 	}
 }
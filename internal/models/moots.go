@@ -0,0 +1,554 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// MootStatus is where a moot session is in its life
+type MootStatus string
+
+const (
+	MootSetup      MootStatus = "setup"
+	MootInProgress MootStatus = "in_progress"
+	MootCompleted  MootStatus = "completed"
+	MootCancelled  MootStatus = "cancelled"
+)
+
+// MootRole is a participant's part in a moot
+type MootRole string
+
+const (
+	MootAppellant  MootRole = "appellant_counsel"
+	MootRespondent MootRole = "respondent_counsel"
+	MootJudge      MootRole = "judge"
+)
+
+// MootRoles lists the roles in a moot, in the order they are shown
+var MootRoles = []MootRole{MootAppellant, MootRespondent, MootJudge}
+
+// RSVP is a participant's answer to a moot invitation
+type RSVP string
+
+const (
+	RSVPPending RSVP = "pending"
+	RSVPYes     RSVP = "yes"
+	RSVPNo      RSVP = "no"
+)
+
+// NoShowPolicy is what happens to a scheduled moot when someone hasn't
+// checked in by the start
+type NoShowPolicy string
+
+const (
+	NoShowCancel NoShowPolicy = "cancel"
+	NoShowAI     NoShowPolicy = "ai"
+)
+
+// MootDuration is how long a scheduled moot is expected to last
+const MootDuration = time.Hour
+
+// MootSession is a moot court session
+type MootSession struct {
+	ID          int
+	SessionType string
+	CaseType    string
+	Difficulty  string
+	CreatedBy   int
+	CreatorName string
+	Status      MootStatus
+
+	// ScheduledAt is set for moots booked ahead for everyone to join at once
+	ScheduledAt sql.NullTime
+
+	// TimeZone is the IANA time zone the moot was scheduled in
+	TimeZone     string
+	NoShowPolicy NoShowPolicy
+
+	// Sequence increases with every change to the schedule, so calendar
+	// applications know which invite is the latest
+	Sequence int
+
+	ReminderSentAt sql.NullTime
+	CreatedAt      time.Time
+
+	// Participants are the people taking part. Filled in by Get and the
+	// methods listing scheduled moots.
+	Participants []*MootParticipant
+}
+
+// MootParticipant is a person taking part in a moot
+type MootParticipant struct {
+	UserID int
+	Name   string
+	Email  string
+	Role   MootRole
+
+	// IsAI is set when the AI took over the participant's role because they
+	// didn't turn up
+	IsAI bool
+
+	RSVP        RSVP
+	CheckedInAt sql.NullTime
+}
+
+// MootSeat assigns a role in a moot to a user
+type MootSeat struct {
+	UserID int
+	Role   MootRole
+
+	// RSVP is the user's answer if they have already given one
+	RSVP RSVP
+}
+
+// Upcoming reports whether the moot is scheduled and hasn't started
+func (s *MootSession) Upcoming() bool {
+	return s.Status == MootSetup && s.ScheduledAt.Valid
+}
+
+// Started reports whether the moot is under way or finished
+func (s *MootSession) Started() bool {
+	return s.Status == MootInProgress || s.Status == MootCompleted
+}
+
+// Cancelled reports whether the moot was called off
+func (s *MootSession) Cancelled() bool {
+	return s.Status == MootCancelled
+}
+
+// IsOrganiser reports whether userID scheduled the moot
+func (s *MootSession) IsOrganiser(userID int) bool {
+	return s.CreatedBy == userID
+}
+
+// Participant returns userID's place in the moot, or nil
+func (s *MootSession) Participant(userID int) *MootParticipant {
+	for _, p := range s.Participants {
+		if p.UserID == userID {
+			return p
+		}
+	}
+	return nil
+}
+
+// AIRoles returns the roles nobody was invited to take, which the AI plays
+func (s *MootSession) AIRoles() []MootRole {
+	var roles []MootRole
+	for _, role := range MootRoles {
+		taken := false
+		for _, p := range s.Participants {
+			if p.Role == role {
+				taken = true
+			}
+		}
+		if !taken {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// EndsAt returns when the scheduled moot is expected to finish
+func (s *MootSession) EndsAt() time.Time {
+	return s.ScheduledAt.Time.Add(MootDuration)
+}
+
+// MootSessionModel wraps a database connection pool
+type MootSessionModel struct {
+	DB *sql.DB
+}
+
+const mootSessionColumns = `s.id, s.session_type, COALESCE(s.case_type, ''), s.difficulty_level,
+	s.created_by, c.name, s.status, s.scheduled_at, s.time_zone, s.no_show_policy, s.sequence,
+	s.reminder_sent_at, s.created_at`
+
+const mootSessionFrom = `FROM moot_sessions s JOIN users c ON c.id = s.created_by`
+
+func scanMootSession(row rowScanner) (*MootSession, error) {
+	var s MootSession
+	err := row.Scan(
+		&s.ID,
+		&s.SessionType,
+		&s.CaseType,
+		&s.Difficulty,
+		&s.CreatedBy,
+		&s.CreatorName,
+		&s.Status,
+		&s.ScheduledAt,
+		&s.TimeZone,
+		&s.NoShowPolicy,
+		&s.Sequence,
+		&s.ReminderSentAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// DeleteStaleSetup removes moot sessions that have sat in the setup state,
+// waiting for invited participants, for longer than maxAge. Scheduled moots
+// are left for the no-show check. It returns the number of sessions removed.
+func (m *MootSessionModel) DeleteStaleSetup(maxAge time.Duration) (int64, error) {
+	stmt := `DELETE FROM moot_sessions WHERE status = 'setup' AND scheduled_at IS NULL AND created_at < ?`
+
+	result, err := m.DB.Exec(stmt, time.Now().UTC().Add(-maxAge))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Schedule books a moot for the given time and returns its ID. The organiser
+// must be one of the seats, and is counted as coming.
+func (m *MootSessionModel) Schedule(creatorID int, sessionType, caseType, difficulty string, startsAt time.Time, timeZone string, policy NoShowPolicy, seats []MootSeat) (int, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for i := range seats {
+		if seats[i].UserID == creatorID {
+			seats[i].RSVP = RSVPYes
+		}
+	}
+
+	id, err := insertScheduledMoot(tx, creatorID, sessionType, caseType, difficulty, startsAt, timeZone, policy, seats)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// insertScheduledMoot adds a scheduled moot and its participants as part of
+// tx, returning the moot's ID
+func insertScheduledMoot(tx *sql.Tx, creatorID int, sessionType, caseType, difficulty string, startsAt time.Time, timeZone string, policy NoShowPolicy, seats []MootSeat) (int, error) {
+	result, err := tx.Exec(`INSERT INTO moot_sessions
+		(session_type, case_type, difficulty_level, created_by, created_at, scheduled_at, time_zone, no_show_policy)
+		VALUES (?, ?, ?, ?, UTC_TIMESTAMP(), ?, ?, ?)`,
+		sessionType, caseType, difficulty, creatorID, startsAt.UTC(), timeZone, policy)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	args := make([]any, 0, 4*len(seats))
+	for _, seat := range seats {
+		rsvp := seat.RSVP
+		if rsvp == "" {
+			rsvp = RSVPPending
+		}
+		args = append(args, id, seat.UserID, seat.Role, rsvp)
+	}
+
+	stmt := `INSERT INTO session_participants (session_id, user_id, role, rsvp) VALUES (?, ?, ?, ?)` +
+		strings.Repeat(", (?, ?, ?, ?)", len(seats)-1)
+
+	_, err = tx.Exec(stmt, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+// Get retrieves a moot that userID takes part in, with its participants
+func (m *MootSessionModel) Get(id, userID int) (*MootSession, error) {
+	stmt := `SELECT ` + mootSessionColumns + ` ` + mootSessionFrom + `
+		WHERE s.id = ? AND EXISTS (SELECT 1 FROM session_participants p WHERE p.session_id = s.id AND p.user_id = ?)`
+
+	s, err := scanMootSession(m.DB.QueryRow(stmt, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	err = m.loadParticipants(s)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// loadParticipants fills in a moot's participants in role order
+func (m *MootSessionModel) loadParticipants(s *MootSession) error {
+	stmt := `SELECT p.user_id, u.name, u.email, p.role, p.is_ai, p.rsvp, p.checked_in_at
+		FROM session_participants p JOIN users u ON u.id = p.user_id
+		WHERE p.session_id = ? ORDER BY FIELD(p.role, 'appellant_counsel', 'respondent_counsel', 'judge')`
+
+	rows, err := m.DB.Query(stmt, s.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	s.Participants = nil
+	for rows.Next() {
+		var p MootParticipant
+		err = rows.Scan(&p.UserID, &p.Name, &p.Email, &p.Role, &p.IsAI, &p.RSVP, &p.CheckedInAt)
+		if err != nil {
+			return err
+		}
+		s.Participants = append(s.Participants, &p)
+	}
+
+	return rows.Err()
+}
+
+// ListScheduled returns the scheduled moots userID takes part in, latest
+// first, with their participants
+func (m *MootSessionModel) ListScheduled(userID int) ([]*MootSession, error) {
+	stmt := `SELECT ` + mootSessionColumns + ` ` + mootSessionFrom + `
+		JOIN session_participants me ON me.session_id = s.id AND me.user_id = ?
+		WHERE s.scheduled_at IS NOT NULL
+		ORDER BY s.scheduled_at DESC, s.id DESC
+		LIMIT 50`
+
+	return m.listWithParticipants(stmt, userID)
+}
+
+// Feed returns the scheduled moots for a user's calendar feed that end after
+// the given time, leaving out those the user has declined
+func (m *MootSessionModel) Feed(userID int, since time.Time) ([]*MootSession, error) {
+	stmt := `SELECT ` + mootSessionColumns + ` ` + mootSessionFrom + `
+		JOIN session_participants me ON me.session_id = s.id AND me.user_id = ?
+		WHERE s.scheduled_at IS NOT NULL AND me.rsvp <> 'no' AND s.scheduled_at >= ?
+		ORDER BY s.scheduled_at`
+
+	return m.listWithParticipants(stmt, userID, since.UTC().Add(-MootDuration))
+}
+
+// DueReminders returns the upcoming moots starting between now and the
+// given time whose participants haven't been reminded
+func (m *MootSessionModel) DueReminders(before time.Time) ([]*MootSession, error) {
+	stmt := `SELECT ` + mootSessionColumns + ` ` + mootSessionFrom + `
+		WHERE s.status = 'setup' AND s.reminder_sent_at IS NULL
+		AND s.scheduled_at > UTC_TIMESTAMP() AND s.scheduled_at <= ?
+		ORDER BY s.scheduled_at`
+
+	return m.listWithParticipants(stmt, before.UTC())
+}
+
+// DueToStart returns the upcoming moots that should start: those whose
+// participants have all checked in once the start time arrives, and the rest
+// once the check-in deadline has passed
+func (m *MootSessionModel) DueToStart(deadline time.Time) ([]*MootSession, error) {
+	stmt := `SELECT ` + mootSessionColumns + ` ` + mootSessionFrom + `
+		WHERE s.status = 'setup' AND s.scheduled_at IS NOT NULL
+		AND (s.scheduled_at <= ? OR (s.scheduled_at <= UTC_TIMESTAMP() AND NOT EXISTS (
+			SELECT 1 FROM session_participants p WHERE p.session_id = s.id AND p.checked_in_at IS NULL)))
+		ORDER BY s.scheduled_at`
+
+	return m.listWithParticipants(stmt, deadline.UTC())
+}
+
+func (m *MootSessionModel) listWithParticipants(stmt string, args ...any) ([]*MootSession, error) {
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*MootSession
+
+	for rows.Next() {
+		s, err := scanMootSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for _, s := range sessions {
+		err = m.loadParticipants(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return sessions, nil
+}
+
+// SetRSVP records a participant's answer to an invitation. It returns
+// ErrNoRecord if the moot has already started or been cancelled.
+func (m *MootSessionModel) SetRSVP(id, userID int, rsvp RSVP) error {
+	stmt := `UPDATE session_participants p JOIN moot_sessions s ON s.id = p.session_id
+		SET p.rsvp = ?
+		WHERE p.session_id = ? AND p.user_id = ? AND s.status = 'setup' AND s.scheduled_at > UTC_TIMESTAMP()`
+
+	return m.update(stmt, rsvp, id, userID)
+}
+
+// CheckIn records that a participant is ready to start. Check-in opens at
+// the given time before the start, and closes when the moot starts. It
+// returns ErrNoRecord outside that window.
+func (m *MootSessionModel) CheckIn(id, userID int, opensBefore time.Duration) error {
+	stmt := `UPDATE session_participants p JOIN moot_sessions s ON s.id = p.session_id
+		SET p.checked_in_at = COALESCE(p.checked_in_at, UTC_TIMESTAMP()), p.rsvp = 'yes'
+		WHERE p.session_id = ? AND p.user_id = ? AND s.status = 'setup' AND s.scheduled_at <= ?`
+
+	return m.update(stmt, id, userID, time.Now().UTC().Add(opensBefore))
+}
+
+// Cancel calls off an upcoming moot. Only its organiser can cancel it, and
+// ErrNoRecord is returned if it has already started or been cancelled. A
+// mentoring request the moot was booked for is cancelled with it, freeing
+// the mentor's slot.
+func (m *MootSessionModel) Cancel(id, creatorID int) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE moot_sessions SET status = 'cancelled', sequence = sequence + 1
+		WHERE id = ? AND created_by = ? AND status = 'setup'`, id, creatorID)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+
+	_, err = tx.Exec(`UPDATE mentor_slots s JOIN mentoring_requests q ON q.id = s.request_id
+		SET s.request_id = NULL WHERE q.moot_session_id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE mentoring_requests SET status = 'cancelled', updated_at = UTC_TIMESTAMP()
+		WHERE moot_session_id = ? AND status = 'accepted'`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkReminded records that the participants have been reminded
+func (m *MootSessionModel) MarkReminded(id int) error {
+	stmt := `UPDATE moot_sessions SET reminder_sent_at = UTC_TIMESTAMP() WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, id)
+	return err
+}
+
+// Start begins a scheduled moot, deciding what to do about participants who
+// haven't checked in. If everyone has, the moot starts. Otherwise it is
+// cancelled if nobody has checked in or its policy says so, or the AI takes
+// over the absent participants' roles. It returns the moot's new status and
+// the participants who didn't turn up, or ErrNoRecord if the moot isn't
+// waiting to start.
+func (m *MootSessionModel) Start(id int) (MootStatus, []int, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return "", nil, err
+	}
+	defer tx.Rollback()
+
+	var policy NoShowPolicy
+	err = tx.QueryRow(`SELECT no_show_policy FROM moot_sessions WHERE id = ? AND status = 'setup' FOR UPDATE`, id).Scan(&policy)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil, ErrNoRecord
+		}
+		return "", nil, err
+	}
+
+	rows, err := tx.Query(`SELECT user_id, checked_in_at IS NOT NULL FROM session_participants
+		WHERE session_id = ? AND is_ai = FALSE`, id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var absent []int
+	present := 0
+	for rows.Next() {
+		var userID int
+		var checkedIn bool
+		if err = rows.Scan(&userID, &checkedIn); err != nil {
+			rows.Close()
+			return "", nil, err
+		}
+		if checkedIn {
+			present++
+		} else {
+			absent = append(absent, userID)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return "", nil, err
+	}
+
+	status := MootInProgress
+	switch {
+	case len(absent) == 0:
+	case present == 0 || policy == NoShowCancel:
+		status = MootCancelled
+	default:
+		stmt := `UPDATE session_participants SET is_ai = TRUE WHERE session_id = ? AND user_id IN (?` +
+			strings.Repeat(", ?", len(absent)-1) + `)`
+
+		args := []any{id}
+		for _, userID := range absent {
+			args = append(args, userID)
+		}
+
+		_, err = tx.Exec(stmt, args...)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	// A cancellation changes the calendar event, so it gets a new sequence
+	bump := 0
+	if status == MootCancelled {
+		bump = 1
+	}
+
+	_, err = tx.Exec(`UPDATE moot_sessions SET status = ?, sequence = sequence + ? WHERE id = ?`, status, bump, id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return status, absent, tx.Commit()
+}
+
+func (m *MootSessionModel) update(stmt string, args ...any) error {
+	result, err := m.DB.Exec(stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// NotificationType groups notifications so users can choose how they hear
+// about each kind
+type NotificationType string
+
+const (
+	NotifyMessage      NotificationType = "message"
+	NotifyInvite       NotificationType = "invite"
+	NotifyEvaluation   NotificationType = "evaluation"
+	NotifyAchievement  NotificationType = "achievement"
+	NotifyVerification NotificationType = "verification"
+	NotifySavedSearch  NotificationType = "saved_search"
+	NotifyInterview    NotificationType = "interview"
+	NotifyMoot         NotificationType = "moot"
+	NotifyMentoring    NotificationType = "mentoring"
+)
+
+// NotificationTypeInfo describes a notification type for the preferences page
+type NotificationTypeInfo struct {
+	Type        NotificationType
+	Description string
+
+	// Email is whether the type is emailed to users who haven't chosen
+	// otherwise. Every type is shown in the app by default.
+	Email bool
+
+	// Roles are the roles that receive the type
+	Roles []UserRole
+}
+
+// NotificationTypes lists every notification type
+var NotificationTypes = []NotificationTypeInfo{
+	{NotifyMessage, "Contact requests and new messages", true, []UserRole{RoleStudent, RoleLawyer, RoleRecruiter}},
+	{NotifyInvite, "Invitations to join an organisation", true, []UserRole{RoleRecruiter}},
+	{NotifyEvaluation, "New moot court evaluations", false, []UserRole{RoleStudent, RoleLawyer}},
+	{NotifyAchievement, "Achievements you earn", true, []UserRole{RoleStudent, RoleLawyer}},
+	{NotifyVerification, "Decisions on your bar registration", true, []UserRole{RoleLawyer}},
+	{NotifySavedSearch, "New candidates matching your saved searches", true, []UserRole{RoleRecruiter}},
+	{NotifyInterview, "Interview invitations, changes and reminders", true, []UserRole{RoleStudent, RoleLawyer, RoleRecruiter}},
+	{NotifyMoot, "Scheduled moot invitations, changes and reminders", true, []UserRole{RoleStudent, RoleLawyer}},
+	{NotifyMentoring, "Mentoring requests, answers and reviews", true, []UserRole{RoleStudent, RoleLawyer}},
+}
+
+// NotificationTypesFor returns the notification types a role receives
+func NotificationTypesFor(role UserRole) []NotificationTypeInfo {
+	var types []NotificationTypeInfo
+	for _, t := range NotificationTypes {
+		for _, r := range t.Roles {
+			if r == role {
+				types = append(types, t)
+				break
+			}
+		}
+	}
+	return types
+}
+
+// ValidNotificationType reports whether t is a known notification type
+func ValidNotificationType(t NotificationType) bool {
+	for _, info := range NotificationTypes {
+		if info.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is one entry in a user's notification centre
+type Notification struct {
+	ID        int
+	UserID    int
+	Type      NotificationType
+	Title     string
+	Body      string
+	URL       string
+	CreatedAt time.Time
+	ReadAt    sql.NullTime
+}
+
+// NotificationPreference is how a user wants to hear about one type of
+// notification
+type NotificationPreference struct {
+	Type  NotificationType
+	InApp bool
+	Email bool
+}
+
+// defaultPreference returns the preference used until a user chooses one
+func defaultPreference(t NotificationType) NotificationPreference {
+	p := NotificationPreference{Type: t, InApp: true}
+	for _, info := range NotificationTypes {
+		if info.Type == t {
+			p.Email = info.Email
+		}
+	}
+	return p
+}
+
+// NotificationModel wraps a database connection pool
+type NotificationModel struct {
+	DB *sql.DB
+}
+
+// Insert adds a notification to a user's notification centre
+func (m *NotificationModel) Insert(userID int, t NotificationType, title, body, url string) (int, error) {
+	stmt := `INSERT INTO notifications (user_id, type, title, body, url, created_at)
+		VALUES (?, ?, ?, ?, ?, UTC_TIMESTAMP())`
+
+	result, err := m.DB.Exec(stmt, userID, t, title, body, url)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+const notificationColumns = `id, user_id, type, title, body, url, created_at, read_at`
+
+func scanNotification(row rowScanner, extra ...any) (*Notification, error) {
+	var n Notification
+	dest := append([]any{
+		&n.ID,
+		&n.UserID,
+		&n.Type,
+		&n.Title,
+		&n.Body,
+		&n.URL,
+		&n.CreatedAt,
+		&n.ReadAt,
+	}, extra...)
+
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// Get retrieves one of a user's notifications
+func (m *NotificationModel) Get(id, userID int) (*Notification, error) {
+	stmt := `SELECT ` + notificationColumns + ` FROM notifications WHERE id = ? AND user_id = ?`
+
+	n, err := scanNotification(m.DB.QueryRow(stmt, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// List returns a page of a user's notifications, newest first, and the total
+// number they have
+func (m *NotificationModel) List(userID, limit, offset int) ([]*Notification, int, error) {
+	stmt := `SELECT ` + notificationColumns + `, COUNT(*) OVER()
+		FROM notifications WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?`
+
+	rows, err := m.DB.Query(stmt, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var notifications []*Notification
+	var total int
+
+	for rows.Next() {
+		n, err := scanNotification(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+// UnreadCount returns the number of notifications a user hasn't read
+func (m *NotificationModel) UnreadCount(userID int) (int, error) {
+	var count int
+	stmt := `SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`
+	err := m.DB.QueryRow(stmt, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marks one of a user's notifications as read
+func (m *NotificationModel) MarkRead(id, userID int) error {
+	stmt := `UPDATE notifications SET read_at = UTC_TIMESTAMP()
+		WHERE id = ? AND user_id = ? AND read_at IS NULL`
+
+	_, err := m.DB.Exec(stmt, id, userID)
+	return err
+}
+
+// MarkAllRead marks every one of a user's notifications as read
+func (m *NotificationModel) MarkAllRead(userID int) error {
+	stmt := `UPDATE notifications SET read_at = UTC_TIMESTAMP() WHERE user_id = ? AND read_at IS NULL`
+
+	_, err := m.DB.Exec(stmt, userID)
+	return err
+}
+
+// DeleteReadBefore removes notifications that were read before the given
+// time. It returns the number removed.
+func (m *NotificationModel) DeleteReadBefore(t time.Time) (int64, error) {
+	stmt := `DELETE FROM notifications WHERE read_at < ?`
+
+	result, err := m.DB.Exec(stmt, t.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Preference returns how a user wants to hear about one type of notification
+func (m *NotificationModel) Preference(userID int, t NotificationType) (NotificationPreference, error) {
+	p := NotificationPreference{Type: t}
+
+	stmt := `SELECT in_app, email FROM notification_preferences WHERE user_id = ? AND type = ?`
+	err := m.DB.QueryRow(stmt, userID, t).Scan(&p.InApp, &p.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return defaultPreference(t), nil
+		}
+		return p, err
+	}
+
+	return p, nil
+}
+
+// Preferences returns a user's preference for each of the given types, in
+// the same order
+func (m *NotificationModel) Preferences(userID int, types []NotificationTypeInfo) ([]NotificationPreference, error) {
+	rows, err := m.DB.Query(`SELECT type, in_app, email FROM notification_preferences WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	saved := make(map[NotificationType]NotificationPreference)
+
+	for rows.Next() {
+		var p NotificationPreference
+		if err = rows.Scan(&p.Type, &p.InApp, &p.Email); err != nil {
+			return nil, err
+		}
+		saved[p.Type] = p
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	prefs := make([]NotificationPreference, len(types))
+	for i, info := range types {
+		p, ok := saved[info.Type]
+		if !ok {
+			p = defaultPreference(info.Type)
+		}
+		prefs[i] = p
+	}
+
+	return prefs, nil
+}
+
+// SetPreferences saves a user's notification preferences
+func (m *NotificationModel) SetPreferences(userID int, prefs []NotificationPreference) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO notification_preferences (user_id, type, in_app, email) VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE in_app = VALUES(in_app), email = VALUES(email)`
+
+	for _, p := range prefs {
+		_, err = tx.Exec(stmt, userID, p.Type, p.InApp, p.Email)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
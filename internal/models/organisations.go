@@ -0,0 +1,518 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// OrgRole is a recruiter's role within their organisation
+type OrgRole string
+
+const (
+	OrgOwner  OrgRole = "owner"
+	OrgAdmin  OrgRole = "admin"
+	OrgMember OrgRole = "member"
+)
+
+// InviteRoles lists the roles a recruiter can be invited with or given.
+// Every organisation has exactly one owner.
+var InviteRoles = []OrgRole{OrgAdmin, OrgMember}
+
+// ValidInviteRole reports whether r can be given to an invited recruiter
+func ValidInviteRole(r OrgRole) bool {
+	for _, known := range InviteRoles {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Organisation is a company that recruiters belong to
+type Organisation struct {
+	ID             int
+	Name           string
+	EmailDomain    string
+	DomainVerified bool
+	Members        int
+	CreatedAt      time.Time
+}
+
+// Membership is a recruiter's place in an organisation
+type Membership struct {
+	Organisation *Organisation
+	Role         OrgRole
+}
+
+// CanManage reports whether the recruiter can invite and remove members and
+// change the organisation's settings
+func (m *Membership) CanManage() bool {
+	return m.Role == OrgOwner || m.Role == OrgAdmin
+}
+
+// IsOwner reports whether the recruiter owns the organisation
+func (m *Membership) IsOwner() bool {
+	return m.Role == OrgOwner
+}
+
+// OrganisationMember is one recruiter in an organisation
+type OrganisationMember struct {
+	UserID   int
+	Name     string
+	Email    string
+	Role     OrgRole
+	JoinedAt time.Time
+}
+
+// OrganisationInvite invites a recruiter, by email address, to join an
+// organisation
+type OrganisationInvite struct {
+	ID               int
+	OrganisationID   int
+	OrganisationName string
+	Email            string
+	Role             OrgRole
+	InviterName      sql.NullString
+	CreatedAt        time.Time
+}
+
+// OrganisationModel wraps a database connection pool
+type OrganisationModel struct {
+	DB *sql.DB
+}
+
+const organisationColumns = `o.id, o.name, COALESCE(o.email_domain, ''), o.domain_verified,
+	(SELECT COUNT(*) FROM organisation_members om WHERE om.organisation_id = o.id), o.created_at`
+
+func scanOrganisation(row rowScanner, extra ...any) (*Organisation, error) {
+	var o Organisation
+
+	dest := append([]any{
+		&o.ID,
+		&o.Name,
+		&o.EmailDomain,
+		&o.DomainVerified,
+		&o.Members,
+		&o.CreatedAt,
+	}, extra...)
+
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// alreadyMember reports whether err is the user already belonging to an
+// organisation
+func alreadyMember(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 &&
+		strings.Contains(mysqlErr.Message, "PRIMARY")
+}
+
+// Create sets up an organisation owned by the given recruiter and returns its
+// ID. ErrAlreadyMember is returned if the recruiter already belongs to one.
+func (m *OrganisationModel) Create(name string, ownerID int) (int, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO organisations (name) VALUES (?)`, name)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`INSERT INTO organisation_members (user_id, organisation_id, role) VALUES (?, ?, 'owner')`,
+		ownerID, id)
+	if err != nil {
+		if alreadyMember(err) {
+			return 0, ErrAlreadyMember
+		}
+		return 0, err
+	}
+
+	return int(id), tx.Commit()
+}
+
+// Delete removes an organisation. Its members' shared shortlists and notes
+// are no longer visible to each other.
+func (m *OrganisationModel) Delete(id int) error {
+	_, err := m.DB.Exec(`DELETE FROM organisations WHERE id = ?`, id)
+	return err
+}
+
+// ForUser returns the recruiter's membership, or ErrNoRecord if they don't
+// belong to an organisation
+func (m *OrganisationModel) ForUser(userID int) (*Membership, error) {
+	stmt := `SELECT ` + organisationColumns + `, m.role
+		FROM organisation_members m
+		JOIN organisations o ON o.id = m.organisation_id
+		WHERE m.user_id = ?`
+
+	var role OrgRole
+	o, err := scanOrganisation(m.DB.QueryRow(stmt, userID), &role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return &Membership{Organisation: o, Role: role}, nil
+}
+
+// Members returns everyone in an organisation, the owner first
+func (m *OrganisationModel) Members(organisationID int) ([]*OrganisationMember, error) {
+	stmt := `SELECT u.id, u.name, u.email, m.role, m.joined_at
+		FROM organisation_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organisation_id = ?
+		ORDER BY FIELD(m.role, 'owner', 'admin', 'member'), u.name`
+
+	rows, err := m.DB.Query(stmt, organisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*OrganisationMember
+
+	for rows.Next() {
+		var om OrganisationMember
+		err = rows.Scan(&om.UserID, &om.Name, &om.Email, &om.Role, &om.JoinedAt)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, &om)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// Member returns one member of an organisation
+func (m *OrganisationModel) Member(organisationID, userID int) (*OrganisationMember, error) {
+	stmt := `SELECT u.id, u.name, u.email, m.role, m.joined_at
+		FROM organisation_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organisation_id = ? AND m.user_id = ?`
+
+	var om OrganisationMember
+	err := m.DB.QueryRow(stmt, organisationID, userID).Scan(&om.UserID, &om.Name, &om.Email, &om.Role, &om.JoinedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return &om, nil
+}
+
+// SetRole changes a member's role. The owner's role can't be changed.
+func (m *OrganisationModel) SetRole(organisationID, userID int, role OrgRole) error {
+	stmt := `UPDATE organisation_members SET role = ?
+		WHERE organisation_id = ? AND user_id = ? AND role <> 'owner'`
+
+	_, err := m.DB.Exec(stmt, role, organisationID, userID)
+	return err
+}
+
+// RemoveMember takes a recruiter out of an organisation, ending their access
+// to its shared shortlists and notes. The owner can't be removed. The
+// departure is recorded so the recruiter isn't added back by AutoJoin.
+func (m *OrganisationModel) RemoveMember(organisationID, userID int) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `DELETE FROM organisation_members
+		WHERE organisation_id = ? AND user_id = ? AND role <> 'owner'`
+
+	result, err := tx.Exec(stmt, organisationID, userID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if removed > 0 {
+		stmt = `INSERT INTO organisation_departures (user_id, organisation_id) VALUES (?, ?)
+			ON DUPLICATE KEY UPDATE departed_at = CURRENT_TIMESTAMP`
+
+		_, err = tx.Exec(stmt, userID, organisationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetDomain changes the organisation's email domain. The new domain must be
+// verified by an administrator before recruiters join automatically.
+// ErrDuplicateDomain is returned if another organisation has claimed it.
+func (m *OrganisationModel) SetDomain(organisationID int, domain string) error {
+	stmt := `UPDATE organisations SET email_domain = ?, domain_verified = FALSE WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, nullString(domain), organisationID)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 &&
+			strings.Contains(mysqlErr.Message, "unique_organisation_domain") {
+			return ErrDuplicateDomain
+		}
+		return err
+	}
+	return nil
+}
+
+// VerifyDomain records whether an administrator has confirmed that the
+// organisation owns its email domain
+func (m *OrganisationModel) VerifyDomain(organisationID int, verified bool) error {
+	stmt := `UPDATE organisations SET domain_verified = ? WHERE id = ? AND email_domain IS NOT NULL`
+
+	_, err := m.DB.Exec(stmt, verified, organisationID)
+	return err
+}
+
+// Get retrieves an organisation by ID
+func (m *OrganisationModel) Get(id int) (*Organisation, error) {
+	stmt := `SELECT ` + organisationColumns + ` FROM organisations o WHERE o.id = ?`
+
+	o, err := scanOrganisation(m.DB.QueryRow(stmt, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return o, nil
+}
+
+// List returns a page of organisations, those with an unverified domain
+// first, and the total number of organisations
+func (m *OrganisationModel) List(limit, offset int) ([]*Organisation, int, error) {
+	stmt := `SELECT ` + organisationColumns + `, COUNT(*) OVER()
+		FROM organisations o
+		ORDER BY (o.email_domain IS NOT NULL AND o.domain_verified = FALSE) DESC, o.name
+		LIMIT ? OFFSET ?`
+
+	rows, err := m.DB.Query(stmt, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var organisations []*Organisation
+	total := 0
+
+	for rows.Next() {
+		o, err := scanOrganisation(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		organisations = append(organisations, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return organisations, total, nil
+}
+
+// Verified returns the organisations that have verified their email domain,
+// by name
+func (m *OrganisationModel) Verified() ([]*Organisation, error) {
+	stmt := `SELECT ` + organisationColumns + ` FROM organisations o
+		WHERE o.domain_verified = TRUE ORDER BY o.name`
+
+	rows, err := m.DB.Query(stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var organisations []*Organisation
+
+	for rows.Next() {
+		o, err := scanOrganisation(rows)
+		if err != nil {
+			return nil, err
+		}
+		organisations = append(organisations, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return organisations, nil
+}
+
+// AutoJoin adds a recruiter to the organisation that has verified the domain
+// of their email address. It returns ErrNoRecord if there is none, or if the
+// recruiter has left or been removed from it before.
+func (m *OrganisationModel) AutoJoin(userID int, email string) (*Organisation, error) {
+	_, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	if !ok {
+		return nil, ErrNoRecord
+	}
+
+	stmt := `SELECT ` + organisationColumns + ` FROM organisations o
+		WHERE o.email_domain = ? AND o.domain_verified = TRUE
+		AND NOT EXISTS (SELECT 1 FROM organisation_departures d
+			WHERE d.organisation_id = o.id AND d.user_id = ?)`
+
+	o, err := scanOrganisation(m.DB.QueryRow(stmt, domain, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	_, err = m.DB.Exec(`INSERT INTO organisation_members (user_id, organisation_id) VALUES (?, ?)`, userID, o.ID)
+	if err != nil {
+		if alreadyMember(err) {
+			return nil, ErrAlreadyMember
+		}
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// Invite invites an email address to join an organisation. Inviting the
+// same address again updates the role.
+func (m *OrganisationModel) Invite(organisationID int, email string, role OrgRole, invitedBy int) error {
+	stmt := `INSERT INTO organisation_invites (organisation_id, email, role, invited_by)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE role = VALUES(role), invited_by = VALUES(invited_by), created_at = UTC_TIMESTAMP()`
+
+	_, err := m.DB.Exec(stmt, organisationID, strings.ToLower(email), role, invitedBy)
+	return err
+}
+
+const inviteColumns = `i.id, i.organisation_id, o.name, i.email, i.role, u.name, i.created_at`
+
+const inviteFrom = `FROM organisation_invites i
+	JOIN organisations o ON o.id = i.organisation_id
+	LEFT JOIN users u ON u.id = i.invited_by`
+
+func (m *OrganisationModel) invites(stmt string, args ...any) ([]*OrganisationInvite, error) {
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invites []*OrganisationInvite
+
+	for rows.Next() {
+		var i OrganisationInvite
+		err = rows.Scan(&i.ID, &i.OrganisationID, &i.OrganisationName, &i.Email, &i.Role, &i.InviterName, &i.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, &i)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+// Invites returns an organisation's outstanding invites, newest first
+func (m *OrganisationModel) Invites(organisationID int) ([]*OrganisationInvite, error) {
+	stmt := `SELECT ` + inviteColumns + ` ` + inviteFrom + `
+		WHERE i.organisation_id = ? ORDER BY i.created_at DESC, i.id DESC`
+
+	return m.invites(stmt, organisationID)
+}
+
+// InvitesFor returns the outstanding invites sent to an email address
+func (m *OrganisationModel) InvitesFor(email string) ([]*OrganisationInvite, error) {
+	stmt := `SELECT ` + inviteColumns + ` ` + inviteFrom + `
+		WHERE i.email = ? ORDER BY i.created_at DESC, i.id DESC`
+
+	return m.invites(stmt, strings.ToLower(email))
+}
+
+// RevokeInvite withdraws an organisation's invite
+func (m *OrganisationModel) RevokeInvite(id, organisationID int) error {
+	_, err := m.DB.Exec(`DELETE FROM organisation_invites WHERE id = ? AND organisation_id = ?`, id, organisationID)
+	return err
+}
+
+// DeclineInvite removes an invite sent to an email address
+func (m *OrganisationModel) DeclineInvite(id int, email string) error {
+	_, err := m.DB.Exec(`DELETE FROM organisation_invites WHERE id = ? AND email = ?`, id, strings.ToLower(email))
+	return err
+}
+
+// AcceptInvite adds the recruiter to the organisation that invited their
+// email address, and clears any other invites to it. It returns ErrNoRecord
+// if there is no such invite, and ErrAlreadyMember if the recruiter already
+// belongs to an organisation. The email address must have been verified.
+func (m *OrganisationModel) AcceptInvite(id, userID int, email string) (*Organisation, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	email = strings.ToLower(email)
+
+	var organisationID int
+	var role OrgRole
+	err = tx.QueryRow(`SELECT organisation_id, role FROM organisation_invites WHERE id = ? AND email = ?`,
+		id, email).Scan(&organisationID, &role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	_, err = tx.Exec(`INSERT INTO organisation_members (user_id, organisation_id, role) VALUES (?, ?, ?)`,
+		userID, organisationID, role)
+	if err != nil {
+		if alreadyMember(err) {
+			return nil, ErrAlreadyMember
+		}
+		return nil, err
+	}
+
+	_, err = tx.Exec(`DELETE FROM organisation_invites WHERE email = ?`, email)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return m.Get(organisationID)
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PasswordResetModel wraps a database connection pool
+type PasswordResetModel struct {
+	DB *sql.DB
+}
+
+// New creates a one-time password reset token for a user and returns its plaintext value
+func (m *PasswordResetModel) New(userID int, ttl time.Duration) (string, error) {
+	plaintext, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	stmt := `INSERT INTO password_resets (token_hash, user_id, expires_at) VALUES (?, ?, ?)`
+
+	_, err = m.DB.Exec(stmt, hashToken(plaintext), userID, time.Now().UTC().Add(ttl))
+	if err != nil {
+		return "", err
+	}
+
+	return plaintext, nil
+}
+
+// GetUserID returns the user a valid, unexpired reset token was issued to
+func (m *PasswordResetModel) GetUserID(plaintext string) (int, error) {
+	var userID int
+
+	stmt := `SELECT user_id FROM password_resets WHERE token_hash = ? AND expires_at > UTC_TIMESTAMP()`
+
+	err := m.DB.QueryRow(stmt, hashToken(plaintext)).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoRecord
+		}
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// DeleteAllForUser removes every outstanding reset token for a user
+func (m *PasswordResetModel) DeleteAllForUser(userID int) error {
+	stmt := `DELETE FROM password_resets WHERE user_id = ?`
+
+	_, err := m.DB.Exec(stmt, userID)
+	return err
+}
+
+// DeleteExpired removes reset tokens whose expiry time has passed
+func (m *PasswordResetModel) DeleteExpired() error {
+	stmt := `DELETE FROM password_resets WHERE expires_at < UTC_TIMESTAMP()`
+
+	_, err := m.DB.Exec(stmt)
+	return err
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Portfolio is a student's or lawyer's public profile page and the fields
+// they've chosen to show on it
+type Portfolio struct {
+	UserID   int
+	Slug     string
+	Headline string
+	IsPublic bool
+	NoIndex  bool
+
+	ShowUniversity      bool
+	ShowYearOfStudy     bool
+	ShowSpecialization  bool
+	ShowExperience      bool
+	ShowFirm            bool
+	ShowBio             bool
+	ShowBarRegistration bool
+	ShowVerified        bool
+	ShowHighlights      bool
+	ShowAchievements    bool
+
+	// EvaluationIDs are the moot results featured on the page
+	EvaluationIDs []int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewPortfolio returns an unsaved, private portfolio with the default
+// visibility settings
+func NewPortfolio(userID int, slug string) *Portfolio {
+	return &Portfolio{
+		UserID:             userID,
+		Slug:               slug,
+		ShowUniversity:     true,
+		ShowYearOfStudy:    true,
+		ShowSpecialization: true,
+		ShowExperience:     true,
+		ShowFirm:           true,
+		ShowBio:            true,
+		ShowVerified:       true,
+		ShowHighlights:     true,
+		ShowAchievements:   true,
+	}
+}
+
+// Features reports whether the given evaluation is featured on the portfolio
+func (p *Portfolio) Features(evaluationID int) bool {
+	for _, id := range p.EvaluationIDs {
+		if id == evaluationID {
+			return true
+		}
+	}
+	return false
+}
+
+// PortfolioModel wraps a database connection pool
+type PortfolioModel struct {
+	DB *sql.DB
+}
+
+const portfolioColumns = `user_id, slug, headline, is_public, noindex,
+	show_university, show_year_of_study, show_specialization, show_experience, show_firm,
+	show_bio, show_bar_registration, show_verified, show_highlights, show_achievements, created_at, updated_at`
+
+// get loads a single portfolio and its featured evaluation IDs
+func (m *PortfolioModel) get(where string, arg any) (*Portfolio, error) {
+	stmt := `SELECT ` + portfolioColumns + ` FROM portfolios WHERE ` + where
+
+	var p Portfolio
+	err := m.DB.QueryRow(stmt, arg).Scan(
+		&p.UserID,
+		&p.Slug,
+		&p.Headline,
+		&p.IsPublic,
+		&p.NoIndex,
+		&p.ShowUniversity,
+		&p.ShowYearOfStudy,
+		&p.ShowSpecialization,
+		&p.ShowExperience,
+		&p.ShowFirm,
+		&p.ShowBio,
+		&p.ShowBarRegistration,
+		&p.ShowVerified,
+		&p.ShowHighlights,
+		&p.ShowAchievements,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	rows, err := m.DB.Query(`SELECT evaluation_id FROM portfolio_evaluations WHERE user_id = ?`, p.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		p.EvaluationIDs = append(p.EvaluationIDs, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Get retrieves a user's portfolio
+func (m *PortfolioModel) Get(userID int) (*Portfolio, error) {
+	return m.get("user_id = ?", userID)
+}
+
+// GetBySlug retrieves a portfolio by its slug, whether or not it is public
+func (m *PortfolioModel) GetBySlug(slug string) (*Portfolio, error) {
+	return m.get("slug = ?", slug)
+}
+
+// SlugTaken reports whether slug belongs to a user other than userID
+func (m *PortfolioModel) SlugTaken(slug string, userID int) (bool, error) {
+	var exists bool
+	stmt := `SELECT EXISTS(SELECT true FROM portfolios WHERE slug = ? AND user_id <> ?)`
+	err := m.DB.QueryRow(stmt, slug, userID).Scan(&exists)
+	return exists, err
+}
+
+// Upsert creates or replaces p.UserID's portfolio and its featured
+// evaluations. Only evaluations belonging to the user are featured, and
+// repeated IDs are featured once. It returns ErrDuplicateSlug if another user
+// already has the slug.
+func (m *PortfolioModel) Upsert(p *Portfolio) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO portfolios (user_id, slug, headline, is_public, noindex,
+		show_university, show_year_of_study, show_specialization, show_experience, show_firm,
+		show_bio, show_bar_registration, show_verified, show_highlights, show_achievements)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE slug = VALUES(slug), headline = VALUES(headline),
+		is_public = VALUES(is_public), noindex = VALUES(noindex),
+		show_university = VALUES(show_university), show_year_of_study = VALUES(show_year_of_study),
+		show_specialization = VALUES(show_specialization), show_experience = VALUES(show_experience),
+		show_firm = VALUES(show_firm), show_bio = VALUES(show_bio),
+		show_bar_registration = VALUES(show_bar_registration), show_verified = VALUES(show_verified),
+		show_highlights = VALUES(show_highlights), show_achievements = VALUES(show_achievements)`
+
+	_, err = tx.Exec(stmt, p.UserID, p.Slug, p.Headline, p.IsPublic, p.NoIndex,
+		p.ShowUniversity, p.ShowYearOfStudy, p.ShowSpecialization, p.ShowExperience, p.ShowFirm,
+		p.ShowBio, p.ShowBarRegistration, p.ShowVerified, p.ShowHighlights, p.ShowAchievements)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) {
+			if mysqlErr.Number == 1062 && strings.Contains(mysqlErr.Message, "unique_portfolio_slug") {
+				return ErrDuplicateSlug
+			}
+		}
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM portfolio_evaluations WHERE user_id = ?`, p.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range p.EvaluationIDs {
+		stmt := `INSERT IGNORE INTO portfolio_evaluations (user_id, evaluation_id)
+			SELECT user_id, id FROM performance_evaluations WHERE id = ? AND user_id = ?`
+
+		_, err = tx.Exec(stmt, id, p.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,277 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// StudentProfile holds the student-specific details of a user
+type StudentProfile struct {
+	ID                  int
+	UserID              int
+	University          string
+	YearOfStudy         int
+	Specialization      string
+	MootCourtExperience string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// Completeness returns the percentage of profile fields that are filled in
+func (p *StudentProfile) Completeness() int {
+	return percentFilled(p.University != "", p.YearOfStudy > 0, p.Specialization != "", p.MootCourtExperience != "")
+}
+
+// LawyerProfile holds the lawyer-specific details of a user
+type LawyerProfile struct {
+	ID                    int
+	UserID                int
+	BarRegistrationNumber string
+	YearsOfExperience     int
+	Specialization        string
+	FirmName              string
+	Bio                   string
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+// Completeness returns the percentage of profile fields that are filled in
+func (p *LawyerProfile) Completeness() int {
+	return percentFilled(p.BarRegistrationNumber != "", p.YearsOfExperience > 0, p.Specialization != "", p.FirmName != "", p.Bio != "")
+}
+
+// RecruiterProfile holds the recruiter-specific details of a user
+type RecruiterProfile struct {
+	ID             int
+	UserID         int
+	CompanyName    string
+	Position       string
+	CompanyWebsite string
+	Bio            string
+	AnonymousViews bool
+	BlindReview    bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Completeness returns the percentage of profile fields that are filled in
+func (p *RecruiterProfile) Completeness() int {
+	return percentFilled(p.CompanyName != "", p.Position != "", p.CompanyWebsite != "", p.Bio != "")
+}
+
+func percentFilled(fields ...bool) int {
+	filled := 0
+	for _, f := range fields {
+		if f {
+			filled++
+		}
+	}
+	return filled * 100 / len(fields)
+}
+
+// nullString stores empty strings as NULL in optional columns
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullInt stores zero as NULL in optional columns
+func nullInt(n int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(n), Valid: n != 0}
+}
+
+// StudentProfileModel wraps a database connection pool
+type StudentProfileModel struct {
+	DB *sql.DB
+}
+
+// Get retrieves the profile for a user
+func (m *StudentProfileModel) Get(userID int) (*StudentProfile, error) {
+	stmt := `SELECT id, user_id, COALESCE(university, ''), COALESCE(year_of_study, 0),
+		COALESCE(specialization, ''), COALESCE(moot_court_experience, ''), created_at, updated_at
+		FROM student_profiles WHERE user_id = ?`
+
+	var p StudentProfile
+
+	err := m.DB.QueryRow(stmt, userID).Scan(
+		&p.ID,
+		&p.UserID,
+		&p.University,
+		&p.YearOfStudy,
+		&p.Specialization,
+		&p.MootCourtExperience,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Upsert creates or replaces the profile for p.UserID
+func (m *StudentProfileModel) Upsert(p *StudentProfile) error {
+	stmt := `INSERT INTO student_profiles (user_id, university, year_of_study, specialization, moot_court_experience)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE university = VALUES(university), year_of_study = VALUES(year_of_study),
+		specialization = VALUES(specialization), moot_court_experience = VALUES(moot_court_experience)`
+
+	_, err := m.DB.Exec(stmt, p.UserID, nullString(p.University), nullInt(p.YearOfStudy),
+		nullString(p.Specialization), nullString(p.MootCourtExperience))
+	return err
+}
+
+// LawyerProfileModel wraps a database connection pool
+type LawyerProfileModel struct {
+	DB *sql.DB
+}
+
+// Get retrieves the profile for a user
+func (m *LawyerProfileModel) Get(userID int) (*LawyerProfile, error) {
+	stmt := `SELECT id, user_id, COALESCE(bar_registration_number, ''), COALESCE(years_of_experience, 0),
+		COALESCE(specialization, ''), COALESCE(firm_name, ''), COALESCE(bio, ''), created_at, updated_at
+		FROM lawyer_profiles WHERE user_id = ?`
+
+	var p LawyerProfile
+
+	err := m.DB.QueryRow(stmt, userID).Scan(
+		&p.ID,
+		&p.UserID,
+		&p.BarRegistrationNumber,
+		&p.YearsOfExperience,
+		&p.Specialization,
+		&p.FirmName,
+		&p.Bio,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Upsert creates or replaces the profile for p.UserID
+func (m *LawyerProfileModel) Upsert(p *LawyerProfile) error {
+	stmt := `INSERT INTO lawyer_profiles (user_id, bar_registration_number, years_of_experience, specialization, firm_name, bio)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE bar_registration_number = VALUES(bar_registration_number),
+		years_of_experience = VALUES(years_of_experience), specialization = VALUES(specialization),
+		firm_name = VALUES(firm_name), bio = VALUES(bio)`
+
+	_, err := m.DB.Exec(stmt, p.UserID, nullString(p.BarRegistrationNumber), nullInt(p.YearsOfExperience),
+		nullString(p.Specialization), nullString(p.FirmName), nullString(p.Bio))
+	return err
+}
+
+// RecruiterProfileModel wraps a database connection pool
+type RecruiterProfileModel struct {
+	DB *sql.DB
+}
+
+// Get retrieves the profile for a user
+func (m *RecruiterProfileModel) Get(userID int) (*RecruiterProfile, error) {
+	stmt := `SELECT id, user_id, company_name, COALESCE(position, ''), COALESCE(company_website, ''),
+		COALESCE(bio, ''), anonymous_views, blind_review, created_at, updated_at
+		FROM recruiter_profiles WHERE user_id = ?`
+
+	var p RecruiterProfile
+
+	err := m.DB.QueryRow(stmt, userID).Scan(
+		&p.ID,
+		&p.UserID,
+		&p.CompanyName,
+		&p.Position,
+		&p.CompanyWebsite,
+		&p.Bio,
+		&p.AnonymousViews,
+		&p.BlindReview,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Upsert creates or replaces the profile for p.UserID
+func (m *RecruiterProfileModel) Upsert(p *RecruiterProfile) error {
+	stmt := `INSERT INTO recruiter_profiles (user_id, company_name, position, company_website, bio, anonymous_views)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE company_name = VALUES(company_name), position = VALUES(position),
+		company_website = VALUES(company_website), bio = VALUES(bio), anonymous_views = VALUES(anonymous_views)`
+
+	_, err := m.DB.Exec(stmt, p.UserID, p.CompanyName, nullString(p.Position),
+		nullString(p.CompanyWebsite), nullString(p.Bio), p.AnonymousViews)
+	return err
+}
+
+// BlindReview reports whether a recruiter has blind review switched on
+func (m *RecruiterProfileModel) BlindReview(userID int) (bool, error) {
+	stmt := `SELECT blind_review FROM recruiter_profiles WHERE user_id = ?`
+
+	var on bool
+	err := m.DB.QueryRow(stmt, userID).Scan(&on)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+	return on, nil
+}
+
+// SetBlindReview switches blind review on or off, creating the profile if
+// the recruiter hasn't filled it in yet. Each change is logged.
+func (m *RecruiterProfileModel) SetBlindReview(userID int, on bool) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt := `INSERT INTO recruiter_profiles (user_id, company_name, blind_review) VALUES (?, '', ?)
+		ON DUPLICATE KEY UPDATE blind_review = VALUES(blind_review)`
+
+	result, err := tx.Exec(stmt, userID, on)
+	if err != nil {
+		return err
+	}
+
+	// No rows are affected when the setting is unchanged
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(`INSERT INTO blind_review_changes (recruiter_id, blind_review, changed_at)
+		VALUES (?, ?, UTC_TIMESTAMP())`, userID, on)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetBarRegistration records a lawyer's verified enrolment number, creating
+// the profile if the lawyer hasn't filled it in yet
+func (m *LawyerProfileModel) SetBarRegistration(userID int, number string) error {
+	stmt := `INSERT INTO lawyer_profiles (user_id, bar_registration_number) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE bar_registration_number = VALUES(bar_registration_number)`
+
+	_, err := m.DB.Exec(stmt, userID, number)
+	return err
+}
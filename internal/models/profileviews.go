@@ -0,0 +1,135 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ProfileView summarises one recruiter's visits to a candidate's profile
+type ProfileView struct {
+	CompanyName  string // empty when the recruiter browses anonymously
+	Views        int
+	LastViewedAt time.Time
+}
+
+// WeeklyViews is the number of profile views in the week starting on
+// WeekStart (a Monday)
+type WeeklyViews struct {
+	WeekStart time.Time
+	Views     int
+}
+
+// ProfileViewModel wraps a database connection pool
+type ProfileViewModel struct {
+	DB *sql.DB
+}
+
+// Record notes that a recruiter opened a candidate's profile. Views by the
+// same recruiter on the same day are counted once.
+func (m *ProfileViewModel) Record(candidateID, recruiterID int) error {
+	stmt := `INSERT INTO profile_views (candidate_id, recruiter_id, viewed_on, viewed_at)
+		VALUES (?, ?, UTC_DATE(), UTC_TIMESTAMP())
+		ON DUPLICATE KEY UPDATE viewed_at = VALUES(viewed_at)`
+
+	_, err := m.DB.Exec(stmt, candidateID, recruiterID)
+	return err
+}
+
+// CountSince returns the number of views of a candidate's profile since the
+// given time
+func (m *ProfileViewModel) CountSince(candidateID int, since time.Time) (int, error) {
+	stmt := `SELECT COUNT(*) FROM profile_views WHERE candidate_id = ? AND viewed_on >= DATE(?)`
+
+	var count int
+	err := m.DB.QueryRow(stmt, candidateID, since.UTC()).Scan(&count)
+	return count, err
+}
+
+// Weekly returns the view counts for a candidate over the last n weeks,
+// oldest first, including weeks without any views
+func (m *ProfileViewModel) Weekly(candidateID, n int) ([]WeeklyViews, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	thisWeek := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	first := thisWeek.AddDate(0, 0, -7*(n-1))
+
+	stmt := `SELECT DATE_SUB(viewed_on, INTERVAL WEEKDAY(viewed_on) DAY) AS week_start, COUNT(*)
+		FROM profile_views
+		WHERE candidate_id = ? AND viewed_on >= ?
+		GROUP BY week_start`
+
+	rows, err := m.DB.Query(stmt, candidateID, first)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[time.Time]int)
+	for rows.Next() {
+		var week time.Time
+		var views int
+		err = rows.Scan(&week, &views)
+		if err != nil {
+			return nil, err
+		}
+		counts[week.UTC()] = views
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	weeks := make([]WeeklyViews, n)
+	for i := range weeks {
+		start := first.AddDate(0, 0, 7*i)
+		weeks[i] = WeeklyViews{WeekStart: start, Views: counts[start]}
+	}
+
+	return weeks, nil
+}
+
+// ViewedBy lists the recruiters who viewed a candidate's profile since the
+// given time, most recent first. Recruiters who browse anonymously are
+// listed without their company.
+func (m *ProfileViewModel) ViewedBy(candidateID int, since time.Time, limit int) ([]*ProfileView, error) {
+	stmt := `SELECT IF(MAX(rp.anonymous_views), '', COALESCE(MAX(rp.company_name), '')),
+		COUNT(*), MAX(pv.viewed_at)
+		FROM profile_views pv
+		LEFT JOIN recruiter_profiles rp ON rp.user_id = pv.recruiter_id
+		WHERE pv.candidate_id = ? AND pv.viewed_on >= DATE(?)
+		GROUP BY pv.recruiter_id
+		ORDER BY MAX(pv.viewed_at) DESC
+		LIMIT ?`
+
+	rows, err := m.DB.Query(stmt, candidateID, since.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var views []*ProfileView
+
+	for rows.Next() {
+		var v ProfileView
+		err = rows.Scan(&v.CompanyName, &v.Views, &v.LastViewedAt)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, &v)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+// CountReviewed returns the number of distinct candidates a recruiter has
+// viewed since the given time
+func (m *ProfileViewModel) CountReviewed(recruiterID int, since time.Time) (int, error) {
+	stmt := `SELECT COUNT(DISTINCT candidate_id) FROM profile_views
+		WHERE recruiter_id = ? AND viewed_on >= DATE(?)`
+
+	var count int
+	err := m.DB.QueryRow(stmt, recruiterID, since.UTC()).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// IdentityRevealModel wraps a database connection pool
+type IdentityRevealModel struct {
+	DB *sql.DB
+}
+
+// Insert logs that a recruiter in blind review revealed a candidate's
+// identity
+func (m *IdentityRevealModel) Insert(recruiterID, candidateID int) error {
+	stmt := `INSERT INTO identity_reveals (recruiter_id, candidate_id, revealed_at) VALUES (?, ?, UTC_TIMESTAMP())`
+
+	_, err := m.DB.Exec(stmt, recruiterID, candidateID)
+	return err
+}
+
+// Revealed returns when the recruiter first revealed each of the given
+// candidates. Candidates they haven't revealed are left out.
+func (m *IdentityRevealModel) Revealed(recruiterID int, candidateIDs []int) (map[int]time.Time, error) {
+	revealed := make(map[int]time.Time)
+	if len(candidateIDs) == 0 {
+		return revealed, nil
+	}
+
+	args := []any{recruiterID}
+	for _, id := range candidateIDs {
+		args = append(args, id)
+	}
+
+	stmt := `SELECT candidate_id, MIN(revealed_at) FROM identity_reveals
+		WHERE recruiter_id = ? AND candidate_id IN (?` + strings.Repeat(", ?", len(candidateIDs)-1) + `)
+		GROUP BY candidate_id`
+
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var at time.Time
+		if err = rows.Scan(&id, &at); err != nil {
+			return nil, err
+		}
+		revealed[id] = at
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revealed, nil
+}
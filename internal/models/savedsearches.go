@@ -0,0 +1,245 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// SearchFrequency is how often a saved search is checked for new matches
+type SearchFrequency string
+
+const (
+	SearchDaily  SearchFrequency = "daily"
+	SearchWeekly SearchFrequency = "weekly"
+	SearchOff    SearchFrequency = "off"
+)
+
+// SearchFrequencies lists the frequencies a recruiter can choose
+var SearchFrequencies = []SearchFrequency{SearchDaily, SearchWeekly, SearchOff}
+
+// ValidSearchFrequency reports whether f is a known frequency
+func ValidSearchFrequency(f SearchFrequency) bool {
+	for _, known := range SearchFrequencies {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Interval is the time between checks, or zero if the search isn't checked
+func (f SearchFrequency) Interval() time.Duration {
+	switch f {
+	case SearchDaily:
+		return 24 * time.Hour
+	case SearchWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// SavedSearch is a candidate search a recruiter has saved
+type SavedSearch struct {
+	ID          int
+	RecruiterID int
+	Name        string
+
+	// Query holds the search filters as a URL query string
+	Query string
+
+	Frequency     SearchFrequency
+	LastCheckedAt sql.NullTime
+	MatchCount    int
+	CreatedAt     time.Time
+}
+
+// SavedSearchModel wraps a database connection pool
+type SavedSearchModel struct {
+	DB *sql.DB
+}
+
+const savedSearchColumns = `s.id, s.recruiter_id, s.name, s.query, s.frequency, s.last_checked_at,
+	(SELECT COUNT(*) FROM saved_search_matches m WHERE m.search_id = s.id), s.created_at`
+
+func scanSavedSearch(row rowScanner) (*SavedSearch, error) {
+	var s SavedSearch
+	err := row.Scan(
+		&s.ID,
+		&s.RecruiterID,
+		&s.Name,
+		&s.Query,
+		&s.Frequency,
+		&s.LastCheckedAt,
+		&s.MatchCount,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func duplicateSavedSearchName(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 &&
+		strings.Contains(mysqlErr.Message, "unique_saved_search_name")
+}
+
+// Insert saves a search and returns its ID
+func (m *SavedSearchModel) Insert(recruiterID int, name, query string, frequency SearchFrequency) (int, error) {
+	stmt := `INSERT INTO saved_searches (recruiter_id, name, query, frequency) VALUES (?, ?, ?, ?)`
+
+	result, err := m.DB.Exec(stmt, recruiterID, name, query, frequency)
+	if err != nil {
+		if duplicateSavedSearchName(err) {
+			return 0, ErrDuplicateName
+		}
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Get retrieves one of the recruiter's saved searches
+func (m *SavedSearchModel) Get(id, recruiterID int) (*SavedSearch, error) {
+	stmt := `SELECT ` + savedSearchColumns + ` FROM saved_searches s
+		WHERE s.id = ? AND s.recruiter_id = ?`
+
+	s, err := scanSavedSearch(m.DB.QueryRow(stmt, id, recruiterID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// ListForRecruiter returns the recruiter's saved searches alphabetically
+func (m *SavedSearchModel) ListForRecruiter(recruiterID int) ([]*SavedSearch, error) {
+	stmt := `SELECT ` + savedSearchColumns + ` FROM saved_searches s
+		WHERE s.recruiter_id = ? ORDER BY s.name`
+
+	return m.list(stmt, recruiterID)
+}
+
+// Due returns the saved searches whose frequency says they should be checked
+// by the given time, oldest check first. Searches that have never been
+// checked are always due.
+func (m *SavedSearchModel) Due(by time.Time) ([]*SavedSearch, error) {
+	stmt := `SELECT ` + savedSearchColumns + ` FROM saved_searches s
+		JOIN users u ON u.id = s.recruiter_id
+		WHERE u.is_active = TRUE AND s.frequency <> 'off' AND (s.last_checked_at IS NULL
+			OR (s.frequency = 'daily' AND s.last_checked_at <= ?)
+			OR (s.frequency = 'weekly' AND s.last_checked_at <= ?))
+		ORDER BY s.last_checked_at`
+
+	by = by.UTC()
+	return m.list(stmt, by.Add(-SearchDaily.Interval()), by.Add(-SearchWeekly.Interval()))
+}
+
+func (m *SavedSearchModel) list(stmt string, args ...any) ([]*SavedSearch, error) {
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var searches []*SavedSearch
+
+	for rows.Next() {
+		s, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return searches, nil
+}
+
+// SetFrequency changes how often a saved search is checked
+func (m *SavedSearchModel) SetFrequency(id, recruiterID int, frequency SearchFrequency) error {
+	stmt := `UPDATE saved_searches SET frequency = ? WHERE id = ? AND recruiter_id = ?`
+
+	_, err := m.DB.Exec(stmt, frequency, id, recruiterID)
+	return err
+}
+
+// Delete removes a saved search
+func (m *SavedSearchModel) Delete(id, recruiterID int) error {
+	stmt := `DELETE FROM saved_searches WHERE id = ? AND recruiter_id = ?`
+
+	_, err := m.DB.Exec(stmt, id, recruiterID)
+	return err
+}
+
+// RecordMatches records the candidates a saved search currently matches and
+// marks it checked. It returns the IDs of the candidates it had not matched
+// before, in the order given.
+func (m *SavedSearchModel) RecordMatches(searchID int, candidateIDs []int) ([]int, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT candidate_id FROM saved_search_matches WHERE search_id = ? FOR UPDATE`, searchID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		known[id] = true
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var added []int
+	var placeholders []string
+	var args []any
+	for _, id := range candidateIDs {
+		if known[id] {
+			continue
+		}
+		known[id] = true
+		added = append(added, id)
+		placeholders = append(placeholders, "(?, ?)")
+		args = append(args, searchID, id)
+	}
+
+	if len(added) > 0 {
+		stmt := `INSERT INTO saved_search_matches (search_id, candidate_id) VALUES ` + strings.Join(placeholders, ", ")
+		_, err = tx.Exec(stmt, args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE saved_searches SET last_checked_at = UTC_TIMESTAMP() WHERE id = ?`, searchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return added, tx.Commit()
+}
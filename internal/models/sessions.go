@@ -4,14 +4,23 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/base32"
+	"errors"
 	"time"
 )
 
-// Session represents a user session
+// SessionLifetime matches the lifetime of the scs session cookie
+const SessionLifetime = 12 * time.Hour
+
+// Session represents one signed-in device in a user's session registry
 type Session struct {
-	Token  string
-	UserID int
-	Expiry time.Time
+	ID         int
+	Token      string
+	UserID     int
+	UserAgent  string
+	IPAddress  string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	Expiry     time.Time
 }
 
 // SessionModel wraps a database connection pool
@@ -19,21 +28,20 @@ type SessionModel struct {
 	DB *sql.DB
 }
 
-// Insert creates a new session for a user
-func (m *SessionModel) Insert(userID int) (string, error) {
+// Insert registers a new signed-in device for a user and returns its token
+func (m *SessionModel) Insert(userID int, userAgent, ipAddress string) (string, error) {
 	// Generate a random session token
 	token, err := generateSessionToken()
 	if err != nil {
 		return "", err
 	}
 
-	// Set session expiry to 12 hours from now
-	expiry := time.Now().Add(12 * time.Hour)
+	expiry := time.Now().UTC().Add(SessionLifetime)
 
-	stmt := `INSERT INTO sessions (token, user_id, expiry)
-		VALUES (?, ?, ?)`
+	stmt := `INSERT INTO user_sessions (token, user_id, user_agent, ip_address, created_at, last_seen_at, expiry)
+		VALUES (?, ?, ?, ?, UTC_TIMESTAMP(), UTC_TIMESTAMP(), ?)`
 
-	_, err = m.DB.Exec(stmt, token, userID, expiry)
+	_, err = m.DB.Exec(stmt, token, userID, truncate(userAgent, 255), truncate(ipAddress, 45), expiry)
 	if err != nil {
 		return "", err
 	}
@@ -41,53 +49,138 @@ func (m *SessionModel) Insert(userID int) (string, error) {
 	return token, nil
 }
 
-// Get retrieves the user ID associated with a session token
-func (m *SessionModel) Get(token string) (int, error) {
-	var userID int
-	var expiry time.Time
-
-	stmt := `SELECT user_id, expiry FROM sessions WHERE token = ?`
+// Get retrieves the session for a token
+func (m *SessionModel) Get(token string) (*Session, error) {
+	stmt := `SELECT id, token, user_id, user_agent, ip_address, created_at, last_seen_at, expiry
+		FROM user_sessions WHERE token = ?`
 
-	err := m.DB.QueryRow(stmt, token).Scan(&userID, &expiry)
+	session, err := scanSession(m.DB.QueryRow(stmt, token))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return 0, ErrNoRecord
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
 		}
-		return 0, err
+		return nil, err
 	}
 
 	// Check if the session has expired
-	if time.Now().After(expiry) {
-		return 0, ErrExpiredSession
+	if time.Now().After(session.Expiry) {
+		return nil, ErrExpiredSession
 	}
 
-	return userID, nil
+	return session, nil
+}
+
+// Touch updates the last-seen time of a session. Writes are throttled to
+// once a minute so page loads don't update the row every time.
+func (m *SessionModel) Touch(token, ipAddress string) error {
+	stmt := `UPDATE user_sessions SET last_seen_at = UTC_TIMESTAMP(), ip_address = ?
+		WHERE token = ? AND last_seen_at < UTC_TIMESTAMP() - INTERVAL 1 MINUTE`
+
+	_, err := m.DB.Exec(stmt, truncate(ipAddress, 45), token)
+	return err
+}
+
+// ListForUser returns a user's unexpired sessions, most recently active first
+func (m *SessionModel) ListForUser(userID int) ([]*Session, error) {
+	stmt := `SELECT id, token, user_id, user_agent, ip_address, created_at, last_seen_at, expiry
+		FROM user_sessions WHERE user_id = ? AND expiry > UTC_TIMESTAMP()
+		ORDER BY last_seen_at DESC`
+
+	rows, err := m.DB.Query(stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*Session
+
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
 }
 
 // Delete removes a session from the database
 func (m *SessionModel) Delete(token string) error {
-	stmt := `DELETE FROM sessions WHERE token = ?`
+	stmt := `DELETE FROM user_sessions WHERE token = ?`
 
 	_, err := m.DB.Exec(stmt, token)
 	return err
 }
 
+// DeleteForUser removes one of a user's sessions by ID
+func (m *SessionModel) DeleteForUser(id, userID int) error {
+	stmt := `DELETE FROM user_sessions WHERE id = ? AND user_id = ?`
+
+	result, err := m.DB.Exec(stmt, id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
 // DeleteAllForUser removes all sessions for a specific user
 func (m *SessionModel) DeleteAllForUser(userID int) error {
-	stmt := `DELETE FROM sessions WHERE user_id = ?`
+	stmt := `DELETE FROM user_sessions WHERE user_id = ?`
 
 	_, err := m.DB.Exec(stmt, userID)
 	return err
 }
 
+// DeleteOthersForUser removes all of a user's sessions except the one identified by token
+func (m *SessionModel) DeleteOthersForUser(userID int, token string) error {
+	stmt := `DELETE FROM user_sessions WHERE user_id = ? AND token <> ?`
+
+	_, err := m.DB.Exec(stmt, userID, token)
+	return err
+}
+
 // CleanupExpired removes all expired sessions from the database
 func (m *SessionModel) CleanupExpired() error {
-	stmt := `DELETE FROM sessions WHERE expiry < UTC_TIMESTAMP()`
+	stmt := `DELETE FROM user_sessions WHERE expiry < UTC_TIMESTAMP()`
 
 	_, err := m.DB.Exec(stmt)
 	return err
 }
 
+func scanSession(row rowScanner) (*Session, error) {
+	var session Session
+
+	err := row.Scan(
+		&session.ID,
+		&session.Token,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.Expiry,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
 // generateSessionToken creates a cryptographically secure random token
 func generateSessionToken() (string, error) {
 	b := make([]byte, 32)
@@ -100,3 +193,17 @@ func generateSessionToken() (string, error) {
 	token := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
 	return token, nil
 }
+
+// truncate shortens s to at most n characters so it fits its column.
+// utf8mb4 VARCHAR lengths count characters, not bytes, and cutting on a
+// character boundary keeps multibyte text valid.
+func truncate(s string, n int) string {
+	chars := 0
+	for i := range s {
+		if chars == n {
+			return s[:i]
+		}
+		chars++
+	}
+	return s
+}
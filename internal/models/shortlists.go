@@ -0,0 +1,398 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Shortlist is a recruiter's named list of candidates
+type Shortlist struct {
+	ID             int
+	OwnerID        int
+	OwnerName      string
+	Name           string
+	Shared         bool
+	CandidateCount int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ShortlistModel wraps a database connection pool
+type ShortlistModel struct {
+	DB *sql.DB
+}
+
+// shortlistAccess limits shortlists to those a recruiter may see: their own,
+// and shared lists owned by a member of the same organisation
+const shortlistAccess = `(s.owner_id = ? OR (s.shared = TRUE AND EXISTS (
+	SELECT 1 FROM organisation_members om
+	JOIN organisation_members mm ON mm.organisation_id = om.organisation_id
+	WHERE om.user_id = s.owner_id AND mm.user_id = ?)))`
+
+const shortlistColumns = `s.id, s.owner_id, u.name, s.name, s.shared,
+	(SELECT COUNT(*) FROM shortlist_candidates sc WHERE sc.shortlist_id = s.id),
+	s.created_at, s.updated_at`
+
+func scanShortlist(row rowScanner) (*Shortlist, error) {
+	var s Shortlist
+	err := row.Scan(
+		&s.ID,
+		&s.OwnerID,
+		&s.OwnerName,
+		&s.Name,
+		&s.Shared,
+		&s.CandidateCount,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func duplicateShortlistName(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 &&
+		strings.Contains(mysqlErr.Message, "unique_shortlist_name")
+}
+
+// Insert creates an empty shortlist and returns its ID
+func (m *ShortlistModel) Insert(ownerID int, name string) (int, error) {
+	stmt := `INSERT INTO shortlists (owner_id, name) VALUES (?, ?)`
+
+	result, err := m.DB.Exec(stmt, ownerID, name)
+	if err != nil {
+		if duplicateShortlistName(err) {
+			return 0, ErrDuplicateName
+		}
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Get retrieves a shortlist the recruiter may see
+func (m *ShortlistModel) Get(id, recruiterID int) (*Shortlist, error) {
+	stmt := `SELECT ` + shortlistColumns + `
+		FROM shortlists s JOIN users u ON u.id = s.owner_id
+		WHERE s.id = ? AND ` + shortlistAccess
+
+	s, err := scanShortlist(m.DB.QueryRow(stmt, id, recruiterID, recruiterID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// ListForRecruiter returns the recruiter's own shortlists followed by those
+// shared with them, each alphabetically
+func (m *ShortlistModel) ListForRecruiter(recruiterID int) ([]*Shortlist, error) {
+	stmt := `SELECT ` + shortlistColumns + `
+		FROM shortlists s JOIN users u ON u.id = s.owner_id
+		WHERE ` + shortlistAccess + `
+		ORDER BY s.owner_id <> ?, s.name`
+
+	rows, err := m.DB.Query(stmt, recruiterID, recruiterID, recruiterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shortlists []*Shortlist
+
+	for rows.Next() {
+		s, err := scanShortlist(rows)
+		if err != nil {
+			return nil, err
+		}
+		shortlists = append(shortlists, s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shortlists, nil
+}
+
+// Update renames a shortlist and sets whether it is shared. Only the owner
+// may change a shortlist.
+func (m *ShortlistModel) Update(id, ownerID int, name string, shared bool) error {
+	stmt := `UPDATE shortlists SET name = ?, shared = ? WHERE id = ? AND owner_id = ?`
+
+	_, err := m.DB.Exec(stmt, name, shared, id, ownerID)
+	if duplicateShortlistName(err) {
+		return ErrDuplicateName
+	}
+	return err
+}
+
+// Delete removes a shortlist. Only the owner may delete a shortlist.
+func (m *ShortlistModel) Delete(id, ownerID int) error {
+	stmt := `DELETE FROM shortlists WHERE id = ? AND owner_id = ?`
+
+	_, err := m.DB.Exec(stmt, id, ownerID)
+	return err
+}
+
+// AddCandidate adds a candidate to a shortlist. Adding a candidate twice is not an error.
+func (m *ShortlistModel) AddCandidate(shortlistID, candidateID, addedBy int) error {
+	stmt := `INSERT IGNORE INTO shortlist_candidates (shortlist_id, candidate_id, added_by) VALUES (?, ?, ?)`
+
+	_, err := m.DB.Exec(stmt, shortlistID, candidateID, addedBy)
+	return err
+}
+
+// RemoveCandidate removes a candidate from a shortlist
+func (m *ShortlistModel) RemoveCandidate(shortlistID, candidateID int) error {
+	stmt := `DELETE FROM shortlist_candidates WHERE shortlist_id = ? AND candidate_id = ?`
+
+	_, err := m.DB.Exec(stmt, shortlistID, candidateID)
+	return err
+}
+
+// Candidates returns the candidates on a shortlist, most recently added
+// first. Candidates who are not visible to the recruiter are left out.
+func (m *ShortlistModel) Candidates(shortlistID, recruiterID int) ([]*Candidate, error) {
+	stmt := `SELECT ` + candidateColumns + ` ` + candidateFrom + `
+		AND u.id IN (SELECT candidate_id FROM shortlist_candidates WHERE shortlist_id = ?)
+		ORDER BY (SELECT added_at FROM shortlist_candidates WHERE shortlist_id = ? AND candidate_id = u.id) DESC`
+
+	rows, err := m.DB.Query(stmt, recruiterID, shortlistID, shortlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*Candidate
+
+	for rows.Next() {
+		c, err := scanCandidate(rows)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// Containing returns the IDs of the recruiter's accessible shortlists that
+// include the candidate
+func (m *ShortlistModel) Containing(candidateID, recruiterID int) ([]int, error) {
+	stmt := `SELECT s.id FROM shortlists s
+		JOIN shortlist_candidates sc ON sc.shortlist_id = s.id
+		WHERE sc.candidate_id = ? AND ` + shortlistAccess
+
+	rows, err := m.DB.Query(stmt, candidateID, recruiterID, recruiterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// CountCandidates returns the number of distinct candidates on the
+// recruiter's own shortlists
+func (m *ShortlistModel) CountCandidates(ownerID int) (int, error) {
+	stmt := `SELECT COUNT(DISTINCT sc.candidate_id) FROM shortlist_candidates sc
+		JOIN shortlists s ON s.id = sc.shortlist_id
+		WHERE s.owner_id = ?`
+
+	var count int
+	err := m.DB.QueryRow(stmt, ownerID).Scan(&count)
+	return count, err
+}
+
+// CountRecruiters returns the number of recruiters who have the candidate on
+// at least one of their shortlists
+func (m *ShortlistModel) CountRecruiters(candidateID int) (int, error) {
+	stmt := `SELECT COUNT(DISTINCT s.owner_id) FROM shortlist_candidates sc
+		JOIN shortlists s ON s.id = sc.shortlist_id
+		WHERE sc.candidate_id = ?`
+
+	var count int
+	err := m.DB.QueryRow(stmt, candidateID).Scan(&count)
+	return count, err
+}
+
+// CandidateNote is a recruiter's note and tags on a candidate. Tags are
+// always private; a shared note is visible to the rest of the recruiter's
+// organisation.
+type CandidateNote struct {
+	RecruiterID   int
+	RecruiterName string
+	CandidateID   int
+	Note          string
+	Shared        bool
+	Tags          []string
+	UpdatedAt     sql.NullTime
+}
+
+// CandidateNoteModel wraps a database connection pool
+type CandidateNoteModel struct {
+	DB *sql.DB
+}
+
+// Get retrieves the recruiter's note and tags on a candidate. A candidate
+// without either gets an empty note rather than ErrNoRecord.
+func (m *CandidateNoteModel) Get(recruiterID, candidateID int) (*CandidateNote, error) {
+	n := &CandidateNote{RecruiterID: recruiterID, CandidateID: candidateID}
+
+	stmt := `SELECT note, shared, updated_at FROM candidate_notes WHERE recruiter_id = ? AND candidate_id = ?`
+
+	err := m.DB.QueryRow(stmt, recruiterID, candidateID).Scan(&n.Note, &n.Shared, &n.UpdatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	tags, err := m.Tags(recruiterID, []int{candidateID})
+	if err != nil {
+		return nil, err
+	}
+	n.Tags = tags[candidateID]
+
+	return n, nil
+}
+
+// Save replaces the recruiter's note and tags on a candidate. An empty note
+// is deleted.
+func (m *CandidateNoteModel) Save(n *CandidateNote) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if n.Note == "" {
+		_, err = tx.Exec(`DELETE FROM candidate_notes WHERE recruiter_id = ? AND candidate_id = ?`,
+			n.RecruiterID, n.CandidateID)
+	} else {
+		stmt := `INSERT INTO candidate_notes (recruiter_id, candidate_id, note, shared) VALUES (?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE note = VALUES(note), shared = VALUES(shared)`
+		_, err = tx.Exec(stmt, n.RecruiterID, n.CandidateID, n.Note, n.Shared)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM candidate_tags WHERE recruiter_id = ? AND candidate_id = ?`,
+		n.RecruiterID, n.CandidateID)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range n.Tags {
+		_, err = tx.Exec(`INSERT IGNORE INTO candidate_tags (recruiter_id, candidate_id, tag) VALUES (?, ?, ?)`,
+			n.RecruiterID, n.CandidateID, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Team returns the notes on a candidate that the recruiter's organisation
+// colleagues have shared, most recently updated first
+func (m *CandidateNoteModel) Team(recruiterID, candidateID int) ([]*CandidateNote, error) {
+	stmt := `SELECT n.recruiter_id, u.name, n.note, n.updated_at
+		FROM candidate_notes n
+		JOIN users u ON u.id = n.recruiter_id
+		JOIN organisation_members om ON om.user_id = n.recruiter_id
+		JOIN organisation_members mm ON mm.organisation_id = om.organisation_id
+		WHERE n.candidate_id = ? AND n.shared = TRUE AND mm.user_id = ? AND n.recruiter_id <> mm.user_id
+		ORDER BY n.updated_at DESC`
+
+	rows, err := m.DB.Query(stmt, candidateID, recruiterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []*CandidateNote
+
+	for rows.Next() {
+		n := &CandidateNote{CandidateID: candidateID, Shared: true}
+		err = rows.Scan(&n.RecruiterID, &n.RecruiterName, &n.Note, &n.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// Tags returns the recruiter's tags for each of the given candidates
+func (m *CandidateNoteModel) Tags(recruiterID int, candidateIDs []int) (map[int][]string, error) {
+	tags := make(map[int][]string)
+	if len(candidateIDs) == 0 {
+		return tags, nil
+	}
+
+	args := []any{recruiterID}
+	for _, id := range candidateIDs {
+		args = append(args, id)
+	}
+
+	stmt := `SELECT candidate_id, tag FROM candidate_tags
+		WHERE recruiter_id = ? AND candidate_id IN (?` + strings.Repeat(", ?", len(candidateIDs)-1) + `)
+		ORDER BY tag`
+
+	rows, err := m.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var tag string
+		if err = rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,260 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// APIScope names a permission that can be granted to an API token
+type APIScope string
+
+const (
+	ScopeUserRead       APIScope = "user:read"
+	ScopeCandidatesRead APIScope = "candidates:read"
+	ScopeProgressRead   APIScope = "progress:read"
+)
+
+// APIScopeInfo describes a scope for display on the account page
+type APIScopeInfo struct {
+	Scope       APIScope
+	Description string
+}
+
+// APIScopes lists every scope a user may grant
+var APIScopes = []APIScopeInfo{
+	{ScopeUserRead, "Read your name, email and role"},
+	{ScopeCandidatesRead, "Search candidates (recruiters only)"},
+	{ScopeProgressRead, "Read your moot score history (students only)"},
+}
+
+// apiTokenPrefix makes Lawbook tokens easy to recognise in logs and secret scanners
+const apiTokenPrefix = "lb_"
+
+// APIToken represents a personal API token. The plaintext value is never stored.
+type APIToken struct {
+	ID         int
+	UserID     int
+	Name       string
+	Scopes     []APIScope
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	CreatedAt  time.Time
+}
+
+// HasScope reports whether the token was granted the given scope
+func (t *APIToken) HasScope(scope APIScope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired reports whether the token has passed its expiry time
+func (t *APIToken) Expired() bool {
+	return t.ExpiresAt.Valid && time.Now().After(t.ExpiresAt.Time)
+}
+
+// ValidScope reports whether scope is one of the known API scopes
+func ValidScope(scope APIScope) bool {
+	for _, s := range APIScopes {
+		if s.Scope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APITokenModel wraps a database connection pool
+type APITokenModel struct {
+	DB *sql.DB
+}
+
+// New mints a token for a user and returns its plaintext value. A zero ttl
+// creates a token that never expires.
+func (m *APITokenModel) New(userID int, name string, scopes []APIScope, ttl time.Duration) (string, error) {
+	plaintext, err := generateAPIToken()
+	if err != nil {
+		return "", err
+	}
+
+	var expiresAt sql.NullTime
+	if ttl > 0 {
+		expiresAt = sql.NullTime{Time: time.Now().UTC().Add(ttl), Valid: true}
+	}
+
+	stmt := `INSERT INTO api_tokens (user_id, name, token_hash, scopes, expires_at)
+		VALUES (?, ?, ?, ?, ?)`
+
+	_, err = m.DB.Exec(stmt, userID, name, hashToken(plaintext), joinScopes(scopes), expiresAt)
+	if err != nil {
+		return "", err
+	}
+
+	return plaintext, nil
+}
+
+// GetByPlaintext looks up an unexpired token by the value presented by a client
+func (m *APITokenModel) GetByPlaintext(plaintext string) (*APIToken, error) {
+	if !strings.HasPrefix(plaintext, apiTokenPrefix) {
+		return nil, ErrNoRecord
+	}
+
+	stmt := `SELECT id, user_id, name, scopes, expires_at, last_used_at, created_at
+		FROM api_tokens WHERE token_hash = ?`
+
+	token, err := scanAPIToken(m.DB.QueryRow(stmt, hashToken(plaintext)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+
+	if token.Expired() {
+		return nil, ErrExpiredToken
+	}
+
+	return token, nil
+}
+
+// ListForUser returns all tokens belonging to a user, newest first
+func (m *APITokenModel) ListForUser(userID int) ([]*APIToken, error) {
+	stmt := `SELECT id, user_id, name, scopes, expires_at, last_used_at, created_at
+		FROM api_tokens WHERE user_id = ? ORDER BY created_at DESC`
+
+	rows, err := m.DB.Query(stmt, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*APIToken
+
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Delete revokes a token. The user ID is checked so users can only revoke their own tokens.
+func (m *APITokenModel) Delete(id, userID int) error {
+	stmt := `DELETE FROM api_tokens WHERE id = ? AND user_id = ?`
+
+	result, err := m.DB.Exec(stmt, id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNoRecord
+	}
+
+	return nil
+}
+
+// Touch records that a token has just been used. Writes are throttled to
+// once a minute so busy clients don't update the row on every request.
+func (m *APITokenModel) Touch(id int) error {
+	stmt := `UPDATE api_tokens SET last_used_at = UTC_TIMESTAMP()
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < UTC_TIMESTAMP() - INTERVAL 1 MINUTE)`
+
+	_, err := m.DB.Exec(stmt, id)
+	return err
+}
+
+// DeleteExpired removes all tokens whose expiry time has passed
+func (m *APITokenModel) DeleteExpired() error {
+	stmt := `DELETE FROM api_tokens WHERE expires_at IS NOT NULL AND expires_at < UTC_TIMESTAMP()`
+
+	_, err := m.DB.Exec(stmt)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIToken(row rowScanner) (*APIToken, error) {
+	var token APIToken
+	var scopes string
+
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&scopes,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	token.Scopes = splitScopes(scopes)
+	return &token, nil
+}
+
+func joinScopes(scopes []APIScope) string {
+	s := make([]string, len(scopes))
+	for i, scope := range scopes {
+		s[i] = string(scope)
+	}
+	return strings.Join(s, " ")
+}
+
+func splitScopes(s string) []APIScope {
+	var scopes []APIScope
+	for _, f := range strings.Fields(s) {
+		scopes = append(scopes, APIScope(f))
+	}
+	return scopes
+}
+
+// generateAPIToken creates a random, prefixed token suitable for an Authorization header
+func generateAPIToken() (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	return apiTokenPrefix + token, nil
+}
+
+// randomToken returns a random, URL-safe token with 160 bits of entropy
+func randomToken() (string, error) {
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)), nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash under which a token is stored
+func hashToken(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
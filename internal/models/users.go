@@ -17,8 +17,12 @@ const (
 	RoleStudent   UserRole = "student"
 	RoleLawyer    UserRole = "lawyer"
 	RoleRecruiter UserRole = "recruiter"
+	RoleAdmin     UserRole = "admin"
 )
 
+// Roles lists every role, in the order they are shown in the admin console
+var Roles = []UserRole{RoleStudent, RoleLawyer, RoleRecruiter, RoleAdmin}
+
 // User represents a user in the system
 type User struct {
 	ID             int
@@ -84,11 +88,6 @@ func (m *UserModel) Authenticate(email, password string) (int, error) {
 		return 0, err
 	}
 
-	// Check if user account is active
-	if !isActive {
-		return 0, ErrInactiveAccount
-	}
-
 	// Compare the hashed password with the plain-text password
 	err = bcrypt.CompareHashAndPassword(hashedPassword, []byte(password))
 	if err != nil {
@@ -98,17 +97,32 @@ func (m *UserModel) Authenticate(email, password string) (int, error) {
 		return 0, err
 	}
 
+	// Check if user account is active. This happens after the password check
+	// so that deactivated accounts aren't revealed to someone guessing emails.
+	if !isActive {
+		return 0, ErrInactiveAccount
+	}
+
 	return id, nil
 }
 
 // Get retrieves a user by their ID
 func (m *UserModel) Get(id int) (*User, error) {
+	return m.get("id = ?", id)
+}
+
+// GetByEmail retrieves a user by their email address
+func (m *UserModel) GetByEmail(email string) (*User, error) {
+	return m.get("email = ?", email)
+}
+
+func (m *UserModel) get(where string, arg any) (*User, error) {
 	stmt := `SELECT id, name, email, role, created_at, updated_at, is_active, email_verified
-		FROM users WHERE id = ?`
+		FROM users WHERE ` + where
 
 	var user User
 
-	err := m.DB.QueryRow(stmt, id).Scan(
+	err := m.DB.QueryRow(stmt, arg).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
@@ -138,6 +152,16 @@ func (m *UserModel) Exists(id int) (bool, error) {
 	return exists, err
 }
 
+// IsActive reports whether a user exists and has not been deactivated
+func (m *UserModel) IsActive(id int) (bool, error) {
+	var active bool
+
+	stmt := `SELECT EXISTS(SELECT 1 FROM users WHERE id = ? AND is_active = TRUE)`
+
+	err := m.DB.QueryRow(stmt, id).Scan(&active)
+	return active, err
+}
+
 // UpdatePassword changes a user's password
 func (m *UserModel) UpdatePassword(id int, newPassword string) error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), 12)
@@ -159,6 +183,39 @@ func (m *UserModel) VerifyEmail(id int) error {
 	return err
 }
 
+// CalendarToken returns the secret in the user's calendar feed address, or
+// an empty string if they don't have one yet
+func (m *UserModel) CalendarToken(id int) (string, error) {
+	stmt := `SELECT COALESCE(calendar_token, '') FROM users WHERE id = ?`
+
+	var token string
+	err := m.DB.QueryRow(stmt, id).Scan(&token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrNoRecord
+		}
+		return "", err
+	}
+	return token, nil
+}
+
+// ResetCalendarToken gives the user a new calendar feed secret, so the old
+// feed address stops working, and returns it
+func (m *UserModel) ResetCalendarToken(id int) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	stmt := `UPDATE users SET calendar_token = ?, updated_at = UTC_TIMESTAMP() WHERE id = ?`
+
+	_, err = m.DB.Exec(stmt, token, id)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
 // DeactivateUser sets a user's account to inactive
 func (m *UserModel) DeactivateUser(id int) error {
 	stmt := `UPDATE users SET is_active = FALSE, updated_at = UTC_TIMESTAMP() WHERE id = ?`
@@ -167,6 +224,74 @@ func (m *UserModel) DeactivateUser(id int) error {
 	return err
 }
 
+// ReactivateUser sets a deactivated user's account back to active
+func (m *UserModel) ReactivateUser(id int) error {
+	stmt := `UPDATE users SET is_active = TRUE, updated_at = UTC_TIMESTAMP() WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, id)
+	return err
+}
+
+// UpdateRole changes a user's role
+func (m *UserModel) UpdateRole(id int, role UserRole) error {
+	stmt := `UPDATE users SET role = ?, updated_at = UTC_TIMESTAMP() WHERE id = ?`
+
+	_, err := m.DB.Exec(stmt, role, id)
+	return err
+}
+
+// Search returns a page of users whose name or email contains query,
+// optionally restricted to one role, along with the total number of matches
+func (m *UserModel) Search(query string, role UserRole, limit, offset int) ([]*User, int, error) {
+	stmt := `SELECT COUNT(*) OVER(), id, name, email, role, created_at, updated_at, is_active, email_verified
+		FROM users
+		WHERE (? = '' OR name LIKE ? OR email LIKE ?)
+		AND (? = '' OR role = ?)
+		ORDER BY created_at DESC LIMIT ? OFFSET ?`
+
+	pattern := likePattern(query)
+
+	rows, err := m.DB.Query(stmt, query, pattern, pattern, role, role, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []*User
+	total := 0
+
+	for rows.Next() {
+		var user User
+		err = rows.Scan(
+			&total,
+			&user.ID,
+			&user.Name,
+			&user.Email,
+			&user.Role,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.IsActive,
+			&user.EmailVerified,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, &user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// likePattern builds a LIKE pattern matching s anywhere, escaping wildcards in s
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
 // GetByRole retrieves all users with a specific role (useful for admin functions)
 func (m *UserModel) GetByRole(role UserRole, limit, offset int) ([]*User, error) {
 	stmt := `SELECT id, name, email, role, created_at, updated_at, is_active, email_verified
@@ -0,0 +1,258 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// VerificationStatus is the review state of a bar registration submission
+type VerificationStatus string
+
+const (
+	VerificationPending  VerificationStatus = "pending"
+	VerificationApproved VerificationStatus = "approved"
+	VerificationRejected VerificationStatus = "rejected"
+)
+
+// StateBarCouncils lists the bar councils a lawyer can be enrolled with
+var StateBarCouncils = []string{
+	"Andhra Pradesh",
+	"Assam, Nagaland, Meghalaya, Manipur, Tripura, Mizoram & Arunachal Pradesh",
+	"Bihar",
+	"Chhattisgarh",
+	"Delhi",
+	"Gujarat",
+	"Himachal Pradesh",
+	"Jammu & Kashmir and Ladakh",
+	"Jharkhand",
+	"Karnataka",
+	"Kerala",
+	"Madhya Pradesh",
+	"Maharashtra & Goa",
+	"Odisha",
+	"Punjab & Haryana",
+	"Rajasthan",
+	"Tamil Nadu & Puducherry",
+	"Telangana",
+	"Uttar Pradesh",
+	"Uttarakhand",
+	"West Bengal",
+}
+
+// ValidStateBarCouncil reports whether name is one of StateBarCouncils
+func ValidStateBarCouncil(name string) bool {
+	for _, c := range StateBarCouncils {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LawyerVerification is one bar registration submission by a lawyer
+type LawyerVerification struct {
+	ID              int
+	UserID          int
+	UserName        string
+	UserEmail       string
+	EnrolmentNumber string
+	StateBarCouncil string
+	DocumentPath    string
+	DocumentName    string
+	DocumentType    string
+	Status          VerificationStatus
+	Reason          string
+	ReviewedBy      sql.NullInt64
+	ReviewerName    sql.NullString
+	ReviewedAt      sql.NullTime
+	CreatedAt       time.Time
+}
+
+// Approved reports whether the submission has been approved
+func (v *LawyerVerification) Approved() bool {
+	return v.Status == VerificationApproved
+}
+
+// Pending reports whether the submission is waiting for review
+func (v *LawyerVerification) Pending() bool {
+	return v.Status == VerificationPending
+}
+
+// LawyerVerificationModel wraps a database connection pool
+type LawyerVerificationModel struct {
+	DB *sql.DB
+}
+
+const verificationColumns = `v.id, v.user_id, u.name, u.email, v.enrolment_number, v.state_bar_council,
+	v.document_path, v.document_name, v.document_type, v.status, v.reason,
+	v.reviewed_by, r.name, v.reviewed_at, v.created_at`
+
+const verificationJoins = `FROM lawyer_verifications v
+	INNER JOIN users u ON u.id = v.user_id
+	LEFT JOIN users r ON r.id = v.reviewed_by`
+
+func scanVerification(row rowScanner, extra ...any) (*LawyerVerification, error) {
+	var v LawyerVerification
+
+	dest := append(extra,
+		&v.ID,
+		&v.UserID,
+		&v.UserName,
+		&v.UserEmail,
+		&v.EnrolmentNumber,
+		&v.StateBarCouncil,
+		&v.DocumentPath,
+		&v.DocumentName,
+		&v.DocumentType,
+		&v.Status,
+		&v.Reason,
+		&v.ReviewedBy,
+		&v.ReviewerName,
+		&v.ReviewedAt,
+		&v.CreatedAt,
+	)
+
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Insert records a new pending submission and returns its ID
+func (m *LawyerVerificationModel) Insert(v *LawyerVerification) (int, error) {
+	stmt := `INSERT INTO lawyer_verifications
+		(user_id, enrolment_number, state_bar_council, document_path, document_name, document_type)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	result, err := m.DB.Exec(stmt, v.UserID, v.EnrolmentNumber, v.StateBarCouncil,
+		v.DocumentPath, truncate(v.DocumentName, 255), v.DocumentType)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// Get retrieves a submission by ID
+func (m *LawyerVerificationModel) Get(id int) (*LawyerVerification, error) {
+	stmt := `SELECT ` + verificationColumns + ` ` + verificationJoins + ` WHERE v.id = ?`
+
+	v, err := scanVerification(m.DB.QueryRow(stmt, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// Latest retrieves a lawyer's most recent submission
+func (m *LawyerVerificationModel) Latest(userID int) (*LawyerVerification, error) {
+	stmt := `SELECT ` + verificationColumns + ` ` + verificationJoins + `
+		WHERE v.user_id = ? ORDER BY v.created_at DESC, v.id DESC LIMIT 1`
+
+	v, err := scanVerification(m.DB.QueryRow(stmt, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// Current retrieves the submission that decides a lawyer's status: their
+// latest approved submission, or their most recent one if none has been
+// approved. A lawyer who resubmits after being approved stays verified while
+// the new submission is reviewed.
+func (m *LawyerVerificationModel) Current(userID int) (*LawyerVerification, error) {
+	stmt := `SELECT ` + verificationColumns + ` ` + verificationJoins + `
+		WHERE v.user_id = ? ORDER BY v.status = 'approved' DESC, v.created_at DESC, v.id DESC LIMIT 1`
+
+	v, err := scanVerification(m.DB.QueryRow(stmt, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoRecord
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+// IsVerified reports whether any of a lawyer's submissions has been approved
+func (m *LawyerVerificationModel) IsVerified(userID int) (bool, error) {
+	v, err := m.Current(userID)
+	if err != nil {
+		if errors.Is(err, ErrNoRecord) {
+			return false, nil
+		}
+		return false, err
+	}
+	return v.Approved(), nil
+}
+
+// List returns a page of submissions with the given status, and the total
+// number of matches. An empty status lists every submission. The pending
+// queue is ordered oldest first so it is worked in order; everything else
+// is newest first.
+func (m *LawyerVerificationModel) List(status VerificationStatus, limit, offset int) ([]*LawyerVerification, int, error) {
+	order := "DESC"
+	if status == VerificationPending {
+		order = "ASC"
+	}
+
+	stmt := `SELECT COUNT(*) OVER(), ` + verificationColumns + ` ` + verificationJoins + `
+		WHERE (? = '' OR v.status = ?)
+		ORDER BY v.created_at ` + order + `, v.id ` + order + ` LIMIT ? OFFSET ?`
+
+	rows, err := m.DB.Query(stmt, status, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var verifications []*LawyerVerification
+	total := 0
+
+	for rows.Next() {
+		v, err := scanVerification(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		verifications = append(verifications, v)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return verifications, total, nil
+}
+
+// Review records an admin's decision on a pending submission. It returns
+// ErrNoRecord if the submission doesn't exist or has already been reviewed.
+func (m *LawyerVerificationModel) Review(id, reviewerID int, status VerificationStatus, reason string) error {
+	stmt := `UPDATE lawyer_verifications SET status = ?, reason = ?, reviewed_by = ?, reviewed_at = UTC_TIMESTAMP()
+		WHERE id = ? AND status = 'pending'`
+
+	result, err := m.DB.Exec(stmt, status, truncate(reason, 500), reviewerID, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNoRecord
+	}
+	return nil
+}
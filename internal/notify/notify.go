@@ -0,0 +1,121 @@
+// Package notify tells users when something happens that concerns them.
+//
+// Handlers and jobs publish an Event for a user, and the Publisher delivers
+// it to the user's notification centre, their inbox or both, following the
+// preferences they have set for that type of notification.
+package notify
+
+import (
+	"errors"
+	"log"
+
+	"lawbook/internal/mailer"
+	"lawbook/internal/models"
+)
+
+// defaultTemplate is the email template for events that don't name one
+const defaultTemplate = "notification.tmpl"
+
+// Event is something a user should hear about
+type Event struct {
+	Type  models.NotificationType
+	Title string
+
+	// Body is optional detail shown under the title
+	Body string
+
+	// URL is the site path the notification links to, such as "/messages/4"
+	URL string
+
+	// Template is the email template to send, notification.tmpl if empty.
+	// It is rendered with Data plus the recipient's Name and the event's
+	// Title, Body and absolute URL.
+	Template string
+	Data     map[string]any
+
+	// Attachments are attached to the email, if one is sent
+	Attachments []mailer.Attachment
+}
+
+// Publisher delivers events to users. It is safe for concurrent use.
+type Publisher struct {
+	models      *models.Models
+	mailer      mailer.Mailer
+	templateDir string
+	baseURL     string
+	errorLog    *log.Logger
+}
+
+// New returns a publisher that renders emails from the templates in
+// templateDir, linking back to the site at baseURL
+func New(m *models.Models, mail mailer.Mailer, templateDir, baseURL string, errorLog *log.Logger) *Publisher {
+	return &Publisher{
+		models:      m,
+		mailer:      mail,
+		templateDir: templateDir,
+		baseURL:     baseURL,
+		errorLog:    errorLog,
+	}
+}
+
+// Publish delivers an event to a user according to their preferences.
+// Deactivated users aren't notified. A failure to send the email is logged
+// rather than returned, since whatever the event describes has already
+// happened.
+func (p *Publisher) Publish(userID int, e Event) error {
+	user, err := p.models.Users.Get(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNoRecord) {
+			return nil
+		}
+		return err
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	pref, err := p.models.Notifications.Preference(userID, e.Type)
+	if err != nil {
+		return err
+	}
+
+	if pref.InApp {
+		_, err = p.models.Notifications.Insert(userID, e.Type, e.Title, e.Body, e.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	if pref.Email {
+		err = p.email(user, e)
+		if err != nil {
+			p.errorLog.Printf("emailing %s notification to %s: %s", e.Type, user.Email, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Publisher) email(user *models.User, e Event) error {
+	data := map[string]any{}
+	for k, v := range e.Data {
+		data[k] = v
+	}
+	data["Name"] = user.Name
+	data["Title"] = e.Title
+	data["Body"] = e.Body
+	data["URL"] = p.baseURL + e.URL
+
+	template := e.Template
+	if template == "" {
+		template = defaultTemplate
+	}
+
+	msg, err := mailer.Render(p.templateDir, template, user.Email, data)
+	if err != nil {
+		return err
+	}
+	msg.Attachments = e.Attachments
+
+	return p.mailer.Send(msg)
+}
@@ -0,0 +1,237 @@
+package stats
+
+import (
+	"database/sql"
+	"sort"
+
+	"lawbook/internal/models"
+)
+
+// MovingAverageWindow is the number of sessions averaged for each point of a
+// moving average
+const MovingAverageWindow = 3
+
+// Skill is one of the criteria a moot evaluation scores
+type Skill string
+
+const (
+	SkillOverall         Skill = "overall"
+	SkillLegalKnowledge  Skill = "legal_knowledge"
+	SkillArgumentation   Skill = "argumentation"
+	SkillPresentation    Skill = "presentation"
+	SkillResponseQuality Skill = "response_quality"
+)
+
+// Skills lists the individual criteria, not including the overall score
+var Skills = []Skill{SkillLegalKnowledge, SkillArgumentation, SkillPresentation, SkillResponseQuality}
+
+// Name returns the skill's display name
+func (s Skill) Name() string {
+	switch s {
+	case SkillOverall:
+		return "Overall"
+	case SkillLegalKnowledge:
+		return "Legal Knowledge"
+	case SkillArgumentation:
+		return "Argumentation"
+	case SkillPresentation:
+		return "Presentation"
+	case SkillResponseQuality:
+		return "Response Quality"
+	default:
+		return string(s)
+	}
+}
+
+// Score returns the evaluation's score for the skill
+func (s Skill) Score(e *models.Evaluation) sql.NullFloat64 {
+	switch s {
+	case SkillOverall:
+		return e.OverallScore
+	case SkillLegalKnowledge:
+		return e.LegalKnowledgeScore
+	case SkillArgumentation:
+		return e.ArgumentationScore
+	case SkillPresentation:
+		return e.PresentationScore
+	case SkillResponseQuality:
+		return e.ResponseQualityScore
+	default:
+		return sql.NullFloat64{}
+	}
+}
+
+// Series is the history of one skill's score, one entry per evaluation
+type Series struct {
+	Skill         Skill
+	Scores        []sql.NullFloat64
+	MovingAverage []sql.NullFloat64
+	Average       sql.NullFloat64
+}
+
+// Latest returns the most recent score in the series
+func (s *Series) Latest() sql.NullFloat64 {
+	for i := len(s.Scores) - 1; i >= 0; i-- {
+		if s.Scores[i].Valid {
+			return s.Scores[i]
+		}
+	}
+	return sql.NullFloat64{}
+}
+
+// Group averages every skill over the evaluations of one case type or
+// difficulty. Averages holds the overall score followed by each of Skills.
+type Group struct {
+	Name     string
+	Sessions int
+	Averages []sql.NullFloat64
+}
+
+// Progress is a candidate's score history with breakdowns and moving
+// averages
+type Progress struct {
+	// Evaluations are oldest first, matching each series' scores
+	Evaluations  []*models.Evaluation
+	Overall      *Series
+	Skills       []*Series
+	ByCaseType   []*Group
+	ByDifficulty []*Group
+
+	// Strongest and Weakest are the criteria with the highest and lowest
+	// average, or nil without any scores
+	Strongest *Series
+	Weakest   *Series
+}
+
+// NewProgress builds a progress report from a candidate's evaluations, given
+// in any order
+func NewProgress(evaluations []*models.Evaluation) *Progress {
+	sorted := make([]*models.Evaluation, len(evaluations))
+	copy(sorted, evaluations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	p := &Progress{
+		Evaluations: sorted,
+		Overall:     newSeries(SkillOverall, sorted),
+	}
+
+	for _, skill := range Skills {
+		s := newSeries(skill, sorted)
+		p.Skills = append(p.Skills, s)
+
+		if !s.Average.Valid {
+			continue
+		}
+		if p.Strongest == nil || s.Average.Float64 > p.Strongest.Average.Float64 {
+			p.Strongest = s
+		}
+		if p.Weakest == nil || s.Average.Float64 < p.Weakest.Average.Float64 {
+			p.Weakest = s
+		}
+	}
+
+	p.ByCaseType = groupBy(sorted, func(e *models.Evaluation) string {
+		if e.CaseType == "" {
+			return "General"
+		}
+		return e.CaseType
+	})
+	p.ByDifficulty = groupBy(sorted, func(e *models.Evaluation) string { return e.Difficulty })
+
+	// Difficulties read easiest first rather than alphabetically
+	rank := make(map[string]int, len(models.Difficulties))
+	for i, d := range models.Difficulties {
+		rank[d] = i
+	}
+	sort.SliceStable(p.ByDifficulty, func(i, j int) bool {
+		return rank[p.ByDifficulty[i].Name] < rank[p.ByDifficulty[j].Name]
+	})
+
+	return p
+}
+
+func newSeries(skill Skill, evaluations []*models.Evaluation) *Series {
+	s := &Series{
+		Skill:         skill,
+		Scores:        make([]sql.NullFloat64, len(evaluations)),
+		MovingAverage: make([]sql.NullFloat64, len(evaluations)),
+	}
+
+	for i, e := range evaluations {
+		s.Scores[i] = skill.Score(e)
+	}
+
+	// The moving average covers the skill's last MovingAverageWindow scores
+	// up to each session, skipping sessions that didn't score it
+	s.Average = average(s.Scores)
+	var scored []sql.NullFloat64
+	for i, score := range s.Scores {
+		if !score.Valid {
+			continue
+		}
+		scored = append(scored, score)
+		s.MovingAverage[i] = average(scored[max(0, len(scored)-MovingAverageWindow):])
+	}
+
+	return s
+}
+
+// groupBy splits evaluations by key and averages each group, largest first
+func groupBy(evaluations []*models.Evaluation, key func(*models.Evaluation) string) []*Group {
+	skills := append([]Skill{SkillOverall}, Skills...)
+
+	scores := make(map[string][][]sql.NullFloat64)
+	var names []string
+
+	for _, e := range evaluations {
+		name := key(e)
+		if _, ok := scores[name]; !ok {
+			names = append(names, name)
+			scores[name] = make([][]sql.NullFloat64, len(skills))
+		}
+		for i, skill := range skills {
+			scores[name][i] = append(scores[name][i], skill.Score(e))
+		}
+	}
+
+	groups := make([]*Group, 0, len(names))
+	for _, name := range names {
+		g := &Group{Name: name, Sessions: len(scores[name][0])}
+		for _, s := range scores[name] {
+			g.Averages = append(g.Averages, average(s))
+		}
+		groups = append(groups, g)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Sessions == groups[j].Sessions {
+			return groups[i].Name < groups[j].Name
+		}
+		return groups[i].Sessions > groups[j].Sessions
+	})
+
+	return groups
+}
+
+// average returns the mean of the valid scores, or an invalid value if there
+// are none
+func average(scores []sql.NullFloat64) sql.NullFloat64 {
+	var sum float64
+	var n int
+	for _, s := range scores {
+		if s.Valid {
+			sum += s.Float64
+			n++
+		}
+	}
+
+	if n == 0 {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: sum / float64(n), Valid: true}
+}
@@ -0,0 +1,220 @@
+// Package stats computes the figures shown on the student, lawyer and
+// recruiter dashboards and candidates' progress reports.
+//
+// Each user's dashboard figures are cached for a short time, so reloading a
+// dashboard doesn't repeat the aggregate queries behind it.
+package stats
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"lawbook/internal/models"
+)
+
+const (
+	// ProfileViewDays is the window for a candidate's profile view count
+	ProfileViewDays = 30
+
+	// TrendWindow is the number of recent sessions compared with the same
+	// number before them to find a candidate's trend
+	TrendWindow = 5
+)
+
+// Tier is a candidate's skill level, based on their average overall score
+type Tier string
+
+const (
+	TierBeginner     Tier = "Beginner"
+	TierIntermediate Tier = "Intermediate"
+	TierAdvanced     Tier = "Advanced"
+	TierExpert       Tier = "Expert"
+)
+
+// tierFor places an average overall score (out of 100) into a tier. A
+// handful of sessions isn't enough to move past Beginner, and Expert needs a
+// sustained record.
+func tierFor(sessions int, average sql.NullFloat64) Tier {
+	switch {
+	case sessions < 3 || !average.Valid || average.Float64 < 50:
+		return TierBeginner
+	case average.Float64 >= 85 && sessions >= 10:
+		return TierExpert
+	case average.Float64 >= 70:
+		return TierAdvanced
+	default:
+		return TierIntermediate
+	}
+}
+
+// Dashboard holds the figures for one user's dashboard. Candidates (students
+// and lawyers) get the first group of fields, recruiters the second.
+type Dashboard struct {
+	Sessions      int
+	AverageScore  sql.NullFloat64
+	RecentScore   sql.NullFloat64
+	PreviousScore sql.NullFloat64
+	Tier          Tier
+	ProfileViews  int
+	ShortlistedBy int
+	Streak        int
+
+	Candidates  int
+	Reviewed    int
+	Shortlisted int
+
+	ComputedAt time.Time
+}
+
+// Trend is the change in average overall score between the candidate's last
+// TrendWindow sessions and the ones before them. It is invalid until there
+// are enough sessions to compare.
+func (d *Dashboard) Trend() sql.NullFloat64 {
+	if !d.RecentScore.Valid || !d.PreviousScore.Valid {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: d.RecentScore.Float64 - d.PreviousScore.Float64, Valid: true}
+}
+
+type entry struct {
+	dashboard *Dashboard
+	expires   time.Time
+}
+
+// Service computes and caches dashboard figures. It is safe for concurrent
+// use.
+type Service struct {
+	models *models.Models
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	entries   map[int]entry
+	lastSweep time.Time
+}
+
+// New returns a service that caches each user's figures for ttl
+func New(m *models.Models, ttl time.Duration) *Service {
+	return &Service{
+		models:  m,
+		ttl:     ttl,
+		entries: make(map[int]entry),
+	}
+}
+
+// ForUser returns the dashboard figures for a user, from the cache if they
+// were computed within the last ttl
+func (s *Service) ForUser(user *models.User) (*Dashboard, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	e, ok := s.entries[user.ID]
+	s.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.dashboard, nil
+	}
+
+	var d *Dashboard
+	var err error
+
+	switch user.Role {
+	case models.RoleRecruiter:
+		d, err = s.recruiter(user.ID, now)
+	default:
+		d, err = s.candidate(user.ID, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop expired entries now and again so users who don't come back
+	// aren't held forever
+	if now.Sub(s.lastSweep) > s.ttl {
+		for id, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, id)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	s.entries[user.ID] = entry{dashboard: d, expires: now.Add(s.ttl)}
+	return d, nil
+}
+
+// Invalidate discards a user's cached figures, for use after something that
+// changes them
+func (s *Service) Invalidate(userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, userID)
+}
+
+func (s *Service) candidate(userID int, now time.Time) (*Dashboard, error) {
+	highlights, err := s.models.Evaluations.Highlights(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trend, err := s.models.Evaluations.Trend(userID, TrendWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	views, err := s.models.ProfileViews.CountSince(userID, now.AddDate(0, 0, -ProfileViewDays))
+	if err != nil {
+		return nil, err
+	}
+
+	shortlistedBy, err := s.models.Shortlists.CountRecruiters(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := s.models.Evaluations.PracticeDays(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Dashboard{
+		Sessions:      highlights.Sessions,
+		AverageScore:  highlights.OverallScore,
+		RecentScore:   trend.Recent,
+		PreviousScore: trend.Previous,
+		Tier:          tierFor(highlights.Sessions, highlights.OverallScore),
+		ProfileViews:  views,
+		ShortlistedBy: shortlistedBy,
+		Streak:        CurrentStreak(days, now),
+		ComputedAt:    now,
+	}, nil
+}
+
+func (s *Service) recruiter(userID int, now time.Time) (*Dashboard, error) {
+	candidates, err := s.models.Candidates.CountWithScores(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	shortlisted, err := s.models.Shortlists.CountCandidates(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	utc := now.UTC()
+	monthStart := time.Date(utc.Year(), utc.Month(), 1, 0, 0, 0, 0, time.UTC)
+	reviewed, err := s.models.ProfileViews.CountReviewed(userID, monthStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Dashboard{
+		Candidates:  candidates,
+		Reviewed:    reviewed,
+		Shortlisted: shortlisted,
+		ComputedAt:  now,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+// practiceDays returns the distinct UTC dates among times, most recent first
+func practiceDays(times []time.Time) []time.Time {
+	seen := make(map[time.Time]bool, len(times))
+	var days []time.Time
+
+	for _, t := range times {
+		t = t.UTC()
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].After(days[j]) })
+	return days
+}
+
+// LongestStreak returns the longest run of consecutive days among the times
+// a candidate practised
+func LongestStreak(times []time.Time) int {
+	days := practiceDays(times)
+
+	longest, run := 0, 0
+	for i, day := range days {
+		if i > 0 && days[i-1].AddDate(0, 0, -1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	return longest
+}
+
+// CurrentStreak returns the run of consecutive days a candidate has practised
+// up to now. A streak isn't broken until a whole day passes without practice,
+// so one that ended yesterday still counts.
+func CurrentStreak(times []time.Time, now time.Time) int {
+	days := practiceDays(times)
+
+	now = now.UTC()
+	next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if len(days) > 0 && days[0].Before(next) {
+		next = next.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for _, day := range days {
+		if !day.Equal(next) {
+			break
+		}
+		streak++
+		next = next.AddDate(0, 0, -1)
+	}
+
+	return streak
+}
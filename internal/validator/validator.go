@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"net/url"
 	"regexp"
 	"strings"
 	"unicode/utf8"
@@ -53,3 +54,13 @@ func (v *Validator) AddNonFieldError(message string) {
 	v.NonFieldErrors = append(v.NonFieldErrors, message)
 
 }
+func Between(value, min, max int) bool {
+	return value >= min && value <= max
+}
+func ValidURL(value string) bool {
+	u, err := url.ParseRequestURI(value)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,16 @@
+USE lawbookauth;
+
+-- Personal API tokens for bearer authentication against the JSON API.
+-- Only the SHA-256 hash of a token is stored; the plaintext is shown once.
+CREATE TABLE api_tokens (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    scopes VARCHAR(255) NOT NULL,
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_api_tokens_user_id (user_id)
+);
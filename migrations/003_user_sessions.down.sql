@@ -0,0 +1,13 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS user_sessions;
+DROP TABLE IF EXISTS sessions;
+
+CREATE TABLE sessions (
+    token CHAR(43) NOT NULL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    expiry TIMESTAMP(6) NOT NULL,
+    INDEX idx_user_id (user_id),
+    INDEX idx_expiry (expiry),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
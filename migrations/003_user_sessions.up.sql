@@ -0,0 +1,28 @@
+USE lawbookauth;
+
+-- The original sessions table doesn't match the schema scs/mysqlstore expects
+-- (it has no data column), so replace it with the store's own layout.
+DROP TABLE IF EXISTS sessions;
+
+CREATE TABLE sessions (
+    token CHAR(43) PRIMARY KEY,
+    data BLOB NOT NULL,
+    expiry TIMESTAMP(6) NOT NULL
+);
+
+CREATE INDEX sessions_expiry_idx ON sessions (expiry);
+
+-- Device registry: one row per login so users can see and revoke their sessions
+CREATE TABLE user_sessions (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    token CHAR(52) NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expiry DATETIME NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_sessions_user_id (user_id),
+    INDEX idx_user_sessions_expiry (expiry)
+);
@@ -0,0 +1,3 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS job_runs;
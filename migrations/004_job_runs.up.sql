@@ -0,0 +1,13 @@
+USE lawbookauth;
+
+-- History of background job runs (see internal/jobs)
+CREATE TABLE job_runs (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    job_name VARCHAR(100) NOT NULL,
+    instance VARCHAR(255) NOT NULL,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME,
+    status ENUM('running', 'succeeded', 'failed') NOT NULL DEFAULT 'running',
+    error TEXT,
+    INDEX idx_job_runs_name_started (job_name, started_at)
+);
@@ -0,0 +1,7 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS password_resets;
+DROP TABLE IF EXISTS admin_audit_log;
+
+DELETE FROM users WHERE role = 'admin';
+ALTER TABLE users MODIFY role ENUM('student', 'lawyer', 'recruiter') NOT NULL;
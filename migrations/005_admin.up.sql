@@ -0,0 +1,25 @@
+USE lawbookauth;
+
+ALTER TABLE users MODIFY role ENUM('student', 'lawyer', 'recruiter', 'admin') NOT NULL;
+
+-- Every action taken in the admin console
+CREATE TABLE admin_audit_log (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    admin_id INTEGER,
+    action VARCHAR(50) NOT NULL,
+    target_user_id INTEGER,
+    details VARCHAR(500) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (admin_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (target_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    INDEX idx_admin_audit_log_created_at (created_at)
+);
+
+-- One-time password reset links
+CREATE TABLE password_resets (
+    token_hash CHAR(64) NOT NULL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,22 @@
+USE lawbookauth;
+
+-- Bar registration checks submitted by lawyers and reviewed by admins.
+-- A lawyer is verified while their most recent submission is approved.
+CREATE TABLE lawyer_verifications (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    enrolment_number VARCHAR(100) NOT NULL,
+    state_bar_council VARCHAR(100) NOT NULL,
+    document_path VARCHAR(255) NOT NULL,
+    document_name VARCHAR(255) NOT NULL,
+    document_type VARCHAR(100) NOT NULL,
+    status ENUM('pending', 'approved', 'rejected') NOT NULL DEFAULT 'pending',
+    reason VARCHAR(500) NOT NULL DEFAULT '',
+    reviewed_by INTEGER,
+    reviewed_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL,
+    INDEX idx_lawyer_verifications_user (user_id, created_at),
+    INDEX idx_lawyer_verifications_status (status, created_at)
+);
@@ -0,0 +1,4 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS portfolio_evaluations;
+DROP TABLE IF EXISTS portfolios;
@@ -0,0 +1,33 @@
+USE lawbookauth;
+
+-- Public portfolio pages for students and lawyers, served at /p/:slug.
+-- The show_* columns are the owner's per-field visibility choices.
+CREATE TABLE portfolios (
+    user_id INTEGER NOT NULL PRIMARY KEY,
+    slug VARCHAR(60) NOT NULL,
+    headline VARCHAR(160) NOT NULL DEFAULT '',
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    noindex BOOLEAN NOT NULL DEFAULT FALSE,
+    show_university BOOLEAN NOT NULL DEFAULT TRUE,
+    show_year_of_study BOOLEAN NOT NULL DEFAULT TRUE,
+    show_specialization BOOLEAN NOT NULL DEFAULT TRUE,
+    show_experience BOOLEAN NOT NULL DEFAULT TRUE,
+    show_firm BOOLEAN NOT NULL DEFAULT TRUE,
+    show_bio BOOLEAN NOT NULL DEFAULT TRUE,
+    show_bar_registration BOOLEAN NOT NULL DEFAULT FALSE,
+    show_verified BOOLEAN NOT NULL DEFAULT TRUE,
+    show_highlights BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_portfolio_slug (slug)
+);
+
+-- Moot results the owner has chosen to feature on their portfolio
+CREATE TABLE portfolio_evaluations (
+    user_id INTEGER NOT NULL,
+    evaluation_id INTEGER NOT NULL,
+    PRIMARY KEY (user_id, evaluation_id),
+    FOREIGN KEY (user_id) REFERENCES portfolios(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (evaluation_id) REFERENCES performance_evaluations(id) ON DELETE CASCADE
+);
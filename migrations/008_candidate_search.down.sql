@@ -0,0 +1,5 @@
+USE lawbookauth;
+
+DROP INDEX idx_moot_sessions_case_type ON moot_sessions;
+DROP INDEX idx_users_recruiter_visible ON users;
+ALTER TABLE users DROP COLUMN recruiter_visible;
@@ -0,0 +1,7 @@
+USE lawbookauth;
+
+-- Students and lawyers opt in before recruiters can find them
+ALTER TABLE users ADD recruiter_visible BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_users_recruiter_visible ON users(recruiter_visible, role, is_active);
+CREATE INDEX idx_moot_sessions_case_type ON moot_sessions(case_type);
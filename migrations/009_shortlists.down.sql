@@ -0,0 +1,6 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS candidate_tags;
+DROP TABLE IF EXISTS candidate_notes;
+DROP TABLE IF EXISTS shortlist_candidates;
+DROP TABLE IF EXISTS shortlists;
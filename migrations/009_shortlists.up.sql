@@ -0,0 +1,46 @@
+USE lawbookauth;
+
+-- Named lists of candidates kept by recruiters. Shared lists are visible to
+-- recruiters with the same company name on their profile.
+CREATE TABLE shortlists (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    owner_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    shared BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_shortlist_name (owner_id, name)
+);
+
+CREATE TABLE shortlist_candidates (
+    shortlist_id INTEGER NOT NULL,
+    candidate_id INTEGER NOT NULL,
+    added_by INTEGER,
+    added_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (shortlist_id, candidate_id),
+    FOREIGN KEY (shortlist_id) REFERENCES shortlists(id) ON DELETE CASCADE,
+    FOREIGN KEY (candidate_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (added_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- A recruiter's private note and tags on a candidate. Never shared.
+CREATE TABLE candidate_notes (
+    recruiter_id INTEGER NOT NULL,
+    candidate_id INTEGER NOT NULL,
+    note TEXT NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (recruiter_id, candidate_id),
+    FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (candidate_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE candidate_tags (
+    recruiter_id INTEGER NOT NULL,
+    candidate_id INTEGER NOT NULL,
+    tag VARCHAR(30) NOT NULL,
+    PRIMARY KEY (recruiter_id, candidate_id, tag),
+    FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (candidate_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_candidate_tags_tag (recruiter_id, tag)
+);
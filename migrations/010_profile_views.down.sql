@@ -0,0 +1,4 @@
+USE lawbookauth;
+
+ALTER TABLE recruiter_profiles DROP COLUMN anonymous_views;
+DROP TABLE IF EXISTS profile_views;
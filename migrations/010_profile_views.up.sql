@@ -0,0 +1,18 @@
+USE lawbookauth;
+
+-- One row per recruiter, candidate and day, so repeat visits on the same day
+-- count once
+CREATE TABLE profile_views (
+    candidate_id INTEGER NOT NULL,
+    recruiter_id INTEGER NOT NULL,
+    viewed_on DATE NOT NULL,
+    viewed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (candidate_id, recruiter_id, viewed_on),
+    FOREIGN KEY (candidate_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_profile_views_candidate (candidate_id, viewed_on),
+    INDEX idx_profile_views_recruiter (recruiter_id, viewed_on)
+);
+
+-- Recruiters can keep their company out of candidates' "viewed by" lists
+ALTER TABLE recruiter_profiles ADD anonymous_views BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,12 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS consent_history;
+ALTER TABLE performance_evaluations DROP COLUMN share_with_recruiters;
+DROP TABLE IF EXISTS candidate_approved_companies;
+
+ALTER TABLE users ADD recruiter_visible BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE users SET recruiter_visible = TRUE WHERE recruiter_visibility = 'all';
+
+DROP INDEX idx_users_recruiter_visibility ON users;
+ALTER TABLE users DROP COLUMN recruiter_visibility;
+CREATE INDEX idx_users_recruiter_visible ON users(recruiter_visible, role, is_active);
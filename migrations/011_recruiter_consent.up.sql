@@ -0,0 +1,37 @@
+USE lawbookauth;
+
+-- Who may see a student or lawyer in candidate search: every recruiter, only
+-- recruiters from companies the user has approved, or nobody. Users who had
+-- opted in keep being visible to everyone.
+ALTER TABLE users ADD recruiter_visibility ENUM('all', 'approved', 'hidden') NOT NULL DEFAULT 'hidden';
+UPDATE users SET recruiter_visibility = 'all' WHERE recruiter_visible = TRUE;
+
+DROP INDEX idx_users_recruiter_visible ON users;
+ALTER TABLE users DROP COLUMN recruiter_visible;
+CREATE INDEX idx_users_recruiter_visibility ON users(recruiter_visibility, role, is_active);
+
+-- Companies a user set to 'approved' has agreed to be seen by, matched
+-- against the company name on recruiters' profiles
+CREATE TABLE candidate_approved_companies (
+    user_id INTEGER NOT NULL,
+    company_name VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, company_name),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Individual evaluations can be withheld from recruiters
+ALTER TABLE performance_evaluations ADD share_with_recruiters BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Every change to a user's recruiter consent, kept for audit
+CREATE TABLE consent_history (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    recruiter_visibility ENUM('all', 'approved', 'hidden') NOT NULL,
+    approved_companies TEXT NOT NULL,
+    withheld_evaluations TEXT NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_consent_history_user (user_id, created_at)
+);
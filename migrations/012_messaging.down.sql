@@ -0,0 +1,6 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS conversation_reports;
+DROP TABLE IF EXISTS user_blocks;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversations;
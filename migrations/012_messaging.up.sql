@@ -0,0 +1,53 @@
+USE lawbookauth;
+
+-- A thread between a recruiter and a student or lawyer. It starts as a
+-- contact request that the candidate accepts or declines.
+CREATE TABLE conversations (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    recruiter_id INTEGER NOT NULL,
+    candidate_id INTEGER NOT NULL,
+    subject VARCHAR(150) NOT NULL,
+    status ENUM('pending', 'accepted', 'declined') NOT NULL DEFAULT 'pending',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_message_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    recruiter_read_at DATETIME,
+    candidate_read_at DATETIME,
+    FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (candidate_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_conversation (recruiter_id, candidate_id),
+    INDEX idx_conversations_candidate (candidate_id, last_message_at),
+    INDEX idx_conversations_recruiter_created (recruiter_id, created_at)
+);
+
+CREATE TABLE messages (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    conversation_id INTEGER NOT NULL,
+    sender_id INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+    FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_messages_conversation (conversation_id, created_at)
+);
+
+-- A block stops all messages between two users, whoever started the thread
+CREATE TABLE user_blocks (
+    blocker_id INTEGER NOT NULL,
+    blocked_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_id, blocked_id),
+    FOREIGN KEY (blocker_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Conversations reported to administrators
+CREATE TABLE conversation_reports (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    conversation_id INTEGER NOT NULL,
+    reporter_id INTEGER NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+    FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_conversation_reports_created_at (created_at)
+);
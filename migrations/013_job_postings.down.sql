@@ -0,0 +1,5 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS job_application_evaluations;
+DROP TABLE IF EXISTS job_applications;
+DROP TABLE IF EXISTS job_postings;
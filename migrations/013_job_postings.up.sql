@@ -0,0 +1,47 @@
+USE lawbookauth;
+
+-- Roles advertised by recruiters. A posting takes applications until its
+-- deadline, or until the recruiter closes it.
+CREATE TABLE job_postings (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    recruiter_id INTEGER NOT NULL,
+    title VARCHAR(150) NOT NULL,
+    practice_area VARCHAR(100) NOT NULL,
+    location VARCHAR(100) NOT NULL,
+    min_experience INTEGER NOT NULL DEFAULT 0,
+    max_experience INTEGER NOT NULL DEFAULT 0,
+    description TEXT NOT NULL,
+    deadline DATE NOT NULL,
+    closed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_job_postings_recruiter (recruiter_id, created_at),
+    INDEX idx_job_postings_deadline (closed, deadline)
+);
+
+-- A student's or lawyer's application and where it is in the recruiter's
+-- pipeline
+CREATE TABLE job_applications (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    posting_id INTEGER NOT NULL,
+    applicant_id INTEGER NOT NULL,
+    cover_letter TEXT NOT NULL,
+    include_portfolio BOOLEAN NOT NULL DEFAULT FALSE,
+    stage ENUM('applied', 'shortlisted', 'interview', 'offer', 'rejected') NOT NULL DEFAULT 'applied',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (posting_id) REFERENCES job_postings(id) ON DELETE CASCADE,
+    FOREIGN KEY (applicant_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_application (posting_id, applicant_id)
+);
+
+-- Moot results attached to an application. These are shown to the recruiter
+-- whatever the applicant's recruiter visibility settings.
+CREATE TABLE job_application_evaluations (
+    application_id INTEGER NOT NULL,
+    evaluation_id INTEGER NOT NULL,
+    PRIMARY KEY (application_id, evaluation_id),
+    FOREIGN KEY (application_id) REFERENCES job_applications(id) ON DELETE CASCADE,
+    FOREIGN KEY (evaluation_id) REFERENCES performance_evaluations(id) ON DELETE CASCADE
+);
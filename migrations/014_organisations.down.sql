@@ -0,0 +1,7 @@
+USE lawbookauth;
+
+ALTER TABLE candidate_notes DROP COLUMN shared;
+
+DROP TABLE IF EXISTS organisation_invites;
+DROP TABLE IF EXISTS organisation_members;
+DROP TABLE IF EXISTS organisations;
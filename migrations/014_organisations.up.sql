@@ -0,0 +1,40 @@
+USE lawbookauth;
+
+-- Companies that recruiters belong to. Recruiters with a verified email
+-- address at a verified domain join automatically.
+CREATE TABLE organisations (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL,
+    email_domain VARCHAR(255),
+    domain_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_organisation_domain (email_domain)
+);
+
+-- A recruiter belongs to at most one organisation
+CREATE TABLE organisation_members (
+    user_id INTEGER NOT NULL PRIMARY KEY,
+    organisation_id INTEGER NOT NULL,
+    role ENUM('owner', 'admin', 'member') NOT NULL DEFAULT 'member',
+    joined_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id) ON DELETE CASCADE,
+    INDEX idx_organisation_members_organisation (organisation_id)
+);
+
+CREATE TABLE organisation_invites (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    organisation_id INTEGER NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    role ENUM('admin', 'member') NOT NULL DEFAULT 'member',
+    invited_by INTEGER,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE KEY unique_organisation_invite (organisation_id, email),
+    INDEX idx_organisation_invites_email (email)
+);
+
+-- Notes can now be shared with the rest of the recruiter's organisation
+ALTER TABLE candidate_notes ADD COLUMN shared BOOLEAN NOT NULL DEFAULT FALSE;
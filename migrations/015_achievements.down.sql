@@ -0,0 +1,5 @@
+USE lawbookauth;
+
+ALTER TABLE portfolios DROP COLUMN show_achievements;
+
+DROP TABLE IF EXISTS achievements;
@@ -0,0 +1,15 @@
+USE lawbookauth;
+
+-- Badges earned by students and lawyers. The codes are defined in
+-- internal/achievements; seen_at is set once the owner has seen the badge on
+-- their dashboard.
+CREATE TABLE achievements (
+    user_id INTEGER NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    earned_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    seen_at DATETIME,
+    PRIMARY KEY (user_id, code),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+ALTER TABLE portfolios ADD COLUMN show_achievements BOOLEAN NOT NULL DEFAULT TRUE AFTER show_highlights;
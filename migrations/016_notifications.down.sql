@@ -0,0 +1,4 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,26 @@
+USE lawbookauth;
+
+-- In-app notifications shown in the notification centre
+CREATE TABLE notifications (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    user_id INTEGER NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    url VARCHAR(255) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    read_at DATETIME,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_notifications_user (user_id, read_at, created_at)
+);
+
+-- How each user wants to hear about each type of notification. Types
+-- without a row use the defaults in internal/models/notifications.go.
+CREATE TABLE notification_preferences (
+    user_id INTEGER NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    in_app BOOLEAN NOT NULL,
+    email BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, type),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
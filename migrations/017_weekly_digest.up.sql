@@ -0,0 +1,13 @@
+USE lawbookauth;
+
+-- The weekly digest is opt-in. candidate_filter holds the candidate search a
+-- recruiter has saved for their digest, as a URL query string.
+CREATE TABLE digest_settings (
+    user_id INTEGER NOT NULL PRIMARY KEY,
+    subscribed BOOLEAN NOT NULL DEFAULT FALSE,
+    candidate_filter VARCHAR(1000) NOT NULL DEFAULT '',
+    last_sent_at DATETIME,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_digest_subscribed (subscribed)
+);
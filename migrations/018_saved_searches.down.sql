@@ -0,0 +1,4 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS saved_search_matches;
+DROP TABLE IF EXISTS saved_searches;
@@ -0,0 +1,27 @@
+USE lawbookauth;
+
+-- Candidate searches recruiters have saved, as URL query strings, and how
+-- often to check them for new matches
+CREATE TABLE saved_searches (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    recruiter_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    query VARCHAR(1000) NOT NULL DEFAULT '',
+    frequency ENUM('daily', 'weekly', 'off') NOT NULL DEFAULT 'daily',
+    last_checked_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE KEY unique_saved_search_name (recruiter_id, name),
+    INDEX idx_saved_searches_frequency (frequency, last_checked_at)
+);
+
+-- Candidates each saved search has matched, so that only new matches are
+-- alerted
+CREATE TABLE saved_search_matches (
+    search_id INTEGER NOT NULL,
+    candidate_id INTEGER NOT NULL,
+    matched_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (search_id, candidate_id),
+    FOREIGN KEY (search_id) REFERENCES saved_searches(id) ON DELETE CASCADE,
+    FOREIGN KEY (candidate_id) REFERENCES users(id) ON DELETE CASCADE
+);
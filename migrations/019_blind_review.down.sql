@@ -0,0 +1,4 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS identity_reveals;
+ALTER TABLE recruiter_profiles DROP COLUMN blind_review;
@@ -0,0 +1,16 @@
+USE lawbookauth;
+
+-- Recruiters in blind review see candidates under pseudonyms, without names,
+-- universities or firms
+ALTER TABLE recruiter_profiles ADD blind_review BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Each time a recruiter in blind review chose to reveal a candidate's identity
+CREATE TABLE identity_reveals (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    recruiter_id INTEGER NOT NULL,
+    candidate_id INTEGER NOT NULL,
+    revealed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (candidate_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_identity_reveals_pair (recruiter_id, candidate_id)
+);
@@ -0,0 +1,4 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS interview_slots;
+DROP TABLE IF EXISTS interviews;
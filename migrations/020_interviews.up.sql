@@ -0,0 +1,33 @@
+USE lawbookauth;
+
+-- Interviews a recruiter has arranged with a candidate. Times are stored in
+-- UTC; time_zone is the IANA zone the recruiter proposed them in, used to
+-- show them. sequence counts changes, so calendar invites replace each other.
+CREATE TABLE interviews (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    recruiter_id INTEGER NOT NULL,
+    candidate_id INTEGER NOT NULL,
+    title VARCHAR(150) NOT NULL,
+    details VARCHAR(500) NOT NULL DEFAULT '',
+    duration_minutes INTEGER NOT NULL,
+    time_zone VARCHAR(64) NOT NULL,
+    status ENUM('proposed', 'scheduled', 'cancelled') NOT NULL DEFAULT 'proposed',
+    starts_at DATETIME,
+    sequence INTEGER NOT NULL DEFAULT 0,
+    reminder_sent_at DATETIME,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (candidate_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_interviews_recruiter (recruiter_id),
+    INDEX idx_interviews_candidate (candidate_id),
+    INDEX idx_interviews_reminders (status, starts_at)
+);
+
+-- The times a recruiter offered for a proposed interview
+CREATE TABLE interview_slots (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    interview_id INTEGER NOT NULL,
+    starts_at DATETIME NOT NULL,
+    FOREIGN KEY (interview_id) REFERENCES interviews(id) ON DELETE CASCADE
+);
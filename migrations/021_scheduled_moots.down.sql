@@ -0,0 +1,16 @@
+USE lawbookauth;
+
+ALTER TABLE session_participants
+    DROP COLUMN checked_in_at,
+    DROP COLUMN rsvp;
+
+DELETE FROM moot_sessions WHERE status = 'cancelled';
+
+ALTER TABLE moot_sessions
+    DROP INDEX idx_moot_sessions_scheduled,
+    DROP COLUMN reminder_sent_at,
+    DROP COLUMN sequence,
+    DROP COLUMN no_show_policy,
+    DROP COLUMN time_zone,
+    DROP COLUMN scheduled_at,
+    MODIFY status ENUM('setup', 'in_progress', 'completed') NOT NULL DEFAULT 'setup';
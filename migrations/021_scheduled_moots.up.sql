@@ -0,0 +1,19 @@
+USE lawbookauth;
+
+-- Dual and trio moots can be scheduled for a time when everyone is online.
+-- scheduled_at is in UTC; time_zone is the IANA zone it was chosen in.
+-- no_show_policy says whether a moot is cancelled or absent participants are
+-- replaced by the AI when someone hasn't checked in by the start.
+ALTER TABLE moot_sessions
+    MODIFY status ENUM('setup', 'in_progress', 'completed', 'cancelled') NOT NULL DEFAULT 'setup',
+    ADD scheduled_at DATETIME,
+    ADD time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    ADD no_show_policy ENUM('cancel', 'ai') NOT NULL DEFAULT 'ai',
+    ADD sequence INTEGER NOT NULL DEFAULT 0,
+    ADD reminder_sent_at DATETIME,
+    ADD INDEX idx_moot_sessions_scheduled (status, scheduled_at);
+
+-- Whether each invited participant is coming, and when they checked in
+ALTER TABLE session_participants
+    ADD rsvp ENUM('pending', 'yes', 'no') NOT NULL DEFAULT 'pending',
+    ADD checked_in_at DATETIME;
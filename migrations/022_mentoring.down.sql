@@ -0,0 +1,7 @@
+USE lawbookauth;
+
+DROP TABLE IF EXISTS mentoring_reviews;
+DROP TABLE IF EXISTS mentor_slots;
+DROP TABLE IF EXISTS mentoring_requests;
+DROP TABLE IF EXISTS mentor_expertise;
+DROP TABLE IF EXISTS mentor_profiles;
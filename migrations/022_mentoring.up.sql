@@ -0,0 +1,75 @@
+USE lawbookauth;
+
+-- Lawyers who offer to mentor students. Unlisted mentors keep their profile
+-- and reviews but can't be found or booked.
+CREATE TABLE mentor_profiles (
+    user_id INTEGER NOT NULL PRIMARY KEY,
+    headline VARCHAR(160) NOT NULL DEFAULT '',
+    is_listed BOOLEAN NOT NULL DEFAULT TRUE,
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- The case types a mentor judges moots in
+CREATE TABLE mentor_expertise (
+    user_id INTEGER NOT NULL,
+    case_type VARCHAR(50) NOT NULL,
+    PRIMARY KEY (user_id, case_type),
+    FOREIGN KEY (user_id) REFERENCES mentor_profiles(user_id) ON DELETE CASCADE,
+    INDEX idx_mentor_expertise_case_type (case_type)
+);
+
+-- Students' requests for a mentor to judge their moot. The time is copied
+-- from the slot requested. Once accepted, the moot is scheduled with the
+-- mentor as judge.
+CREATE TABLE mentoring_requests (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    mentor_id INTEGER NOT NULL,
+    student_id INTEGER NOT NULL,
+    starts_at DATETIME NOT NULL,
+    time_zone VARCHAR(64) NOT NULL,
+    case_type VARCHAR(50) NOT NULL,
+    difficulty ENUM('easy', 'medium', 'hard') NOT NULL,
+    role ENUM('appellant_counsel', 'respondent_counsel') NOT NULL,
+    message VARCHAR(1000) NOT NULL DEFAULT '',
+    status ENUM('pending', 'accepted', 'declined', 'cancelled') NOT NULL DEFAULT 'pending',
+    moot_session_id INTEGER,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (mentor_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (student_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (moot_session_id) REFERENCES moot_sessions(id) ON DELETE SET NULL,
+    INDEX idx_mentoring_requests_mentor (mentor_id, created_at),
+    INDEX idx_mentoring_requests_student (student_id, created_at)
+);
+
+-- The times a mentor is available. A slot is held by the request made for
+-- it until that request is declined or cancelled.
+CREATE TABLE mentor_slots (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    mentor_id INTEGER NOT NULL,
+    starts_at DATETIME NOT NULL,
+    request_id INTEGER,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (mentor_id) REFERENCES mentor_profiles(user_id) ON DELETE CASCADE,
+    FOREIGN KEY (request_id) REFERENCES mentoring_requests(id) ON DELETE SET NULL,
+    UNIQUE KEY unique_mentor_slot (mentor_id, starts_at)
+);
+
+-- What the student and the mentor thought of a mentored moot. Reviews of
+-- mentors are shown on their mentor profile.
+CREATE TABLE mentoring_reviews (
+    request_id INTEGER NOT NULL,
+    reviewer_id INTEGER NOT NULL,
+    reviewee_id INTEGER NOT NULL,
+    rating TINYINT NOT NULL,
+    comment VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (request_id, reviewer_id),
+    FOREIGN KEY (request_id) REFERENCES mentoring_requests(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewer_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (reviewee_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_mentoring_reviews_reviewee (reviewee_id, created_at)
+);
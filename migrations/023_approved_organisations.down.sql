@@ -0,0 +1,16 @@
+USE lawbookauth;
+
+CREATE TABLE candidate_approved_companies (
+    user_id INTEGER NOT NULL,
+    company_name VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, company_name),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT IGNORE INTO candidate_approved_companies (user_id, company_name, created_at)
+SELECT ao.user_id, o.name, ao.created_at
+FROM candidate_approved_organisations ao
+JOIN organisations o ON o.id = ao.organisation_id;
+
+DROP TABLE IF EXISTS candidate_approved_organisations;
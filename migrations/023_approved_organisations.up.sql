@@ -0,0 +1,22 @@
+USE lawbookauth;
+
+-- Candidates who are only visible to approved companies now approve
+-- organisations with a verified email domain, rather than company names that
+-- any recruiter could type into their profile
+CREATE TABLE candidate_approved_organisations (
+    user_id INTEGER NOT NULL,
+    organisation_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, organisation_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id) ON DELETE CASCADE
+);
+
+-- Keep approvals of names that match a verified organisation. The rest are
+-- dropped, leaving those candidates hidden until they approve again.
+INSERT IGNORE INTO candidate_approved_organisations (user_id, organisation_id, created_at)
+SELECT ac.user_id, o.id, ac.created_at
+FROM candidate_approved_companies ac
+JOIN organisations o ON o.name = ac.company_name AND o.domain_verified = TRUE;
+
+DROP TABLE candidate_approved_companies;
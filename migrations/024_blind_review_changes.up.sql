@@ -0,0 +1,12 @@
+USE lawbookauth;
+
+-- Each time a recruiter switched blind review on or off. Switching it off
+-- reveals every candidate at once, so it is logged like individual reveals.
+CREATE TABLE blind_review_changes (
+    id INTEGER NOT NULL PRIMARY KEY AUTO_INCREMENT,
+    recruiter_id INTEGER NOT NULL,
+    blind_review BOOLEAN NOT NULL,
+    changed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_blind_review_changes_recruiter (recruiter_id, changed_at)
+);
@@ -0,0 +1,3 @@
+USE lawbookauth;
+
+ALTER TABLE users DROP COLUMN calendar_token;
@@ -0,0 +1,5 @@
+USE lawbookauth;
+
+-- The secret in each user's calendar feed address. It is random rather than
+-- derived from the user ID so a leaked address can be replaced.
+ALTER TABLE users ADD calendar_token VARCHAR(64) NULL;
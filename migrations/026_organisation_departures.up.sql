@@ -0,0 +1,12 @@
+USE lawbookauth;
+
+-- Recruiters who left or were removed from an organisation. They don't join
+-- it again automatically through its email domain, only by invite.
+CREATE TABLE organisation_departures (
+    user_id INTEGER NOT NULL,
+    organisation_id INTEGER NOT NULL,
+    departed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, organisation_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id) ON DELETE CASCADE
+);